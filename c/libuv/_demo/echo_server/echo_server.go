@@ -1,6 +1,8 @@
 package main
 
 import (
+	"io"
+
 	"github.com/goplus/lib/c"
 	"github.com/goplus/lib/c/libuv"
 	"github.com/goplus/lib/c/net"
@@ -9,11 +11,6 @@ import (
 var DEFAULT_PORT c.Int = 8080
 var DEFAULT_BACKLOG c.Int = 128
 
-type WriteReq struct {
-	Req libuv.Write
-	Buf libuv.Buf
-}
-
 func main() {
 	// Initialize the default event loop
 	var loop = libuv.DefaultLoop()
@@ -29,9 +26,15 @@ func main() {
 
 	// Bind the server to the specified address and port
 	server.Bind((*net.SockAddr)(c.Pointer(&addr)), 0)
-	res := (*libuv.Stream)(server).Listen(DEFAULT_BACKLOG, OnNewConnection)
-	if res != 0 {
-		c.Fprintf(c.Stderr, c.Str("Listen error: %s\n"), libuv.Strerror(libuv.Errno(res)))
+	err := (*libuv.Stream)(c.Pointer(server)).ListenFunc(DEFAULT_BACKLOG, func(err error) {
+		if err != nil {
+			c.Fprintf(c.Stderr, c.Str("New connection error: %s\n"), c.AllocaCStr(err.Error()))
+			return
+		}
+		onNewConnection(loop, server)
+	})
+	if err != nil {
+		c.Fprintf(c.Stderr, c.Str("Listen error: %s\n"), c.AllocaCStr(err.Error()))
 		return
 	}
 
@@ -39,74 +42,33 @@ func main() {
 	loop.Run(libuv.RUN_DEFAULT)
 }
 
-func FreeWriteReq(req *libuv.Write) {
-	wr := (*WriteReq)(c.Pointer(req))
-	// Free the buffer base.
-	if wr.Buf.Base != nil {
-		c.Free(c.Pointer(wr.Buf.Base))
-		wr.Buf.Base = nil
-	}
-}
-
-func AllocBuffer(handle *libuv.Handle, suggestedSize uintptr, buf *libuv.Buf) {
-	// Allocate memory for the buffer based on the suggested size.
-	buf.Base = (*c.Char)(c.Malloc(suggestedSize))
-	buf.Len = suggestedSize
-}
-
-func EchoWrite(req *libuv.Write, status c.Int) {
-	if status != 0 {
-		c.Fprintf(c.Stderr, c.Str("Write error: %s\n"), libuv.Strerror(libuv.Errno(status)))
-	}
-	FreeWriteReq(req)
-}
-
-func EchoRead(client *libuv.Stream, nread c.Long, buf *libuv.Buf) {
-	if nread > 0 {
-		req := new(WriteReq)
-		// Initialize the buffer with the data read.
-		req.Buf = libuv.InitBuf(buf.Base, c.Uint(nread))
-		// Write the data back to the client.
-		req.Req.Write(client, &req.Buf, 1, EchoWrite)
-		return
-	}
-	if nread < 0 {
-		// Handle read errors and EOF.
-		if (libuv.Errno)(nread) != libuv.EOF {
-			c.Fprintf(c.Stderr, c.Str("Read error: %s\n"), libuv.Strerror(libuv.Errno(nread)))
-		}
-		(*libuv.Handle)(c.Pointer(client)).Close(nil)
-	}
-	// Free the buffer if it's no longer needed.
-	if buf.Base != nil {
-		c.Free(c.Pointer(buf.Base))
-	}
-}
-
-func OnNewConnection(server *libuv.Stream, status c.Int) {
-	if status < 0 {
-		c.Fprintf(c.Stderr, c.Str("New connection error: %s\n"), libuv.Strerror(libuv.Errno(status)))
-		return
-	}
-
-	// Allocate memory for a new client.
+func onNewConnection(loop *libuv.Loop, server *libuv.Tcp) {
+	// Initialize the client TCP handle.
 	client := &libuv.Tcp{}
+	libuv.InitTcp(loop, client)
+	stream := (*libuv.Stream)(c.Pointer(client))
+	handle := (*libuv.Handle)(c.Pointer(client))
 
-	if client == nil {
-		c.Fprintf(c.Stderr, c.Str("Failed to allocate memory for client\n"))
+	if (*libuv.Stream)(c.Pointer(server)).Accept(stream) != 0 {
+		handle.CloseFunc(nil)
 		return
 	}
 
-	// Initialize the client TCP handle.
-	if libuv.InitTcp(libuv.DefaultLoop(), client) < 0 {
-		c.Fprintf(c.Stderr, c.Str("Failed to initialize client\n"))
-		return
-	}
-
-	// Accept the new connection and start reading data.
-	if server.Accept((*libuv.Stream)(client)) == 0 {
-		(*libuv.Stream)(client).StartRead(AllocBuffer, EchoRead)
-	} else {
-		(*libuv.Handle)(c.Pointer(client)).Close(nil)
-	}
+	// Read buffers come from the default allocator and are reused after
+	// each callback; WriteFunc copies the data it is given, so it can be
+	// echoed straight back.
+	stream.StartReadFunc(func(data []byte, err error) {
+		if err != nil {
+			if err != io.EOF {
+				c.Fprintf(c.Stderr, c.Str("Read error: %s\n"), c.AllocaCStr(err.Error()))
+			}
+			handle.CloseFunc(nil)
+			return
+		}
+		stream.WriteFunc(data, func(err error) {
+			if err != nil {
+				c.Fprintf(c.Stderr, c.Str("Write error: %s\n"), c.AllocaCStr(err.Error()))
+			}
+		})
+	})
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/goplus/lib/c/libuv"
+)
+
+func main() {
+	ln, err := libuv.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Println("listen:", err)
+		return
+	}
+	defer ln.Close()
+	fmt.Println("listening on", ln.Addr())
+
+	// Echo every connection back to its sender.
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}(conn)
+		}
+	}()
+
+	conn, err := libuv.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		fmt.Println("dial:", err)
+		return
+	}
+	defer conn.Close()
+	conn.(*libuv.TcpConn).SetNoDelay(true)
+
+	// Nothing has been sent yet, so this read times out.
+	conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, err = conn.Read(make([]byte, 1))
+	fmt.Println("read before write:", err)
+	conn.SetReadDeadline(time.Time{})
+
+	fmt.Fprintf(conn, "hello from %v\n", conn.LocalAddr())
+	conn.(*libuv.TcpConn).CloseWrite()
+
+	reply, err := io.ReadAll(conn)
+	fmt.Printf("echoed: %q, err: %v\n", reply, err)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/c/libuv"
+)
+
+var PIPE_NAME = c.Str("/tmp/libuv-echo.sock")
+var DEFAULT_BACKLOG c.Int = 128
+
+type WriteReq struct {
+	Req libuv.Write
+	Buf libuv.Buf
+}
+
+func main() {
+	loop := libuv.DefaultLoop()
+
+	// Initialize a pipe server (not in IPC mode).
+	server := &libuv.Pipe{}
+	libuv.InitPipe(loop, server, 0)
+
+	// Remove a stale socket left behind by a previous run.
+	var req libuv.Fs
+	libuv.FsUnlink(loop, &req, PIPE_NAME, nil)
+	req.ReqCleanup()
+
+	if res := server.Bind(PIPE_NAME); res != 0 {
+		c.Fprintf(c.Stderr, c.Str("Bind error: %s\n"), libuv.Strerror(libuv.Errno(res)))
+		return
+	}
+	res := (*libuv.Stream)(c.Pointer(server)).Listen(DEFAULT_BACKLOG, OnNewConnection)
+	if res != 0 {
+		c.Fprintf(c.Stderr, c.Str("Listen error: %s\n"), libuv.Strerror(libuv.Errno(res)))
+		return
+	}
+	c.Printf(c.Str("Listening on %s\n"), PIPE_NAME)
+
+	loop.Run(libuv.RUN_DEFAULT)
+}
+
+func AllocBuffer(handle *libuv.Handle, suggestedSize uintptr, buf *libuv.Buf) {
+	buf.Base = (*c.Char)(c.Malloc(suggestedSize))
+	buf.Len = suggestedSize
+}
+
+func EchoWrite(req *libuv.Write, status c.Int) {
+	if status != 0 {
+		c.Fprintf(c.Stderr, c.Str("Write error: %s\n"), libuv.Strerror(libuv.Errno(status)))
+	}
+	wr := (*WriteReq)(c.Pointer(req))
+	c.Free(c.Pointer(wr.Buf.Base))
+}
+
+func EchoRead(client *libuv.Stream, nread c.Long, buf *libuv.Buf) {
+	if nread > 0 {
+		req := new(WriteReq)
+		req.Buf = libuv.InitBuf(buf.Base, c.Uint(nread))
+		req.Req.Write(client, &req.Buf, 1, EchoWrite)
+		return
+	}
+	if nread < 0 {
+		if libuv.Errno(nread) != libuv.EOF {
+			c.Fprintf(c.Stderr, c.Str("Read error: %s\n"), libuv.Strerror(libuv.Errno(nread)))
+		}
+		(*libuv.Handle)(c.Pointer(client)).Close(nil)
+	}
+	if buf.Base != nil {
+		c.Free(c.Pointer(buf.Base))
+	}
+}
+
+func OnNewConnection(server *libuv.Stream, status c.Int) {
+	if status < 0 {
+		c.Fprintf(c.Stderr, c.Str("New connection error: %s\n"), libuv.Strerror(libuv.Errno(status)))
+		return
+	}
+
+	client := &libuv.Pipe{}
+	libuv.InitPipe(libuv.DefaultLoop(), client, 0)
+
+	if server.Accept((*libuv.Stream)(c.Pointer(client))) == 0 {
+		(*libuv.Stream)(c.Pointer(client)).StartRead(AllocBuffer, EchoRead)
+	} else {
+		(*libuv.Handle)(c.Pointer(client)).Close(nil)
+	}
+}
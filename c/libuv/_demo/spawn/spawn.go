@@ -0,0 +1,70 @@
+package main
+
+import (
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/c/libuv"
+)
+
+var (
+	loop    *libuv.Loop
+	process libuv.Process
+	stdout  libuv.Pipe
+)
+
+func main() {
+	loop = libuv.DefaultLoop()
+
+	// Create the pipe the child writes its stdout to.
+	libuv.InitPipe(loop, &stdout, 0)
+
+	args := [...]*c.Char{c.Str("ls"), c.Str("-l"), nil}
+
+	// stdin is ignored, stdout is piped back to us, stderr is inherited.
+	var stdio [3]libuv.StdioContainer
+	stdio[0].Flags = libuv.IGNORE
+	stdio[1].SetStream(libuv.CREATE_PIPE|libuv.WRITABLE_PIPE, (*libuv.Stream)(c.Pointer(&stdout)))
+	stdio[2].SetFd(2)
+
+	options := libuv.ProcessOptions{
+		ExitCb:     onExit,
+		File:       args[0],
+		Args:       &args[0],
+		StdioCount: c.Int(len(stdio)),
+		Stdio:      &stdio[0],
+	}
+
+	if res := libuv.Spawn(loop, &process, &options); res != 0 {
+		c.Fprintf(c.Stderr, c.Str("Spawn error: %s\n"), libuv.Strerror(libuv.Errno(res)))
+		return
+	}
+	c.Printf(c.Str("Launched process with ID %d\n"), process.GetPid())
+
+	// Stream the child's stdout as it arrives.
+	(*libuv.Stream)(c.Pointer(&stdout)).StartRead(allocBuffer, onRead)
+
+	loop.Run(libuv.RUN_DEFAULT)
+}
+
+func allocBuffer(handle *libuv.Handle, suggestedSize uintptr, buf *libuv.Buf) {
+	buf.Base = (*c.Char)(c.Malloc(suggestedSize))
+	buf.Len = suggestedSize
+}
+
+func onRead(stream *libuv.Stream, nread c.Long, buf *libuv.Buf) {
+	if nread > 0 {
+		c.Fwrite(c.Pointer(buf.Base), 1, uintptr(nread), c.Stdout)
+	} else if nread < 0 {
+		if libuv.Errno(nread) != libuv.EOF {
+			c.Fprintf(c.Stderr, c.Str("Read error: %s\n"), libuv.Strerror(libuv.Errno(nread)))
+		}
+		(*libuv.Handle)(c.Pointer(stream)).Close(nil)
+	}
+	if buf.Base != nil {
+		c.Free(c.Pointer(buf.Base))
+	}
+}
+
+func onExit(process *libuv.Process, exitStatus int64, termSignal c.Int) {
+	c.Printf(c.Str("Process exited with status %lld, signal %d\n"), exitStatus, termSignal)
+	(*libuv.Handle)(c.Pointer(process)).Close(nil)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/c/libuv"
+)
+
+func main() {
+	loop := libuv.DefaultLoop()
+	loop.EnableMetricsIdleTime()
+
+	var uts libuv.Utsname
+	if libuv.OsUname(&uts) == 0 {
+		c.Printf(c.Str("%s %s %s\n"), &uts.Sysname[0], &uts.Release[0], &uts.Machine[0])
+	}
+
+	var uptime c.Double
+	libuv.Uptime(&uptime)
+	var avg [3]c.Double
+	libuv.Loadavg(&avg)
+	c.Printf(c.Str("uptime: %.0fs, load: %.2f %.2f %.2f\n"), uptime, avg[0], avg[1], avg[2])
+
+	var rss uintptr
+	libuv.ResidentSetMemory(&rss)
+	c.Printf(c.Str("memory: rss %zu, free %llu of %llu\n"), rss, libuv.GetFreeMemory(), libuv.GetTotalMemory())
+
+	var cpus *libuv.CpuInfo
+	var ncpu c.Int
+	if libuv.GetCpuInfo(&cpus, &ncpu) == 0 {
+		for _, cpu := range unsafe.Slice(cpus, ncpu) {
+			c.Printf(c.Str("cpu: %s @ %d MHz\n"), cpu.Model, cpu.Speed)
+		}
+		libuv.FreeCpuInfo(cpus, ncpu)
+	}
+
+	var addrs *libuv.InterfaceAddress
+	var naddr c.Int
+	if libuv.InterfaceAddresses(&addrs, &naddr) == 0 {
+		var name [64]c.Char
+		for _, addr := range unsafe.Slice(addrs, naddr) {
+			libuv.IpName(addr.Addr(), &name[0], uintptr(len(name)))
+			c.Printf(c.Str("interface: %s %s internal=%d\n"), addr.Name, &name[0], addr.IsInternal)
+		}
+		libuv.FreeInterfaceAddresses(addrs, naddr)
+	}
+
+	// Run a short timer and report what the loop did.
+	var timer libuv.Timer
+	libuv.InitTimer(loop, &timer)
+	start := libuv.Hrtime()
+	timer.StartFunc(50, 0, func() {
+		stats := loop.Stats()
+		c.Printf(c.Str("handles: %d total, %d timers (%d active)\n"), stats.Total(), stats.Handles[libuv.TIMER], stats.Active[libuv.TIMER])
+		loop.PrintAllHandles(c.Stdout)
+		(*libuv.Handle)(unsafe.Pointer(&timer)).CloseFunc(nil)
+	})
+	loop.Run(libuv.RUN_DEFAULT)
+
+	stats := loop.Stats()
+	c.Printf(c.Str("elapsed: %lluns, idle: %lluns, iterations: %llu, events: %llu\n"),
+		libuv.Hrtime()-start, stats.IdleTime, stats.LoopCount, stats.Events)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goplus/lib/c/libuv"
+)
+
+func fib(n int) int {
+	if n < 2 {
+		return n
+	}
+	return fib(n-1) + fib(n-2)
+}
+
+func main() {
+	// Must happen before any work is queued.
+	libuv.SetThreadpoolSize(2)
+
+	loop := libuv.DefaultLoop()
+
+	for _, n := range []int{30, 32, 34} {
+		n := n
+		libuv.Go(loop, func() (int, error) {
+			return fib(n), nil
+		}).Then(func(v int, err error) {
+			fmt.Printf("fib(%d) = %d\n", n, v)
+		})
+	}
+
+	// Both pool threads are busy, so this task is still queued and can be
+	// cancelled.
+	slow := libuv.Go(loop, func() (string, error) {
+		time.Sleep(time.Second)
+		return "finished", nil
+	})
+	slow.Then(func(v string, err error) {
+		fmt.Println("slow task:", v, err)
+	})
+	if err := slow.Cancel(); err != nil {
+		fmt.Println("cancel:", err)
+	}
+
+	loop.Run(libuv.RUN_DEFAULT)
+}
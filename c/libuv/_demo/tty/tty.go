@@ -0,0 +1,76 @@
+package main
+
+import (
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/c/libuv"
+	"github.com/goplus/lib/c/syscall"
+)
+
+var (
+	loop   *libuv.Loop
+	tty    libuv.Tty
+	winch  libuv.Signal
+	buffer [64]c.Char
+)
+
+func main() {
+	loop = libuv.DefaultLoop()
+
+	if libuv.GuessHandle(0) != libuv.TTY {
+		c.Fprintf(c.Stderr, c.Str("stdin is not a terminal\n"))
+		return
+	}
+
+	libuv.InitTty(loop, &tty, 0, 0)
+	if res := tty.SetMode(libuv.TTY_MODE_RAW); res != 0 {
+		c.Fprintf(c.Stderr, c.Str("SetMode error: %s\n"), libuv.Strerror(libuv.Errno(res)))
+		return
+	}
+	defer libuv.TtyResetMode()
+
+	printWinsize()
+	libuv.SignalInit(loop, &winch)
+	winch.Start(onResize, c.Int(syscall.SIGWINCH))
+
+	c.Printf(c.Str("Press keys, q to quit\r\n"))
+	tty.StartRead(allocBuffer, onRead)
+
+	loop.Run(libuv.RUN_DEFAULT)
+}
+
+func printWinsize() {
+	var width, height c.Int
+	if tty.GetWinsize(&width, &height) == 0 {
+		c.Printf(c.Str("Window size: %dx%d\r\n"), width, height)
+	}
+}
+
+func onResize(handle *libuv.Signal, sigNum c.Int) {
+	printWinsize()
+}
+
+func allocBuffer(handle *libuv.Handle, suggestedSize uintptr, buf *libuv.Buf) {
+	*buf = libuv.InitBuf(&buffer[0], c.Uint(len(buffer)))
+}
+
+func onRead(stream *libuv.Stream, nread c.Long, buf *libuv.Buf) {
+	if nread < 0 {
+		stop()
+		return
+	}
+	for i := c.Long(0); i < nread; i++ {
+		ch := buffer[i]
+		c.Printf(c.Str("key: 0x%02x\r\n"), c.Int(ch))
+		if ch == 'q' || ch == 3 {
+			stop()
+			return
+		}
+	}
+}
+
+func stop() {
+	tty.StopRead()
+	winch.Stop()
+	(*libuv.Handle)(c.Pointer(&tty)).Close(nil)
+	(*libuv.Handle)(c.Pointer(&winch)).Close(nil)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/goplus/lib/c/libuv"
+)
+
+func main() {
+	// The collector reads datagrams in batches where recvmmsg is available.
+	server, err := libuv.ListenUdp("udp", netip.MustParseAddrPort("127.0.0.1:0"), &libuv.UdpConfig{
+		Recvmmsg: true,
+		QueueLen: 4096,
+	})
+	if err != nil {
+		fmt.Println("listen:", err)
+		return
+	}
+	defer server.Close()
+	fmt.Println("listening on", server.LocalAddr(), "recvmmsg:", server.UsingRecvmmsg())
+
+	client, err := libuv.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		fmt.Println("listen:", err)
+		return
+	}
+	defer client.Close()
+
+	const count = 1000
+	sender := client.(*libuv.UdpConn)
+	for i := 0; i < count; i++ {
+		sender.WriteToUDPAddrPort([]byte(fmt.Sprintf("metric %d", i)), server.LocalAddrPort())
+	}
+
+	buf := make([]byte, 1500)
+	received := 0
+	server.SetReadDeadline(time.Now().Add(time.Second))
+	for received < count {
+		n, from, err := server.ReadFromUDPAddrPort(buf)
+		if err != nil {
+			fmt.Println("read:", err)
+			break
+		}
+		if received == 0 {
+			fmt.Printf("first datagram from %v: %q\n", from, buf[:n])
+		}
+		received++
+	}
+	fmt.Printf("received %d of %d datagrams, dropped %d\n", received, count, server.Dropped())
+}
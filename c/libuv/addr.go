@@ -0,0 +1,52 @@
+package libuv
+
+import (
+	"net/netip"
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/c/net"
+)
+
+// sockaddr is large enough to hold an address of any family libuv supports,
+// like struct sockaddr_storage.
+type sockaddr struct {
+	_   [0]uint64
+	buf [128]byte
+}
+
+func (sa *sockaddr) ptr() *net.SockAddr {
+	return (*net.SockAddr)(unsafe.Pointer(&sa.buf[0]))
+}
+
+// set stores ap in sa in the platform's native layout.
+func (sa *sockaddr) set(ap netip.AddrPort) error {
+	ip := ap.Addr()
+	var ret c.Int
+	if ip.Is4() {
+		ret = Ip4Addr(c.AllocaCStr(ip.String()), c.Int(ap.Port()), (*net.SockaddrIn)(unsafe.Pointer(&sa.buf[0])))
+	} else {
+		ret = Ip6Addr(c.AllocaCStr(ip.String()), c.Int(ap.Port()), (*net.SockaddrIn6)(unsafe.Pointer(&sa.buf[0])))
+	}
+	return toError(ret)
+}
+
+// addrPortOf converts an IPv4 or IPv6 socket address returned by libuv. It
+// reports false for other address families.
+func addrPortOf(sa *net.SockAddr) (netip.AddrPort, bool) {
+	if sa == nil {
+		return netip.AddrPort{}, false
+	}
+	var name [64]c.Char
+	if IpName(sa, &name[0], uintptr(len(name))) != 0 {
+		return netip.AddrPort{}, false
+	}
+	ip, err := netip.ParseAddr(c.GoString(&name[0]))
+	if err != nil {
+		return netip.AddrPort{}, false
+	}
+	// sin_port and sin6_port are at the same offset, in network byte order,
+	// in every layout.
+	b := (*[4]byte)(unsafe.Pointer(sa))
+	return netip.AddrPortFrom(ip, uint16(b[2])<<8|uint16(b[3])), true
+}
@@ -0,0 +1,514 @@
+package libuv
+
+import (
+	"io"
+	"net/netip"
+	"sync"
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/c/net"
+)
+
+// ----------------------------------------------
+
+/* Buffer allocator */
+
+// Allocator hands out fixed-size C buffers for reads and writes. Buffers go
+// back to a free list once libuv is done with them, so a busy loop does not
+// call malloc for every read or write.
+type Allocator struct {
+	size uintptr
+	max  int
+
+	mu   sync.Mutex
+	free []*c.Char
+}
+
+// DefaultAllocator is used by handles that were not given an allocator with
+// SetAllocator.
+var DefaultAllocator = NewAllocator(64<<10, 128)
+
+const (
+	// MaxDatagramSize is the buffer size libuv needs per datagram.
+	MaxDatagramSize = 64 << 10
+	// MmsgBatch is the most datagrams libuv reads with one recvmmsg call.
+	MmsgBatch = 20
+)
+
+// NewMmsgAllocator returns an allocator whose buffers hold batch
+// datagrams, for Udp handles initialized with UDP_RECVMMSG. batch is
+// clamped to [2, MmsgBatch].
+func NewMmsgAllocator(batch int, max int) *Allocator {
+	if batch < 2 {
+		batch = 2
+	} else if batch > MmsgBatch {
+		batch = MmsgBatch
+	}
+	return NewAllocator(uintptr(batch)*MaxDatagramSize, max)
+}
+
+// NewAllocator returns an allocator of size-byte buffers that keeps at most
+// max idle buffers around.
+func NewAllocator(size uintptr, max int) *Allocator {
+	return &Allocator{size: size, max: max}
+}
+
+// Size returns the size of the buffers handed out by a.
+func (a *Allocator) Size() uintptr {
+	return a.size
+}
+
+// Get returns a buffer of a.Size() bytes.
+func (a *Allocator) Get() Buf {
+	a.mu.Lock()
+	if n := len(a.free); n > 0 {
+		base := a.free[n-1]
+		a.free = a.free[:n-1]
+		a.mu.Unlock()
+		return Buf{Base: base, Len: a.size}
+	}
+	a.mu.Unlock()
+	return Buf{Base: (*c.Char)(c.Malloc(a.size)), Len: a.size}
+}
+
+// Put returns a buffer obtained from Get. Buffers beyond the idle limit are
+// freed.
+func (a *Allocator) Put(buf Buf) {
+	if buf.Base == nil {
+		return
+	}
+	a.mu.Lock()
+	if len(a.free) < a.max {
+		a.free = append(a.free, buf.Base)
+		a.mu.Unlock()
+		return
+	}
+	a.mu.Unlock()
+	c.Free(c.Pointer(buf.Base))
+}
+
+// copyBufs copies data into buffers from a and appends them to bufs. Empty
+// data still produces one, empty, buffer.
+func (a *Allocator) copyBufs(bufs []Buf, data []byte) []Buf {
+	for first := true; first || len(data) > 0; first = false {
+		buf := a.Get()
+		n := copy(unsafe.Slice((*byte)(unsafe.Pointer(buf.Base)), buf.Len), data)
+		buf.Len = uintptr(n)
+		bufs = append(bufs, buf)
+		data = data[n:]
+	}
+	return bufs
+}
+
+// putBufs returns buffers filled by copyBufs and empties bufs for reuse.
+func (a *Allocator) putBufs(bufs []Buf) []Buf {
+	for i := range bufs {
+		bufs[i].Len = a.size
+		a.Put(bufs[i])
+		bufs[i] = Buf{}
+	}
+	return bufs[:0]
+}
+
+// ----------------------------------------------
+
+/* Closure state */
+
+// handleFuncs holds the Go callbacks of a handle. It is kept in liveHandles,
+// keyed by the handle, until the handle is closed with CloseFunc, which
+// leaves the handle's Data field to its user.
+type handleFuncs struct {
+	handle *Handle
+	alloc  *Allocator
+
+	onRead       func(data []byte, err error)
+	onRecv       func(data []byte, from netip.AddrPort, flags UdpFlags, err error)
+	onConnection func(err error)
+	onTimer      func()
+	onSignal     func(sigNum c.Int)
+	onExit       func(exitStatus int64, termSignal c.Int)
+	onClose      func()
+}
+
+var (
+	liveMu      sync.Mutex
+	liveHandles = make(map[*Handle]*handleFuncs)
+	liveReqs    = make(map[c.Pointer]any)
+)
+
+func funcsOf(handle *Handle) *handleFuncs {
+	liveMu.Lock()
+	defer liveMu.Unlock()
+	f := liveHandles[handle]
+	if f == nil {
+		f = &handleFuncs{handle: handle, alloc: DefaultAllocator}
+		liveHandles[handle] = f
+	}
+	return f
+}
+
+func lookupFuncs(handle *Handle) *handleFuncs {
+	liveMu.Lock()
+	f := liveHandles[handle]
+	liveMu.Unlock()
+	return f
+}
+
+func keepReq(req c.Pointer, v any) {
+	liveMu.Lock()
+	liveReqs[req] = v
+	liveMu.Unlock()
+}
+
+func releaseReq(req c.Pointer) {
+	liveMu.Lock()
+	delete(liveReqs, req)
+	liveMu.Unlock()
+}
+
+// SetAllocator makes the closure methods of handle take their read buffers
+// from alloc instead of DefaultAllocator.
+func (handle *Handle) SetAllocator(alloc *Allocator) {
+	funcsOf(handle).alloc = alloc
+}
+
+// CloseFunc closes handle and calls fn, which may be nil, once it is closed.
+// Handles that use any of the closure methods must be closed with CloseFunc
+// so that their callbacks are released.
+func (handle *Handle) CloseFunc(fn func()) {
+	funcsOf(handle).onClose = fn
+	handle.Close(onCloseFunc)
+}
+
+func onCloseFunc(handle *Handle) {
+	f := lookupFuncs(handle)
+	liveMu.Lock()
+	delete(liveHandles, handle)
+	liveMu.Unlock()
+	if f != nil && f.onClose != nil {
+		f.onClose()
+	}
+}
+
+// ----------------------------------------------
+
+/* Stream closures */
+
+// StartReadFunc starts reading from stream. fn receives each chunk of data;
+// the slice is only valid during the call, as its buffer is reused
+// afterwards. At end of stream fn is called with io.EOF, and with an Errno
+// on failure.
+func (stream *Stream) StartReadFunc(fn func(data []byte, err error)) error {
+	funcsOf((*Handle)(c.Pointer(stream))).onRead = fn
+	return toError(stream.StartRead(onAllocFunc, onReadFunc))
+}
+
+func onAllocFunc(handle *Handle, suggestedSize uintptr, buf *Buf) {
+	*buf = lookupFuncs(handle).alloc.Get()
+}
+
+func onReadFunc(stream *Stream, nread c.Long, buf *Buf) {
+	f := lookupFuncs((*Handle)(c.Pointer(stream)))
+	defer f.alloc.Put(*buf)
+	switch {
+	case nread > 0:
+		f.onRead(unsafe.Slice((*byte)(unsafe.Pointer(buf.Base)), nread), nil)
+	case Errno(nread) == EOF:
+		f.onRead(nil, io.EOF)
+	case nread < 0:
+		f.onRead(nil, Errno(nread))
+	}
+}
+
+// ListenFunc starts listening for incoming connections and calls fn for each
+// one, which should then be taken with Accept.
+func (stream *Stream) ListenFunc(backlog c.Int, fn func(err error)) error {
+	funcsOf((*Handle)(c.Pointer(stream))).onConnection = fn
+	return toError(stream.Listen(backlog, onConnectionFunc))
+}
+
+func onConnectionFunc(server *Stream, status c.Int) {
+	lookupFuncs((*Handle)(c.Pointer(server))).onConnection(toError(status))
+}
+
+// writeReq is a pooled uv_write_t together with the buffers it sends.
+type writeReq struct {
+	req   Write
+	alloc *Allocator
+	bufs  []Buf
+	fn    func(err error)
+}
+
+var writeReqPool = sync.Pool{New: func() any { return new(writeReq) }}
+
+// WriteFunc writes data to stream and calls fn, which may be nil, when the
+// write completes. data is copied into buffers from the handle's allocator,
+// which are released after the write, so the caller may reuse data as soon
+// as WriteFunc returns.
+func (stream *Stream) WriteFunc(data []byte, fn func(err error)) error {
+	alloc := funcsOf((*Handle)(c.Pointer(stream))).alloc
+	wr := writeReqPool.Get().(*writeReq)
+	wr.alloc, wr.fn = alloc, fn
+	wr.bufs = alloc.copyBufs(wr.bufs, data)
+	keepReq(c.Pointer(&wr.req), wr)
+	if ret := wr.req.Write(stream, &wr.bufs[0], c.Uint(len(wr.bufs)), onWriteFunc); ret < 0 {
+		wr.release()
+		return Errno(ret)
+	}
+	return nil
+}
+
+func onWriteFunc(req *Write, status c.Int) {
+	wr := (*writeReq)(c.Pointer(req))
+	fn := wr.fn
+	wr.release()
+	if fn != nil {
+		fn(toError(status))
+	}
+}
+
+func (wr *writeReq) release() {
+	releaseReq(c.Pointer(&wr.req))
+	wr.bufs = wr.alloc.putBufs(wr.bufs)
+	wr.alloc, wr.fn = nil, nil
+	writeReqPool.Put(wr)
+}
+
+// shutdownReq is a pooled uv_shutdown_t.
+type shutdownReq struct {
+	req Shutdown
+	fn  func(err error)
+}
+
+var shutdownReqPool = sync.Pool{New: func() any { return new(shutdownReq) }}
+
+// ShutdownFunc shuts down the write side of stream once pending writes
+// complete, and then calls fn, which may be nil.
+func (stream *Stream) ShutdownFunc(fn func(err error)) error {
+	sr := shutdownReqPool.Get().(*shutdownReq)
+	sr.fn = fn
+	keepReq(c.Pointer(&sr.req), sr)
+	if ret := StreamShutdown(&sr.req, stream, onShutdownFunc); ret < 0 {
+		sr.release()
+		return Errno(ret)
+	}
+	return nil
+}
+
+func onShutdownFunc(req *Shutdown, status c.Int) {
+	sr := (*shutdownReq)(c.Pointer(req))
+	fn := sr.fn
+	sr.release()
+	if fn != nil {
+		fn(toError(status))
+	}
+}
+
+func (sr *shutdownReq) release() {
+	releaseReq(c.Pointer(&sr.req))
+	sr.fn = nil
+	shutdownReqPool.Put(sr)
+}
+
+// ----------------------------------------------
+
+/* Connect closures */
+
+// connectReq is a pooled uv_connect_t.
+type connectReq struct {
+	req Connect
+	fn  func(err error)
+}
+
+var connectReqPool = sync.Pool{New: func() any { return new(connectReq) }}
+
+func newConnectReq(fn func(err error)) *connectReq {
+	cr := connectReqPool.Get().(*connectReq)
+	cr.fn = fn
+	keepReq(c.Pointer(&cr.req), cr)
+	return cr
+}
+
+func onConnectFunc(req *Connect, status c.Int) {
+	cr := (*connectReq)(c.Pointer(req))
+	fn := cr.fn
+	cr.release()
+	fn(toError(status))
+}
+
+func (cr *connectReq) release() {
+	releaseReq(c.Pointer(&cr.req))
+	cr.fn = nil
+	connectReqPool.Put(cr)
+}
+
+// ConnectFunc connects tcp to addr and calls fn with the result.
+func (tcp *Tcp) ConnectFunc(addr *net.SockAddr, fn func(err error)) error {
+	cr := newConnectReq(fn)
+	if ret := TcpConnect(&cr.req, tcp, addr, onConnectFunc); ret < 0 {
+		cr.release()
+		return Errno(ret)
+	}
+	return nil
+}
+
+// ConnectFunc connects pipe to the socket or named pipe name and calls fn
+// with the result.
+func (pipe *Pipe) ConnectFunc(name string, fn func(err error)) error {
+	cr := newConnectReq(fn)
+	if ret := PipeConnect2(&cr.req, pipe, c.AllocaCStr(name), uintptr(len(name)), 0, onConnectFunc); ret < 0 {
+		cr.release()
+		return Errno(ret)
+	}
+	return nil
+}
+
+// ----------------------------------------------
+
+/* Udp closures */
+
+// Bind2 binds udp to the local address ap.
+func (udp *Udp) Bind2(ap netip.AddrPort, flags UdpFlags) error {
+	var sa sockaddr
+	if err := sa.set(ap); err != nil {
+		return err
+	}
+	return toError(udp.Bind(sa.ptr(), c.Uint(flags)))
+}
+
+// LocalAddrPort returns the address udp is bound to.
+func (udp *Udp) LocalAddrPort() (netip.AddrPort, error) {
+	var sa sockaddr
+	nameLen := c.Int(len(sa.buf))
+	if err := toError(udp.Getsockname(sa.ptr(), &nameLen)); err != nil {
+		return netip.AddrPort{}, err
+	}
+	ap, _ := addrPortOf(sa.ptr())
+	return ap, nil
+}
+
+// StartRecvFunc starts receiving datagrams on udp and calls fn for each one.
+// data is only valid during the call, as its buffer is reused afterwards.
+// flags may include UDP_PARTIAL when the datagram was truncated, and
+// UDP_MMSG_CHUNK when it was received in a batch; receive errors are
+// reported through err.
+//
+// A handle initialized with UDP_RECVMMSG receives up to MmsgBatch datagrams
+// per system call when its allocator hands out buffers of at least two
+// datagrams; see NewMmsgAllocator. The buffer is shared by the whole batch
+// and goes back to the allocator once libuv reports UDP_MMSG_FREE.
+func (udp *Udp) StartRecvFunc(fn func(data []byte, from netip.AddrPort, flags UdpFlags, err error)) error {
+	funcsOf((*Handle)(c.Pointer(udp))).onRecv = fn
+	return toError(udp.StartRecv(onAllocFunc, onRecvFunc))
+}
+
+func onRecvFunc(udp *Udp, nread c.Long, buf *Buf, addr *net.SockAddr, flags c.Uint) {
+	f := lookupFuncs((*Handle)(c.Pointer(udp)))
+	uflags := UdpFlags(flags)
+	switch {
+	case uflags&UDP_MMSG_FREE != 0:
+		// The batch is done; the buffer was not released by its chunks.
+		f.alloc.Put(*buf)
+		return
+	case uflags&UDP_MMSG_CHUNK == 0:
+		defer f.alloc.Put(*buf)
+	}
+	switch {
+	case nread < 0:
+		f.onRecv(nil, netip.AddrPort{}, uflags, Errno(nread))
+	case addr == nil:
+		// Nothing more to read.
+	default:
+		from, _ := addrPortOf(addr)
+		f.onRecv(unsafe.Slice((*byte)(unsafe.Pointer(buf.Base)), nread), from, uflags, nil)
+	}
+}
+
+// udpSendReq is a pooled uv_udp_send_t together with the buffers it sends.
+type udpSendReq struct {
+	req  UdpSend
+	bufs []Buf
+	fn   func(err error)
+}
+
+var udpSendReqPool = sync.Pool{New: func() any { return new(udpSendReq) }}
+
+// SendFunc sends data to the address to and calls fn, which may be nil,
+// once it has been sent. data is copied into buffers from DefaultAllocator,
+// so the caller may reuse it as soon as SendFunc returns. A zero to sends to
+// the address udp is connected to.
+func (udp *Udp) SendFunc(data []byte, to netip.AddrPort, fn func(err error)) error {
+	var sa sockaddr
+	var addr *net.SockAddr
+	if to.IsValid() {
+		if err := sa.set(to); err != nil {
+			return err
+		}
+		addr = sa.ptr()
+	}
+	sr := udpSendReqPool.Get().(*udpSendReq)
+	sr.fn = fn
+	sr.bufs = DefaultAllocator.copyBufs(sr.bufs, data)
+	keepReq(c.Pointer(&sr.req), sr)
+	if ret := Send(&sr.req, udp, &sr.bufs[0], c.Uint(len(sr.bufs)), addr, onUdpSendFunc); ret < 0 {
+		sr.release()
+		return Errno(ret)
+	}
+	return nil
+}
+
+func onUdpSendFunc(req *UdpSend, status c.Int) {
+	sr := (*udpSendReq)(c.Pointer(req))
+	fn := sr.fn
+	sr.release()
+	if fn != nil {
+		fn(toError(status))
+	}
+}
+
+func (sr *udpSendReq) release() {
+	releaseReq(c.Pointer(&sr.req))
+	sr.bufs = DefaultAllocator.putBufs(sr.bufs)
+	sr.fn = nil
+	udpSendReqPool.Put(sr)
+}
+
+// ----------------------------------------------
+
+/* Timer, Signal and Process closures */
+
+// StartFunc starts timer and calls fn after timeoutMs milliseconds, then
+// every repeat milliseconds if repeat is non-zero.
+func (timer *Timer) StartFunc(timeoutMs uint64, repeat uint64, fn func()) error {
+	funcsOf((*Handle)(c.Pointer(timer))).onTimer = fn
+	return toError(timer.Start(onTimerFunc, timeoutMs, repeat))
+}
+
+func onTimerFunc(timer *Timer) {
+	lookupFuncs((*Handle)(c.Pointer(timer))).onTimer()
+}
+
+// StartFunc calls fn whenever signum is delivered.
+func (handle *Signal) StartFunc(signum c.Int, fn func(sigNum c.Int)) error {
+	funcsOf((*Handle)(c.Pointer(handle))).onSignal = fn
+	return toError(handle.Start(onSignalFunc, signum))
+}
+
+func onSignalFunc(handle *Signal, sigNum c.Int) {
+	lookupFuncs((*Handle)(c.Pointer(handle))).onSignal(sigNum)
+}
+
+// SpawnFunc is like Spawn but calls fn when the process exits. The ExitCb
+// field of options is ignored. As with Spawn, handle must be closed even if
+// spawning fails.
+func SpawnFunc(loop *Loop, handle *Process, options *ProcessOptions, fn func(exitStatus int64, termSignal c.Int)) error {
+	funcsOf((*Handle)(c.Pointer(handle))).onExit = fn
+	opts := *options
+	opts.ExitCb = onExitFunc
+	return toError(Spawn(loop, handle, &opts))
+}
+
+func onExitFunc(process *Process, exitStatus int64, termSignal c.Int) {
+	lookupFuncs((*Handle)(c.Pointer(process))).onExit(exitStatus, termSignal)
+}
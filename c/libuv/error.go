@@ -116,3 +116,17 @@ func ErrName(err Errno) *c.Char
 
 //go:linkname ErrNameR C.uv_err_name_r
 func ErrNameR(err Errno, buf *c.Char, bufLen uintptr) *c.Char
+
+// Error implements the error interface with the libuv error message.
+func (e Errno) Error() string {
+	return c.GoString(Strerror(e))
+}
+
+// toError converts a libuv return code to an error; non-negative codes
+// mean success.
+func toError(ret c.Int) error {
+	if ret >= 0 {
+		return nil
+	}
+	return Errno(ret)
+}
@@ -0,0 +1,124 @@
+package libuv
+
+import (
+	_ "unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+// Metrics is a uv_metrics_t.
+type Metrics struct {
+	// Number of event loop iterations.
+	LoopCount uint64
+	// Number of events that have been processed by the event handler.
+	Events uint64
+	// Number of events that were waiting to be processed when the event
+	// provider was called.
+	EventsWaiting uint64
+	// Reserved for future use.
+	Reserved [13]*uint64
+}
+
+// ----------------------------------------------
+
+/* Metrics related function and method */
+
+// uint64_t uv_metrics_idle_time(uv_loop_t* loop)
+//
+// MetricsIdleTime returns the time, in nanoseconds, the loop has spent idle
+// in the kernel's event provider. It is only collected after the loop has
+// been configured with METRICS_IDLE_TIME.
+//
+// llgo:link (*Loop).MetricsIdleTime C.uv_metrics_idle_time
+func (loop *Loop) MetricsIdleTime() uint64 {
+	return 0
+}
+
+// int uv_metrics_info(uv_loop_t* loop, uv_metrics_t* metrics)
+//
+// llgo:link (*Loop).MetricsInfo C.uv_metrics_info
+func (loop *Loop) MetricsInfo(metrics *Metrics) c.Int {
+	return 0
+}
+
+// void uv_print_all_handles(uv_loop_t* loop, FILE* stream)
+//
+// llgo:link (*Loop).PrintAllHandles C.uv_print_all_handles
+func (loop *Loop) PrintAllHandles(stream c.FilePtr) {}
+
+// void uv_print_active_handles(uv_loop_t* loop, FILE* stream)
+//
+// llgo:link (*Loop).PrintActiveHandles C.uv_print_active_handles
+func (loop *Loop) PrintActiveHandles(stream c.FilePtr) {}
+
+// EnableMetricsIdleTime starts collecting the idle time reported by
+// MetricsIdleTime and Stats.
+func (loop *Loop) EnableMetricsIdleTime() c.Int {
+	return loop.Configure(METRICS_IDLE_TIME, 0)
+}
+
+// ----------------------------------------------
+
+/* Loop statistics */
+
+// LoopStats is a snapshot of a loop's handles and metrics.
+type LoopStats struct {
+	// Handles counts the loop's handles by type, including inactive ones
+	// and ones that are closing.
+	Handles [HANDLE_TYPE_MAX]int
+	// Active counts the active handles by type.
+	Active [HANDLE_TYPE_MAX]int
+	// Referenced counts the handles by type that keep the loop alive.
+	Referenced [HANDLE_TYPE_MAX]int
+	// Closing is the number of handles being closed.
+	Closing int
+
+	LoopCount     uint64
+	Events        uint64
+	EventsWaiting uint64
+	// IdleTime is zero unless EnableMetricsIdleTime has been called.
+	IdleTime uint64
+}
+
+// Total returns the number of handles of all types.
+func (s *LoopStats) Total() int {
+	n := 0
+	for _, v := range s.Handles {
+		n += v
+	}
+	return n
+}
+
+// Stats walks the loop's handles and reports how many there are of each
+// type, together with the loop metrics. Like every libuv call, it must be
+// called on the thread running loop.
+func (loop *Loop) Stats() LoopStats {
+	var stats LoopStats
+	loop.Walk(onStatsWalk, c.Pointer(&stats))
+	var m Metrics
+	if loop.MetricsInfo(&m) == 0 {
+		stats.LoopCount = m.LoopCount
+		stats.Events = m.Events
+		stats.EventsWaiting = m.EventsWaiting
+	}
+	stats.IdleTime = loop.MetricsIdleTime()
+	return stats
+}
+
+func onStatsWalk(handle *Handle, arg c.Pointer) {
+	stats := (*LoopStats)(arg)
+	t := handle.GetType()
+	if t < 0 || t >= HANDLE_TYPE_MAX {
+		t = UNKNOWN_HANDLE
+	}
+	stats.Handles[t]++
+	if handle.IsActive() != 0 {
+		stats.Active[t]++
+	}
+	if handle.HasRef() != 0 {
+		stats.Referenced[t]++
+	}
+	if handle.IsClosing() != 0 {
+		stats.Closing++
+	}
+}
@@ -232,10 +232,11 @@ func (handle *Handle) IsWritable() c.Int {
 	return 0
 }
 
-//go:linkname Pipe C.uv_pipe
-func Pipe(fds [2]File, readFlags c.Int, writeFlags c.Int) c.Int {
-	return 0
-}
+// MakePipe creates a pair of connected pipe file descriptors: fds[0] is the
+// read end and fds[1] is the write end.
+//
+//go:linkname MakePipe C.uv_pipe
+func MakePipe(fds *[2]File, readFlags c.Int, writeFlags c.Int) c.Int
 
 //go:linkname Socketpair C.uv_socketpair
 func Socketpair(_type c.Int, protocol c.Int, socketVector [2]OsSock, flag0 c.Int, flag1 c.Int) c.Int {
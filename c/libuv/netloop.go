@@ -0,0 +1,70 @@
+package libuv
+
+import (
+	"sync"
+
+	"github.com/goplus/lib/c"
+)
+
+// netLoop is a private loop running on its own thread. It backs the
+// blocking net.Conn and net.Listener types: every libuv call they make is
+// handed to the loop thread with exec or call, since libuv handles must only
+// be touched by the thread running their loop.
+type netLoop struct {
+	loop  *Loop
+	async Async
+
+	mu    sync.Mutex
+	queue []func()
+}
+
+var (
+	defaultNetLoopOnce sync.Once
+	defaultNetLoop     *netLoop
+)
+
+// getNetLoop starts the shared network loop on first use.
+func getNetLoop() *netLoop {
+	defaultNetLoopOnce.Do(func() {
+		l := &netLoop{loop: (*Loop)(c.Malloc(LoopSize()))}
+		l.loop.Init()
+		l.loop.Async(&l.async, onNetLoopWakeup)
+		l.async.Data = c.Pointer(l)
+		defaultNetLoop = l
+		// The async handle is never closed, so Run only returns if the
+		// loop is stopped.
+		go l.loop.Run(RUN_DEFAULT)
+	})
+	return defaultNetLoop
+}
+
+func onNetLoopWakeup(a *Async) {
+	l := (*netLoop)(a.Data)
+	l.mu.Lock()
+	queue := l.queue
+	l.queue = nil
+	l.mu.Unlock()
+	for _, fn := range queue {
+		fn()
+	}
+}
+
+// exec runs fn on the loop thread without waiting for it. Functions run in
+// the order they were queued.
+func (l *netLoop) exec(fn func()) {
+	l.mu.Lock()
+	l.queue = append(l.queue, fn)
+	l.mu.Unlock()
+	l.async.Send()
+}
+
+// call runs fn on the loop thread and waits for it to return. It must not be
+// used from the loop thread itself.
+func (l *netLoop) call(fn func()) {
+	done := make(chan struct{})
+	l.exec(func() {
+		fn()
+		close(done)
+	})
+	<-done
+}
@@ -0,0 +1,124 @@
+package libuv
+
+import (
+	_ "unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+const (
+	/*
+	 * Do not truncate the name passed to Bind2 or PipeConnect2; fail with
+	 * EINVAL instead when it does not fit in sockaddr_un.sun_path.
+	 */
+	PIPE_NO_TRUNCATE PipeFlags = 1
+)
+
+const (
+	PIPE_READABLE PipeMode = 1
+	PIPE_WRITABLE PipeMode = 2
+)
+
+type PipeFlags c.Uint
+
+type PipeMode c.Int
+
+// ----------------------------------------------
+
+/* Handle types. */
+
+// Pipe is a uv_pipe_t. It provides an abstraction over local domain sockets
+// on Unix and named pipes on Windows, and is used as a Stream. A Pipe
+// initialized with ipc set to 1 can pass handles between processes with
+// Write2 and receive them with PendingCount, PendingType and Accept.
+type Pipe struct {
+	Data   c.Pointer
+	Unused [272]byte
+}
+
+// ----------------------------------------------
+
+/* Pipe related function and method */
+
+// int uv_pipe_init(uv_loop_t* loop, uv_pipe_t* handle, int ipc)
+//
+//go:linkname InitPipe C.uv_pipe_init
+func InitPipe(loop *Loop, pipe *Pipe, ipc c.Int) c.Int
+
+// int uv_pipe_open(uv_pipe_t* handle, uv_file file)
+//
+// llgo:link (*Pipe).Open C.uv_pipe_open
+func (pipe *Pipe) Open(file File) c.Int {
+	return 0
+}
+
+// int uv_pipe_bind(uv_pipe_t* handle, const char* name)
+//
+// llgo:link (*Pipe).Bind C.uv_pipe_bind
+func (pipe *Pipe) Bind(name *c.Char) c.Int {
+	return 0
+}
+
+// int uv_pipe_bind2(uv_pipe_t* handle, const char* name, size_t namelen, unsigned int flags)
+//
+// llgo:link (*Pipe).Bind2 C.uv_pipe_bind2
+func (pipe *Pipe) Bind2(name *c.Char, nameLen uintptr, flags PipeFlags) c.Int {
+	return 0
+}
+
+// void uv_pipe_connect(uv_connect_t* req, uv_pipe_t* handle, const char* name, uv_connect_cb cb)
+//
+//go:linkname PipeConnect C.uv_pipe_connect
+func PipeConnect(req *Connect, pipe *Pipe, name *c.Char, connectCb ConnectCb)
+
+// int uv_pipe_connect2(uv_connect_t* req, uv_pipe_t* handle, const char* name, size_t namelen, unsigned int flags, uv_connect_cb cb)
+//
+//go:linkname PipeConnect2 C.uv_pipe_connect2
+func PipeConnect2(req *Connect, pipe *Pipe, name *c.Char, nameLen uintptr, flags PipeFlags, connectCb ConnectCb) c.Int
+
+// int uv_pipe_getsockname(const uv_pipe_t* handle, char* buffer, size_t* size)
+//
+// llgo:link (*Pipe).Getsockname C.uv_pipe_getsockname
+func (pipe *Pipe) Getsockname(buffer *c.Char, size *uintptr) c.Int {
+	return 0
+}
+
+// int uv_pipe_getpeername(const uv_pipe_t* handle, char* buffer, size_t* size)
+//
+// llgo:link (*Pipe).Getpeername C.uv_pipe_getpeername
+func (pipe *Pipe) Getpeername(buffer *c.Char, size *uintptr) c.Int {
+	return 0
+}
+
+// void uv_pipe_pending_instances(uv_pipe_t* handle, int count)
+//
+// llgo:link (*Pipe).PendingInstances C.uv_pipe_pending_instances
+func (pipe *Pipe) PendingInstances(count c.Int) {}
+
+// int uv_pipe_pending_count(uv_pipe_t* handle)
+//
+// PendingCount returns the number of handles received over an IPC pipe that
+// are waiting to be accepted. Call it from the ReadCb; each pending handle is
+// received by initializing a handle of PendingType and passing it to Accept.
+//
+// llgo:link (*Pipe).PendingCount C.uv_pipe_pending_count
+func (pipe *Pipe) PendingCount() c.Int {
+	return 0
+}
+
+// uv_handle_type uv_pipe_pending_type(uv_pipe_t* handle)
+//
+// llgo:link (*Pipe).PendingType C.uv_pipe_pending_type
+func (pipe *Pipe) PendingType() HandleType {
+	return 0
+}
+
+// int uv_pipe_chmod(uv_pipe_t* handle, int flags)
+//
+// Chmod makes the pipe accessible by other users; flags is a combination of
+// PIPE_READABLE and PIPE_WRITABLE.
+//
+// llgo:link (*Pipe).Chmod C.uv_pipe_chmod
+func (pipe *Pipe) Chmod(flags PipeMode) c.Int {
+	return 0
+}
@@ -0,0 +1,190 @@
+package libuv
+
+import (
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+const (
+	/*
+	 * Set the child process' user id.
+	 */
+	PROCESS_SETUID ProcessFlags = 1 << 0
+	/*
+	 * Set the child process' group id.
+	 */
+	PROCESS_SETGID ProcessFlags = 1 << 1
+	/*
+	 * Do not wrap any arguments in quotes, or perform any other escaping, when
+	 * converting the argument list into a command line string. This option is
+	 * only meaningful on Windows systems.
+	 */
+	PROCESS_WINDOWS_VERBATIM_ARGUMENTS ProcessFlags = 1 << 2
+	/*
+	 * Spawn the child process in a detached state - this will make it a process
+	 * group leader, and will effectively enable the child to keep running after
+	 * the parent exits. Note that the child process will still keep the
+	 * parent's event loop alive unless the parent process calls uv_unref() on
+	 * the child's process handle.
+	 */
+	PROCESS_DETACHED ProcessFlags = 1 << 3
+	/*
+	 * Hide the subprocess window that would normally be created. This option is
+	 * only meaningful on Windows systems.
+	 */
+	PROCESS_WINDOWS_HIDE ProcessFlags = 1 << 4
+	/*
+	 * Hide the subprocess console window that would normally be created. This
+	 * option is only meaningful on Windows systems.
+	 */
+	PROCESS_WINDOWS_HIDE_CONSOLE ProcessFlags = 1 << 5
+	/*
+	 * Hide the subprocess GUI window that would normally be created. This
+	 * option is only meaningful on Windows systems.
+	 */
+	PROCESS_WINDOWS_HIDE_GUI ProcessFlags = 1 << 6
+	/*
+	 * On Windows, if the path to the program to execute, specified in
+	 * uv_process_options_t's file field, has a directory component,
+	 * search for the exact file name before trying variants with
+	 * extensions like '.exe' or '.cmd'.
+	 */
+	PROCESS_WINDOWS_FILE_PATH_EXACT_NAME ProcessFlags = 1 << 7
+)
+
+const (
+	IGNORE         StdioFlags = 0x00
+	CREATE_PIPE    StdioFlags = 0x01
+	INHERIT_FD     StdioFlags = 0x02
+	INHERIT_STREAM StdioFlags = 0x04
+	/*
+	 * When CREATE_PIPE is specified, READABLE_PIPE and WRITABLE_PIPE
+	 * determine the direction of flow, from the child process' perspective. Both
+	 * flags may be specified to create a duplex data stream.
+	 */
+	READABLE_PIPE StdioFlags = 0x10
+	WRITABLE_PIPE StdioFlags = 0x20
+	/*
+	 * When CREATE_PIPE is specified, specifying NONBLOCK_PIPE opens the
+	 * handle in non-blocking mode in the child. This may cause loss of data,
+	 * if the child is not designed to handle to encounter this mode,
+	 * but can also be significantly more efficient.
+	 */
+	NONBLOCK_PIPE   StdioFlags = 0x40
+	OVERLAPPED_PIPE StdioFlags = 0x40 /* old name, for compatibility */
+)
+
+type ProcessFlags c.Uint
+
+type StdioFlags c.Int
+
+type Uid c.Uint
+
+type Gid c.Uint
+
+// ----------------------------------------------
+
+/* Handle types. */
+
+// Process is a uv_process_t. It represents a child process spawned with Spawn.
+type Process struct {
+	Data   c.Pointer
+	Unused [128]byte
+}
+
+// StdioContainer is a uv_stdio_container_t. It describes how a single file
+// descriptor of a child process is set up. Data holds either a *Stream (for
+// CREATE_PIPE and INHERIT_STREAM) or a file descriptor (for INHERIT_FD); use
+// SetStream and SetFd to fill it in.
+type StdioContainer struct {
+	Flags StdioFlags
+	Data  c.Pointer
+}
+
+// ProcessOptions is a uv_process_options_t. Args and Env are NULL-terminated
+// arrays of C strings; a nil Env inherits the parent's environment and a nil
+// Cwd inherits the parent's working directory. Stdio describes the child's
+// file descriptors starting at 0; descriptors past StdioCount are closed.
+type ProcessOptions struct {
+	ExitCb     ExitCb
+	File       *c.Char
+	Args       **c.Char
+	Env        **c.Char
+	Cwd        *c.Char
+	Flags      ProcessFlags
+	StdioCount c.Int
+	Stdio      *StdioContainer
+	Uid        Uid
+	Gid        Gid
+}
+
+// ----------------------------------------------
+
+/* Function type */
+
+// typedef void (*uv_exit_cb)(uv_process_t*, int64_t exit_status, int term_signal);
+//
+// llgo:type C
+type ExitCb func(process *Process, exitStatus int64, termSignal c.Int)
+
+// ----------------------------------------------
+
+/* StdioContainer related method */
+
+// SetStream makes the child's descriptor use stream, which must be an
+// initialized Pipe for CREATE_PIPE or an open stream for INHERIT_STREAM.
+func (s *StdioContainer) SetStream(flags StdioFlags, stream *Stream) {
+	s.Flags = flags
+	s.Data = c.Pointer(stream)
+}
+
+// SetFd makes the child's descriptor inherit fd from the parent.
+func (s *StdioContainer) SetFd(fd c.Int) {
+	s.Flags = INHERIT_FD
+	s.Data = nil
+	*(*c.Int)(unsafe.Pointer(&s.Data)) = fd
+}
+
+// Stream returns the stream stored in s.
+func (s *StdioContainer) Stream() *Stream {
+	return (*Stream)(s.Data)
+}
+
+// Fd returns the file descriptor stored in s.
+func (s *StdioContainer) Fd() c.Int {
+	return *(*c.Int)(unsafe.Pointer(&s.Data))
+}
+
+// ----------------------------------------------
+
+/* Process related function and method */
+
+// int uv_spawn(uv_loop_t* loop, uv_process_t* handle, const uv_process_options_t* options)
+//
+//go:linkname Spawn C.uv_spawn
+func Spawn(loop *Loop, handle *Process, options *ProcessOptions) c.Int
+
+// void uv_disable_stdio_inheritance(void)
+//
+//go:linkname DisableStdioInheritance C.uv_disable_stdio_inheritance
+func DisableStdioInheritance()
+
+// int uv_kill(int pid, int signum)
+//
+//go:linkname Kill C.uv_kill
+func Kill(pid c.Int, signum c.Int) c.Int
+
+// int uv_process_kill(uv_process_t* handle, int signum)
+//
+// llgo:link (*Process).Kill C.uv_process_kill
+func (handle *Process) Kill(signum c.Int) c.Int {
+	return 0
+}
+
+// uv_pid_t uv_process_get_pid(const uv_process_t* handle)
+//
+// llgo:link (*Process).GetPid C.uv_process_get_pid
+func (handle *Process) GetPid() c.Int {
+	return 0
+}
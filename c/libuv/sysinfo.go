@@ -0,0 +1,161 @@
+package libuv
+
+import (
+	_ "unsafe"
+
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/c/net"
+)
+
+const (
+	MAXHOSTNAMESIZE = 256
+)
+
+const (
+	PRIORITY_LOW          = 19
+	PRIORITY_BELOW_NORMAL = 10
+	PRIORITY_NORMAL       = 0
+	PRIORITY_ABOVE_NORMAL = -7
+	PRIORITY_HIGH         = -14
+	PRIORITY_HIGHEST      = -20
+)
+
+// CpuTimes is the time, in milliseconds, a CPU spent in each mode.
+type CpuTimes struct {
+	User uint64
+	Nice uint64
+	Sys  uint64
+	Idle uint64
+	Irq  uint64
+}
+
+// CpuInfo is a uv_cpu_info_t.
+type CpuInfo struct {
+	Model    *c.Char
+	Speed    c.Int
+	CpuTimes CpuTimes
+}
+
+// InterfaceAddress is a uv_interface_address_t. Address and Netmask hold a
+// sockaddr_in or sockaddr_in6; use Addr and Mask to read them.
+type InterfaceAddress struct {
+	Name       *c.Char
+	PhysAddr   [6]c.Char
+	IsInternal c.Int
+	Address    [28]byte
+	Netmask    [28]byte
+}
+
+// Utsname is a uv_utsname_t.
+type Utsname struct {
+	Sysname [256]c.Char
+	Release [256]c.Char
+	Version [256]c.Char
+	Machine [256]c.Char
+}
+
+// Timeval is a uv_timeval_t.
+type Timeval struct {
+	Sec  c.Long
+	Usec c.Long
+}
+
+// Rusage is a uv_rusage_t.
+type Rusage struct {
+	Utime    Timeval /* user CPU time used */
+	Stime    Timeval /* system CPU time used */
+	Maxrss   uint64  /* maximum resident set size */
+	Ixrss    uint64  /* integral shared memory size */
+	Idrss    uint64  /* integral unshared data size */
+	Isrss    uint64  /* integral unshared stack size */
+	Minflt   uint64  /* page reclaims (soft page faults) */
+	Majflt   uint64  /* page faults (hard page faults) */
+	Nswap    uint64  /* swaps */
+	Inblock  uint64  /* block input operations */
+	Oublock  uint64  /* block output operations */
+	Msgsnd   uint64  /* IPC messages sent */
+	Msgrcv   uint64  /* IPC messages received */
+	Nsignals uint64  /* signals received */
+	Nvcsw    uint64  /* voluntary context switches */
+	Nivcsw   uint64  /* involuntary context switches */
+}
+
+// Addr returns the interface address as a socket address.
+func (a *InterfaceAddress) Addr() *net.SockAddr {
+	return (*net.SockAddr)(c.Pointer(&a.Address[0]))
+}
+
+// Mask returns the interface netmask as a socket address.
+func (a *InterfaceAddress) Mask() *net.SockAddr {
+	return (*net.SockAddr)(c.Pointer(&a.Netmask[0]))
+}
+
+// ----------------------------------------------
+
+/* System information functions */
+
+//go:linkname Hrtime C.uv_hrtime
+func Hrtime() uint64
+
+//go:linkname ResidentSetMemory C.uv_resident_set_memory
+func ResidentSetMemory(rss *uintptr) c.Int
+
+//go:linkname Uptime C.uv_uptime
+func Uptime(uptime *c.Double) c.Int
+
+//go:linkname Getrusage C.uv_getrusage
+func Getrusage(rusage *Rusage) c.Int
+
+//go:linkname GetCpuInfo C.uv_cpu_info
+func GetCpuInfo(cpuInfos **CpuInfo, count *c.Int) c.Int
+
+//go:linkname FreeCpuInfo C.uv_free_cpu_info
+func FreeCpuInfo(cpuInfos *CpuInfo, count c.Int)
+
+//go:linkname AvailableParallelism C.uv_available_parallelism
+func AvailableParallelism() c.Uint
+
+//go:linkname InterfaceAddresses C.uv_interface_addresses
+func InterfaceAddresses(addresses **InterfaceAddress, count *c.Int) c.Int
+
+//go:linkname FreeInterfaceAddresses C.uv_free_interface_addresses
+func FreeInterfaceAddresses(addresses *InterfaceAddress, count c.Int)
+
+//go:linkname Loadavg C.uv_loadavg
+func Loadavg(avg *[3]c.Double)
+
+//go:linkname GetFreeMemory C.uv_get_free_memory
+func GetFreeMemory() uint64
+
+//go:linkname GetTotalMemory C.uv_get_total_memory
+func GetTotalMemory() uint64
+
+//go:linkname GetConstrainedMemory C.uv_get_constrained_memory
+func GetConstrainedMemory() uint64
+
+//go:linkname GetAvailableMemory C.uv_get_available_memory
+func GetAvailableMemory() uint64
+
+//go:linkname OsUname C.uv_os_uname
+func OsUname(buffer *Utsname) c.Int
+
+//go:linkname OsGetpid C.uv_os_getpid
+func OsGetpid() c.Int
+
+//go:linkname OsGetppid C.uv_os_getppid
+func OsGetppid() c.Int
+
+//go:linkname OsGethostname C.uv_os_gethostname
+func OsGethostname(buffer *c.Char, size *uintptr) c.Int
+
+//go:linkname OsHomedir C.uv_os_homedir
+func OsHomedir(buffer *c.Char, size *uintptr) c.Int
+
+//go:linkname OsTmpdir C.uv_os_tmpdir
+func OsTmpdir(buffer *c.Char, size *uintptr) c.Int
+
+//go:linkname OsGetpriority C.uv_os_getpriority
+func OsGetpriority(pid c.Int, priority *c.Int) c.Int
+
+//go:linkname OsSetpriority C.uv_os_setpriority
+func OsSetpriority(pid c.Int, priority c.Int) c.Int
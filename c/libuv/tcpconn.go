@@ -0,0 +1,608 @@
+package libuv
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/goplus/lib/c"
+)
+
+const (
+	defaultBacklog = 128
+	// maxReadBuffer is how much received data a TcpConn buffers before it
+	// stops reading from the socket until Read catches up.
+	maxReadBuffer = 256 << 10
+	// defaultKeepAlivePeriod matches the net package's default.
+	defaultKeepAlivePeriod = 15 * time.Second
+)
+
+// ----------------------------------------------
+
+/* Address resolution */
+
+// resolveAddrs resolves address for a TCP or UDP network ("tcp", "tcp4",
+// "tcp6", "udp", "udp4" or "udp6"). An empty host resolves to the
+// unspecified address when listen is set and to the loopback address
+// otherwise.
+func resolveAddrs(network, address string, listen bool) ([]netip.AddrPort, error) {
+	ipNet := "ip"
+	switch network {
+	case "tcp", "udp":
+	case "tcp4", "udp4":
+		ipNet = "ip4"
+	case "tcp6", "udp6":
+		ipNet = "ip6"
+	default:
+		return nil, net.UnknownNetworkError(network)
+	}
+	host, service, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := net.LookupPort(network, service)
+	if err != nil {
+		return nil, err
+	}
+	var ips []netip.Addr
+	switch {
+	case host == "" && listen:
+		if ipNet == "ip4" {
+			ips = []netip.Addr{netip.IPv4Unspecified()}
+		} else {
+			ips = []netip.Addr{netip.IPv6Unspecified(), netip.IPv4Unspecified()}
+		}
+	case host == "":
+		if ipNet == "ip6" {
+			ips = []netip.Addr{netip.IPv6Loopback()}
+		} else {
+			ips = []netip.Addr{netip.AddrFrom4([4]byte{127, 0, 0, 1})}
+		}
+	default:
+		if ip, err := netip.ParseAddr(host); err == nil {
+			ips = []netip.Addr{ip}
+		} else if ips, err = net.DefaultResolver.LookupNetIP(context.Background(), ipNet, host); err != nil {
+			return nil, err
+		}
+	}
+	var addrs []netip.AddrPort
+	for _, ip := range ips {
+		ip = ip.Unmap()
+		if ipNet == "ip4" && !ip.Is4() || ipNet == "ip6" && !ip.Is6() {
+			continue
+		}
+		addrs = append(addrs, netip.AddrPortFrom(ip, uint16(port)))
+	}
+	if len(addrs) == 0 {
+		return nil, &net.AddrError{Err: "no suitable address found", Addr: address}
+	}
+	return addrs, nil
+}
+
+func tcpAddrOf(tcp *Tcp, peer bool) net.Addr {
+	var sa sockaddr
+	nameLen := c.Int(len(sa.buf))
+	var ret c.Int
+	if peer {
+		ret = tcp.Getpeername(sa.ptr(), &nameLen)
+	} else {
+		ret = tcp.Getsockname(sa.ptr(), &nameLen)
+	}
+	if ret != 0 {
+		return nil
+	}
+	ap, ok := addrPortOf(sa.ptr())
+	if !ok {
+		return nil
+	}
+	return net.TCPAddrFromAddrPort(ap)
+}
+
+// ----------------------------------------------
+
+/* Listener */
+
+// TcpListener is a net.Listener backed by a libuv Tcp handle.
+type TcpListener struct {
+	l    *netLoop
+	tcp  *Tcp
+	addr net.Addr
+
+	accepted  chan *TcpConn
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Listen announces on the local network address. network must be "tcp",
+// "tcp4" or "tcp6". Connections are served by a libuv loop running on its
+// own thread, so the returned listener and its connections can be used from
+// any goroutine with the usual blocking net semantics.
+func Listen(network, address string) (net.Listener, error) {
+	addrs, err := resolveAddrs(network, address, true)
+	if err != nil {
+		return nil, &net.OpError{Op: "listen", Net: network, Err: err}
+	}
+	ln := &TcpListener{
+		l:        getNetLoop(),
+		accepted: make(chan *TcpConn, defaultBacklog),
+		closed:   make(chan struct{}),
+	}
+	for _, ap := range addrs {
+		ln.l.call(func() { err = ln.listen(ap) })
+		if err == nil {
+			return ln, nil
+		}
+	}
+	return nil, &net.OpError{Op: "listen", Net: network, Addr: net.TCPAddrFromAddrPort(addrs[0]), Err: err}
+}
+
+func (ln *TcpListener) listen(ap netip.AddrPort) error {
+	tcp := new(Tcp)
+	if err := toError(InitTcp(ln.l.loop, tcp)); err != nil {
+		return err
+	}
+	var sa sockaddr
+	err := sa.set(ap)
+	if err == nil {
+		err = toError(tcp.Bind(sa.ptr(), 0))
+	}
+	if err == nil {
+		err = (*Stream)(c.Pointer(tcp)).ListenFunc(defaultBacklog, ln.onConnection)
+	}
+	if err != nil {
+		(*Handle)(c.Pointer(tcp)).CloseFunc(nil)
+		return err
+	}
+	ln.tcp = tcp
+	ln.addr = tcpAddrOf(tcp, false)
+	return nil
+}
+
+// onConnection runs on the loop thread.
+func (ln *TcpListener) onConnection(err error) {
+	if err != nil {
+		return
+	}
+	tcp := new(Tcp)
+	InitTcp(ln.l.loop, tcp)
+	if (*Stream)(c.Pointer(ln.tcp)).Accept((*Stream)(c.Pointer(tcp))) != 0 {
+		(*Handle)(c.Pointer(tcp)).CloseFunc(nil)
+		return
+	}
+	conn := newTcpConn(ln.l, tcp)
+	select {
+	case ln.accepted <- conn:
+	default:
+		// Nobody is accepting; drop the connection like a full backlog.
+		conn.Close()
+	}
+}
+
+// Accept waits for and returns the next connection to the listener.
+func (ln *TcpListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-ln.accepted:
+		return conn, nil
+	case <-ln.closed:
+		return nil, &net.OpError{Op: "accept", Net: "tcp", Addr: ln.addr, Err: net.ErrClosed}
+	}
+}
+
+// Close stops listening. Connections already accepted are not closed.
+func (ln *TcpListener) Close() error {
+	err := net.ErrClosed
+	ln.closeOnce.Do(func() {
+		err = nil
+		close(ln.closed)
+		ln.l.call(func() {
+			(*Handle)(c.Pointer(ln.tcp)).CloseFunc(nil)
+			for {
+				select {
+				case conn := <-ln.accepted:
+					conn.Close()
+				default:
+					return
+				}
+			}
+		})
+	})
+	if err != nil {
+		return &net.OpError{Op: "close", Net: "tcp", Addr: ln.addr, Err: err}
+	}
+	return nil
+}
+
+// Addr returns the listener's network address.
+func (ln *TcpListener) Addr() net.Addr {
+	return ln.addr
+}
+
+// ----------------------------------------------
+
+/* Conn */
+
+// deadline is a read or write deadline driven by a libuv Timer. Its channel
+// is closed once the deadline passes.
+type deadline struct {
+	l      *netLoop
+	timer  *Timer
+	closed <-chan struct{}
+
+	mu     sync.Mutex
+	cancel chan struct{}
+}
+
+func (d *deadline) init(l *netLoop, closed <-chan struct{}) {
+	d.l, d.closed = l, closed
+	d.timer = new(Timer)
+	d.cancel = make(chan struct{})
+	InitTimer(l.loop, d.timer)
+}
+
+func (d *deadline) set(t time.Time) {
+	d.mu.Lock()
+	select {
+	case <-d.cancel:
+		d.cancel = make(chan struct{})
+	default:
+	}
+	cancel := d.cancel
+	var timeoutMs uint64
+	if !t.IsZero() {
+		if dur := time.Until(t); dur <= 0 {
+			close(cancel)
+			t = time.Time{}
+		} else {
+			timeoutMs = uint64((dur + time.Millisecond - 1) / time.Millisecond)
+		}
+	}
+	d.mu.Unlock()
+
+	d.l.exec(func() {
+		select {
+		case <-d.closed:
+			return
+		default:
+		}
+		d.timer.Stop()
+		if t.IsZero() {
+			return
+		}
+		d.timer.StartFunc(timeoutMs, 0, func() {
+			d.mu.Lock()
+			if d.cancel == cancel {
+				select {
+				case <-cancel:
+				default:
+					close(cancel)
+				}
+			}
+			d.mu.Unlock()
+		})
+	})
+}
+
+func (d *deadline) wait() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.cancel
+}
+
+// TcpConn is a net.Conn backed by a libuv Tcp handle.
+type TcpConn struct {
+	l      *netLoop
+	tcp    *Tcp
+	laddr  net.Addr
+	raddr  net.Addr
+	rdl    deadline
+	wdl    deadline
+	closed chan struct{}
+
+	mu       sync.Mutex
+	rbuf     []byte
+	rerr     error
+	paused   bool
+	readable chan struct{}
+
+	closeOnce       sync.Once
+	keepAlivePeriod time.Duration
+}
+
+// Dial connects to the address on the named network, which must be "tcp",
+// "tcp4" or "tcp6". The connection is served by the same loop as Listen.
+func Dial(network, address string) (net.Conn, error) {
+	addrs, err := resolveAddrs(network, address, false)
+	if err != nil {
+		return nil, &net.OpError{Op: "dial", Net: network, Err: err}
+	}
+	l := getNetLoop()
+	for _, ap := range addrs {
+		var conn *TcpConn
+		if conn, err = dialTcp(l, ap); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, &net.OpError{Op: "dial", Net: network, Addr: net.TCPAddrFromAddrPort(addrs[len(addrs)-1]), Err: err}
+}
+
+func dialTcp(l *netLoop, ap netip.AddrPort) (*TcpConn, error) {
+	tcp := new(Tcp)
+	done := make(chan error, 1)
+	inited := false
+	l.call(func() {
+		if err := toError(InitTcp(l.loop, tcp)); err != nil {
+			done <- err
+			return
+		}
+		inited = true
+		var sa sockaddr
+		err := sa.set(ap)
+		if err == nil {
+			err = tcp.ConnectFunc(sa.ptr(), func(err error) { done <- err })
+		}
+		if err != nil {
+			done <- err
+		}
+	})
+	if err := <-done; err != nil {
+		// Only an initialized handle can be closed.
+		if inited {
+			l.exec(func() { (*Handle)(c.Pointer(tcp)).CloseFunc(nil) })
+		}
+		return nil, err
+	}
+	var conn *TcpConn
+	l.call(func() { conn = newTcpConn(l, tcp) })
+	return conn, nil
+}
+
+// newTcpConn wraps a connected tcp handle and starts reading from it. It
+// must be called on the loop thread.
+func newTcpConn(l *netLoop, tcp *Tcp) *TcpConn {
+	conn := &TcpConn{
+		l:        l,
+		tcp:      tcp,
+		laddr:    tcpAddrOf(tcp, false),
+		raddr:    tcpAddrOf(tcp, true),
+		closed:   make(chan struct{}),
+		readable: make(chan struct{}, 1),
+	}
+	conn.rdl.init(l, conn.closed)
+	conn.wdl.init(l, conn.closed)
+	if err := conn.stream().StartReadFunc(conn.onRead); err != nil {
+		conn.rerr = err
+	}
+	return conn
+}
+
+func (conn *TcpConn) stream() *Stream {
+	return (*Stream)(c.Pointer(conn.tcp))
+}
+
+// onRead runs on the loop thread.
+func (conn *TcpConn) onRead(data []byte, err error) {
+	conn.mu.Lock()
+	if err != nil {
+		conn.rerr = err
+	} else {
+		conn.rbuf = append(conn.rbuf, data...)
+		if len(conn.rbuf) >= maxReadBuffer && !conn.paused {
+			conn.paused = true
+			conn.stream().StopRead()
+		}
+	}
+	conn.mu.Unlock()
+	select {
+	case conn.readable <- struct{}{}:
+	default:
+	}
+}
+
+func (conn *TcpConn) opError(op string, err error) error {
+	return &net.OpError{Op: op, Net: "tcp", Source: conn.laddr, Addr: conn.raddr, Err: err}
+}
+
+// Read reads data from the connection.
+func (conn *TcpConn) Read(b []byte) (int, error) {
+	for {
+		select {
+		case <-conn.closed:
+			return 0, conn.opError("read", net.ErrClosed)
+		case <-conn.rdl.wait():
+			return 0, conn.opError("read", os.ErrDeadlineExceeded)
+		default:
+		}
+		conn.mu.Lock()
+		if len(conn.rbuf) > 0 {
+			n := copy(b, conn.rbuf)
+			conn.rbuf = conn.rbuf[n:]
+			if len(conn.rbuf) == 0 {
+				conn.rbuf = nil
+			}
+			resume := conn.paused && len(conn.rbuf) < maxReadBuffer/2
+			if resume {
+				conn.paused = false
+			}
+			conn.mu.Unlock()
+			if resume {
+				conn.l.exec(func() {
+					select {
+					case <-conn.closed:
+					default:
+						conn.stream().StartReadFunc(conn.onRead)
+					}
+				})
+			}
+			return n, nil
+		}
+		rerr := conn.rerr
+		conn.mu.Unlock()
+		if rerr == io.EOF {
+			return 0, io.EOF
+		} else if rerr != nil {
+			return 0, conn.opError("read", rerr)
+		}
+		select {
+		case <-conn.readable:
+		case <-conn.closed:
+		case <-conn.rdl.wait():
+		}
+	}
+}
+
+// Write writes data to the connection. It returns once the data has been
+// handed to the kernel, or when the write deadline passes.
+func (conn *TcpConn) Write(b []byte) (int, error) {
+	select {
+	case <-conn.closed:
+		return 0, conn.opError("write", net.ErrClosed)
+	case <-conn.wdl.wait():
+		return 0, conn.opError("write", os.ErrDeadlineExceeded)
+	default:
+	}
+	done := make(chan error, 1)
+	var err error
+	conn.l.call(func() {
+		// WriteFunc copies b before returning, so b is not referenced once
+		// call returns, even if the deadline passes first.
+		err = conn.stream().WriteFunc(b, func(err error) { done <- err })
+	})
+	if err == nil {
+		select {
+		case err = <-done:
+		case <-conn.closed:
+			err = net.ErrClosed
+		case <-conn.wdl.wait():
+			err = os.ErrDeadlineExceeded
+		}
+	}
+	if err != nil {
+		return 0, conn.opError("write", err)
+	}
+	return len(b), nil
+}
+
+// Close closes the connection. Blocked Read and Write calls return
+// net.ErrClosed.
+func (conn *TcpConn) Close() error {
+	err := net.ErrClosed
+	conn.closeOnce.Do(func() {
+		err = nil
+		close(conn.closed)
+		conn.l.exec(conn.closeHandles)
+	})
+	if err != nil {
+		return conn.opError("close", err)
+	}
+	return nil
+}
+
+// closeHandles closes the handle and its timers. It runs on the loop thread.
+func (conn *TcpConn) closeHandles() {
+	(*Handle)(c.Pointer(conn.tcp)).CloseFunc(nil)
+	(*Handle)(c.Pointer(conn.rdl.timer)).CloseFunc(nil)
+	(*Handle)(c.Pointer(conn.wdl.timer)).CloseFunc(nil)
+}
+
+// CloseWrite shuts down the writing side of the connection once pending
+// writes have been sent.
+func (conn *TcpConn) CloseWrite() error {
+	done := make(chan error, 1)
+	conn.l.exec(func() {
+		select {
+		case <-conn.closed:
+			done <- net.ErrClosed
+			return
+		default:
+		}
+		if err := conn.stream().ShutdownFunc(func(err error) { done <- err }); err != nil {
+			done <- err
+		}
+	})
+	if err := <-done; err != nil {
+		return conn.opError("close", err)
+	}
+	return nil
+}
+
+// SetNoDelay controls whether Nagle's algorithm is disabled.
+func (conn *TcpConn) SetNoDelay(noDelay bool) error {
+	enable := c.Int(0)
+	if noDelay {
+		enable = 1
+	}
+	return conn.setOption("set", func() c.Int { return conn.tcp.Nodelay(enable) })
+}
+
+// SetKeepAlive enables or disables TCP keep-alive probes.
+func (conn *TcpConn) SetKeepAlive(keepalive bool) error {
+	enable := c.Int(0)
+	if keepalive {
+		enable = 1
+	}
+	period := conn.keepAlivePeriod
+	if period == 0 {
+		period = defaultKeepAlivePeriod
+	}
+	return conn.setOption("set", func() c.Int { return conn.tcp.KeepAlive(enable, c.Uint(period/time.Second)) })
+}
+
+// SetKeepAlivePeriod enables keep-alive probes and sets the idle time
+// before the first probe, rounded down to whole seconds.
+func (conn *TcpConn) SetKeepAlivePeriod(d time.Duration) error {
+	if d < time.Second {
+		return conn.opError("set", errors.New("keep-alive period must be at least one second"))
+	}
+	conn.keepAlivePeriod = d
+	return conn.SetKeepAlive(true)
+}
+
+func (conn *TcpConn) setOption(op string, fn func() c.Int) error {
+	var ret c.Int
+	conn.l.call(func() {
+		select {
+		case <-conn.closed:
+			ret = c.Int(EBADF)
+		default:
+			ret = fn()
+		}
+	})
+	if err := toError(ret); err != nil {
+		return conn.opError(op, err)
+	}
+	return nil
+}
+
+// LocalAddr returns the local network address.
+func (conn *TcpConn) LocalAddr() net.Addr {
+	return conn.laddr
+}
+
+// RemoteAddr returns the remote network address.
+func (conn *TcpConn) RemoteAddr() net.Addr {
+	return conn.raddr
+}
+
+// SetDeadline sets the read and write deadlines.
+func (conn *TcpConn) SetDeadline(t time.Time) error {
+	conn.rdl.set(t)
+	conn.wdl.set(t)
+	return nil
+}
+
+// SetReadDeadline sets the deadline for future and pending Read calls.
+func (conn *TcpConn) SetReadDeadline(t time.Time) error {
+	conn.rdl.set(t)
+	return nil
+}
+
+// SetWriteDeadline sets the deadline for future and pending Write calls.
+func (conn *TcpConn) SetWriteDeadline(t time.Time) error {
+	conn.wdl.set(t)
+	return nil
+}
@@ -0,0 +1,98 @@
+package libuv
+
+import (
+	_ "unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+const (
+	/* Initial/normal terminal mode */
+	TTY_MODE_NORMAL TtyMode = iota
+	/*
+	 * Raw input mode (On Windows, ENABLE_WINDOW_INPUT is also enabled).
+	 * May become equivalent to TTY_MODE_RAW_VT in future libuv versions.
+	 */
+	TTY_MODE_RAW
+	/* Binary-safe I/O mode for IPC (Unix-only) */
+	TTY_MODE_IO
+	/* Raw input mode. On Windows ENABLE_VIRTUAL_TERMINAL_INPUT is also set. */
+	TTY_MODE_RAW_VT
+)
+
+const (
+	/*
+	 * The console supports handling of virtual terminal sequences
+	 * (Windows10 new console, ConEmu)
+	 */
+	TTY_SUPPORTED TtyVtermState = iota
+	/* The console cannot process the virtual terminal sequence. (Legacy console) */
+	TTY_UNSUPPORTED
+)
+
+type TtyMode c.Int
+
+type TtyVtermState c.Int
+
+// ----------------------------------------------
+
+/* Handle types. */
+
+// Tty is a uv_tty_t, a stream for the console. It embeds Stream, so reads
+// and writes go through the usual Stream methods.
+type Tty struct {
+	Stream
+	// uv_tty_t adds the saved termios state and the current mode to
+	// uv_stream_t.
+	Unused [72]byte
+}
+
+// ----------------------------------------------
+
+/* Tty related function and method */
+
+// int uv_tty_init(uv_loop_t* loop, uv_tty_t* handle, uv_file fd, int unused)
+//
+//go:linkname InitTty C.uv_tty_init
+func InitTty(loop *Loop, tty *Tty, fd File, unused c.Int) c.Int
+
+// int uv_tty_set_mode(uv_tty_t* handle, uv_tty_mode_t mode)
+//
+// llgo:link (*Tty).SetMode C.uv_tty_set_mode
+func (tty *Tty) SetMode(mode TtyMode) c.Int {
+	return 0
+}
+
+// int uv_tty_get_winsize(uv_tty_t* handle, int* width, int* height)
+//
+// llgo:link (*Tty).GetWinsize C.uv_tty_get_winsize
+func (tty *Tty) GetWinsize(width *c.Int, height *c.Int) c.Int {
+	return 0
+}
+
+// int uv_tty_reset_mode(void)
+//
+// TtyResetMode restores every TTY to the mode it had before SetMode was
+// first called. It is async-signal-safe, so it may be called from a signal
+// handler or at exit.
+//
+//go:linkname TtyResetMode C.uv_tty_reset_mode
+func TtyResetMode() c.Int
+
+// void uv_tty_set_vterm_state(uv_tty_vtermstate_t state)
+//
+//go:linkname TtySetVtermState C.uv_tty_set_vterm_state
+func TtySetVtermState(state TtyVtermState)
+
+// int uv_tty_get_vterm_state(uv_tty_vtermstate_t* state)
+//
+//go:linkname TtyGetVtermState C.uv_tty_get_vterm_state
+func TtyGetVtermState(state *TtyVtermState) c.Int
+
+// uv_handle_type uv_guess_handle(uv_file file)
+//
+// GuessHandle reports which kind of handle (TTY, NAMED_PIPE, TCP, UDP or
+// FILE) suits file.
+//
+//go:linkname GuessHandle C.uv_guess_handle
+func GuessHandle(file File) HandleType
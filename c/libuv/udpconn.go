@@ -0,0 +1,350 @@
+package libuv
+
+import (
+	"net"
+	"net/netip"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goplus/lib/c"
+)
+
+const (
+	defaultUdpQueueLen = 1024
+	// pooledDatagramSize is the capacity of pooled datagram buffers; larger
+	// datagrams are allocated individually.
+	pooledDatagramSize = 2048
+)
+
+// UdpConfig configures ListenUdp.
+type UdpConfig struct {
+	// Recvmmsg makes the socket read datagrams in batches with recvmmsg
+	// where the platform supports it.
+	Recvmmsg bool
+	// Batch is the number of datagrams per recvmmsg call; zero means
+	// MmsgBatch.
+	Batch int
+	// QueueLen is the number of received datagrams buffered for ReadFrom;
+	// zero means 1024. Datagrams arriving while the queue is full are
+	// dropped and counted by Dropped.
+	QueueLen int
+	// ReuseAddr sets UDP_REUSEADDR when binding.
+	ReuseAddr bool
+}
+
+type datagram struct {
+	buf  *[]byte
+	from netip.AddrPort
+}
+
+var datagramPool = sync.Pool{New: func() any {
+	b := make([]byte, pooledDatagramSize)
+	return &b
+}}
+
+// UdpConn is a net.PacketConn backed by a libuv Udp handle.
+type UdpConn struct {
+	l      *netLoop
+	udp    *Udp
+	laddr  netip.AddrPort
+	rdl    deadline
+	wdl    deadline
+	closed chan struct{}
+
+	queue   chan datagram
+	rerr    chan error // pending receive error, delivered once
+	dropped atomic.Uint64
+	mmsg    bool
+
+	closeOnce sync.Once
+}
+
+// ListenPacket announces on the local network address. network must be
+// "udp", "udp4" or "udp6". The returned connection is a *UdpConn served by
+// the same loop as Listen.
+func ListenPacket(network, address string) (net.PacketConn, error) {
+	addrs, err := resolveAddrs(network, address, true)
+	if err != nil {
+		return nil, &net.OpError{Op: "listen", Net: network, Err: err}
+	}
+	for _, ap := range addrs {
+		var conn *UdpConn
+		if conn, err = ListenUdp(network, ap, nil); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// ListenUdp binds a UDP socket to laddr. config may be nil for the
+// defaults.
+func ListenUdp(network string, laddr netip.AddrPort, config *UdpConfig) (*UdpConn, error) {
+	switch network {
+	case "udp", "udp4", "udp6":
+	default:
+		return nil, &net.OpError{Op: "listen", Net: network, Err: net.UnknownNetworkError(network)}
+	}
+	if config == nil {
+		config = &UdpConfig{}
+	}
+	queueLen := config.QueueLen
+	if queueLen <= 0 {
+		queueLen = defaultUdpQueueLen
+	}
+	conn := &UdpConn{
+		l:      getNetLoop(),
+		udp:    new(Udp),
+		closed: make(chan struct{}),
+		queue:  make(chan datagram, queueLen),
+		rerr:   make(chan error, 1),
+	}
+	var err error
+	conn.l.call(func() { err = conn.listen(laddr, config) })
+	if err != nil {
+		return nil, &net.OpError{Op: "listen", Net: network, Addr: net.UDPAddrFromAddrPort(laddr), Err: err}
+	}
+	return conn, nil
+}
+
+// listen runs on the loop thread.
+func (conn *UdpConn) listen(laddr netip.AddrPort, config *UdpConfig) error {
+	var flags c.Uint
+	if config.Recvmmsg {
+		flags |= c.Uint(UDP_RECVMMSG)
+	}
+	if err := toError(InitUdpEx(conn.l.loop, conn.udp, flags)); err != nil {
+		return err
+	}
+	handle := (*Handle)(c.Pointer(conn.udp))
+	var bindFlags UdpFlags
+	if config.ReuseAddr {
+		bindFlags |= UDP_REUSEADDR
+	}
+	err := conn.udp.Bind2(laddr, bindFlags)
+	if err == nil {
+		conn.laddr, err = conn.udp.LocalAddrPort()
+	}
+	if err == nil {
+		conn.mmsg = conn.udp.UsingRecvmmsg() != 0
+		if conn.mmsg {
+			batch := config.Batch
+			if batch == 0 {
+				batch = MmsgBatch
+			}
+			handle.SetAllocator(NewMmsgAllocator(batch, 4))
+		}
+		err = conn.udp.StartRecvFunc(conn.onRecv)
+	}
+	if err != nil {
+		handle.CloseFunc(nil)
+		return err
+	}
+	conn.rdl.init(conn.l, conn.closed)
+	conn.wdl.init(conn.l, conn.closed)
+	return nil
+}
+
+// onRecv runs on the loop thread. The datagram is copied out of the libuv
+// buffer, which is reused for the next batch. A receive error is returned
+// once, by the next read; libuv keeps receiving after it, and errors
+// arriving while one is pending are dropped.
+func (conn *UdpConn) onRecv(data []byte, from netip.AddrPort, flags UdpFlags, err error) {
+	if err != nil {
+		select {
+		case conn.rerr <- err:
+		default:
+		}
+		return
+	}
+	var buf *[]byte
+	if len(data) <= pooledDatagramSize {
+		buf = datagramPool.Get().(*[]byte)
+		*buf = (*buf)[:len(data)]
+	} else {
+		b := make([]byte, len(data))
+		buf = &b
+	}
+	copy(*buf, data)
+	select {
+	case conn.queue <- datagram{buf: buf, from: from}:
+	default:
+		conn.dropped.Add(1)
+		putDatagram(buf)
+	}
+}
+
+func putDatagram(buf *[]byte) {
+	if cap(*buf) == pooledDatagramSize {
+		*buf = (*buf)[:cap(*buf)]
+		datagramPool.Put(buf)
+	}
+}
+
+func (conn *UdpConn) opError(op string, addr net.Addr, err error) error {
+	return &net.OpError{Op: op, Net: "udp", Source: net.UDPAddrFromAddrPort(conn.laddr), Addr: addr, Err: err}
+}
+
+// ReadFromUDPAddrPort reads a datagram, copying it into b. If b is too small
+// the rest of the datagram is discarded.
+func (conn *UdpConn) ReadFromUDPAddrPort(b []byte) (int, netip.AddrPort, error) {
+	select {
+	case <-conn.closed:
+		return 0, netip.AddrPort{}, conn.opError("read", nil, net.ErrClosed)
+	case <-conn.rdl.wait():
+		return 0, netip.AddrPort{}, conn.opError("read", nil, os.ErrDeadlineExceeded)
+	default:
+	}
+	select {
+	case d := <-conn.queue:
+		n := copy(b, *d.buf)
+		putDatagram(d.buf)
+		return n, d.from, nil
+	default:
+	}
+	select {
+	case d := <-conn.queue:
+		n := copy(b, *d.buf)
+		putDatagram(d.buf)
+		return n, d.from, nil
+	case err := <-conn.rerr:
+		return 0, netip.AddrPort{}, conn.opError("read", nil, err)
+	case <-conn.closed:
+		return 0, netip.AddrPort{}, conn.opError("read", nil, net.ErrClosed)
+	case <-conn.rdl.wait():
+		return 0, netip.AddrPort{}, conn.opError("read", nil, os.ErrDeadlineExceeded)
+	}
+}
+
+// ReadFrom implements net.PacketConn.
+func (conn *UdpConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	n, from, err := conn.ReadFromUDPAddrPort(b)
+	if err != nil {
+		return n, nil, err
+	}
+	return n, net.UDPAddrFromAddrPort(from), nil
+}
+
+// WriteToUDPAddrPort sends b as one datagram to addr.
+func (conn *UdpConn) WriteToUDPAddrPort(b []byte, addr netip.AddrPort) (int, error) {
+	select {
+	case <-conn.closed:
+		return 0, conn.opError("write", net.UDPAddrFromAddrPort(addr), net.ErrClosed)
+	case <-conn.wdl.wait():
+		return 0, conn.opError("write", net.UDPAddrFromAddrPort(addr), os.ErrDeadlineExceeded)
+	default:
+	}
+	if !addr.IsValid() {
+		return 0, conn.opError("write", nil, &net.AddrError{Err: "invalid address"})
+	}
+	done := make(chan error, 1)
+	var err error
+	conn.l.call(func() {
+		// Try to send right away; only queue a copy if the socket is busy.
+		var sa sockaddr
+		if err = sa.set(addr); err != nil {
+			return
+		}
+		buf := Buf{Len: uintptr(len(b))}
+		if len(b) > 0 {
+			buf.Base = (*c.Char)(c.Pointer(&b[0]))
+		}
+		ret := conn.udp.TrySend(&buf, 1, sa.ptr())
+		switch {
+		case ret >= 0:
+			done <- nil
+		case Errno(ret) == EAGAIN:
+			err = conn.udp.SendFunc(b, addr, func(err error) { done <- err })
+		default:
+			err = Errno(ret)
+		}
+	})
+	if err == nil {
+		select {
+		case err = <-done:
+		case <-conn.closed:
+			err = net.ErrClosed
+		case <-conn.wdl.wait():
+			err = os.ErrDeadlineExceeded
+		}
+	}
+	if err != nil {
+		return 0, conn.opError("write", net.UDPAddrFromAddrPort(addr), err)
+	}
+	return len(b), nil
+}
+
+// WriteTo implements net.PacketConn.
+func (conn *UdpConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	var ap netip.AddrPort
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		ap = a.AddrPort()
+	default:
+		var err error
+		if ap, err = netip.ParseAddrPort(addr.String()); err != nil {
+			return 0, conn.opError("write", addr, err)
+		}
+	}
+	return conn.WriteToUDPAddrPort(b, netip.AddrPortFrom(ap.Addr().Unmap(), ap.Port()))
+}
+
+// Close closes the connection. Blocked ReadFrom and WriteTo calls return
+// net.ErrClosed.
+func (conn *UdpConn) Close() error {
+	err := net.ErrClosed
+	conn.closeOnce.Do(func() {
+		err = nil
+		close(conn.closed)
+		conn.l.exec(func() {
+			(*Handle)(c.Pointer(conn.udp)).CloseFunc(nil)
+			(*Handle)(c.Pointer(conn.rdl.timer)).CloseFunc(nil)
+			(*Handle)(c.Pointer(conn.wdl.timer)).CloseFunc(nil)
+		})
+	})
+	if err != nil {
+		return conn.opError("close", nil, err)
+	}
+	return nil
+}
+
+// LocalAddrPort returns the local address.
+func (conn *UdpConn) LocalAddrPort() netip.AddrPort {
+	return conn.laddr
+}
+
+// LocalAddr returns the local network address.
+func (conn *UdpConn) LocalAddr() net.Addr {
+	return net.UDPAddrFromAddrPort(conn.laddr)
+}
+
+// UsingRecvmmsg reports whether datagrams are read in batches.
+func (conn *UdpConn) UsingRecvmmsg() bool {
+	return conn.mmsg
+}
+
+// Dropped returns the number of datagrams dropped because the receive queue
+// was full.
+func (conn *UdpConn) Dropped() uint64 {
+	return conn.dropped.Load()
+}
+
+// SetDeadline sets the read and write deadlines.
+func (conn *UdpConn) SetDeadline(t time.Time) error {
+	conn.rdl.set(t)
+	conn.wdl.set(t)
+	return nil
+}
+
+// SetReadDeadline sets the deadline for future and pending ReadFrom calls.
+func (conn *UdpConn) SetReadDeadline(t time.Time) error {
+	conn.rdl.set(t)
+	return nil
+}
+
+// SetWriteDeadline sets the deadline for future and pending WriteTo calls.
+func (conn *UdpConn) SetWriteDeadline(t time.Time) error {
+	conn.wdl.set(t)
+	return nil
+}
@@ -0,0 +1,133 @@
+package libuv
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/c/os"
+)
+
+// MaxThreadpoolSize is the largest thread pool libuv supports.
+const MaxThreadpoolSize = 1024
+
+// SetThreadpoolSize sets UV_THREADPOOL_SIZE, the number of threads that run
+// work queued with Go, QueueWork and the file system functions. The pool is
+// created the first time work is queued, so this must be called before that;
+// later calls have no effect. libuv's default is 4 threads.
+func SetThreadpoolSize(n int) error {
+	if n < 1 || n > MaxThreadpoolSize {
+		return EINVAL
+	}
+	if os.Setenv(c.Str("UV_THREADPOOL_SIZE"), c.AllocaCStr(strconv.Itoa(n)), 1) != 0 {
+		return ENOMEM
+	}
+	return nil
+}
+
+// workTask is implemented by every Task[T]; the work callbacks find it by
+// its request in liveReqs.
+type workTask interface {
+	run()
+	finish(status c.Int)
+}
+
+// Task is a function running on the libuv thread pool, started with Go.
+type Task[T any] struct {
+	req  Work
+	fn   func() (T, error)
+	then func(T, error)
+
+	value T
+	err   error
+	done  chan struct{}
+}
+
+// Go runs fn on the loop's thread pool. The result is delivered on the loop
+// thread to the function registered with Then, and can also be waited for
+// from other goroutines with Done and Result. Like every libuv call, Go must
+// be called on the thread running loop.
+func Go[T any](loop *Loop, fn func() (T, error)) *Task[T] {
+	t := &Task[T]{fn: fn, done: make(chan struct{})}
+	keepReq(c.Pointer(&t.req), workTask(t))
+	if ret := QueueWork(loop, &t.req, onWork, onAfterWork); ret < 0 {
+		releaseReq(c.Pointer(&t.req))
+		t.err = Errno(ret)
+		close(t.done)
+	}
+	return t
+}
+
+func onWork(req *Work) {
+	lookupWorkTask(req).run()
+}
+
+func onAfterWork(req *Work, status c.Int) {
+	t := lookupWorkTask(req)
+	releaseReq(c.Pointer(req))
+	t.finish(status)
+}
+
+func lookupWorkTask(req *Work) workTask {
+	liveMu.Lock()
+	defer liveMu.Unlock()
+	return liveReqs[c.Pointer(req)].(workTask)
+}
+
+// run executes fn on a thread pool thread. A panic in fn is reported as the
+// task's error, as there is no Go caller on the pool thread to receive it.
+func (t *Task[T]) run() {
+	defer func() {
+		if r := recover(); r != nil {
+			t.err = fmt.Errorf("panic in task: %v", r)
+		}
+	}()
+	t.value, t.err = t.fn()
+}
+
+// finish runs on the loop thread once fn has returned or was cancelled.
+func (t *Task[T]) finish(status c.Int) {
+	if status < 0 {
+		t.err = Errno(status)
+	}
+	close(t.done)
+	if t.then != nil {
+		t.then(t.value, t.err)
+	}
+}
+
+// Then registers fn to receive the result on the loop thread. It must be
+// called on the loop thread; if the task has already finished, fn is called
+// right away.
+func (t *Task[T]) Then(fn func(value T, err error)) {
+	select {
+	case <-t.done:
+		fn(t.value, t.err)
+	default:
+		t.then = fn
+	}
+}
+
+// Cancel stops the task if it has not started running yet, in which case its
+// result is ECANCELED. It returns EBUSY if the task is already running or
+// has finished. Cancel must be called on the loop thread.
+func (t *Task[T]) Cancel() error {
+	select {
+	case <-t.done:
+		return EBUSY
+	default:
+	}
+	return toError((*Req)(c.Pointer(&t.req)).Cancel())
+}
+
+// Done returns a channel that is closed once the task has finished.
+func (t *Task[T]) Done() <-chan struct{} {
+	return t.done
+}
+
+// Result waits for the task to finish and returns its result. It must not
+// be called on the loop thread, which delivers the result.
+func (t *Task[T]) Result() (T, error) {
+	<-t.done
+	return t.value, t.err
+}
@@ -39,7 +39,7 @@ func main() {
 		if status == lua.YIELD {
 			c.Printf(c.Str("Resuming coroutine %d...\n"), status)
 			yieldValue := co.Tointeger(-1)
-			c.Printf(c.Str("Yield value: %d\n"), yieldValue)
+			c.Printf(c.Str("Yield value: %lld\n"), yieldValue)
 			co.Pop(1) // Clean up the stack
 
 			// Check if the coroutine is yieldable
@@ -11,7 +11,7 @@ func countdownContinue(L *lua.State, status c.Int, ctx lua.KContext) c.Int {
 
 func countdown(L *lua.State) c.Int {
 	arg := L.Tointeger(lua.Upvalueindex(1))
-	c.Printf(c.Str("resume called with %d\n"), arg)
+	c.Printf(c.Str("resume called with %lld\n"), arg)
 	if arg > 0 {
 		L.Pushinteger(arg - 1)
 		L.Replace(lua.Upvalueindex(1))
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing/fstest"
+
+	"github.com/goplus/lib/c/lua"
+	"github.com/goplus/lib/c/lua/dbg"
+)
+
+const fib = `local function fib(n)
+	if n < 2 then
+		return n
+	end
+	return fib(n - 1) + fib(n - 2)
+end
+
+local total = 0
+for i = 1, 10 do
+	total = total + fib(i)
+end
+return total
+`
+
+var loader = lua.NewFSLoader(fstest.MapFS{"fib.lua": {Data: []byte(fib)}}, "")
+
+func run(vm *lua.VM) {
+	if err := loader.Load(vm.State, "fib.lua"); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if vm.Pcall(0, 1, 0) != lua.OK {
+		fmt.Println(vm.Tostring(-1))
+	} else {
+		fmt.Println("result:", vm.Tointegerx(-1, nil))
+	}
+	vm.Pop(1)
+}
+
+func main() {
+	vm := lua.NewVM()
+	defer vm.Close()
+
+	// Profile and measure coverage at the same time.
+	prof := dbg.NewProfiler(dbg.Sampling, 100)
+	cov := dbg.NewCoverage()
+	prof.Start(vm.State)
+	cov.Start(vm.State)
+	run(vm)
+	cov.Stop(vm.State)
+	prof.Stop(vm.State)
+
+	if f, err := os.Create("fib.pprof"); err == nil {
+		prof.WriteProfile(f)
+		f.Close()
+		fmt.Println("wrote fib.pprof")
+	}
+	lines := cov.Lines("fib.lua")
+	fmt.Println("line hits:", lines[2], lines[3], lines[5], lines[10])
+
+	// Drive the debugger with a scripted session.
+	session := strings.NewReader(`break fib.lua:3
+continue
+bt
+locals
+print total 1
+finish
+delete fib.lua:3
+continue
+`)
+	d := dbg.NewDebugger(session, os.Stdout)
+	d.Attach(vm.State, true)
+	run(vm)
+	d.Detach(vm.State)
+}
+
+/* Expected output:
+result: 143
+wrote fib.pprof
+line hits: 452 231 221 10
+stopped fib.lua:1 entry
+ok
+ok
+stopped fib.lua:3 breakpoint
+#0 fib.lua:3: in fib
+#1 fib.lua:10: in main chunk
+ok
+n = 1
+ok
+total = 0
+ok
+ok
+stopped fib.lua:9 step
+ok
+ok
+result: 143
+*/
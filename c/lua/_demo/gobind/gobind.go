@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/c/lua"
+)
+
+type Point struct {
+	X, Y int
+}
+
+type Shape struct {
+	Name   string `lua:"name"`
+	Points []Point
+	Tags   map[string]bool `lua:"tags"`
+}
+
+func (s *Shape) Add(x, y int) int {
+	s.Points = append(s.Points, Point{x, y})
+	return len(s.Points)
+}
+
+func divmod(a, b int) (int, int, error) {
+	if b == 0 {
+		return 0, 0, errors.New("division by zero")
+	}
+	return a / b, a % b, nil
+}
+
+func run(L *lua.State, code string) {
+	if L.Dostring(c.AllocaCStr(code)) != lua.OK {
+		c.Printf(c.Str("error: %s\n"), L.Tostring(-1))
+		L.Pop(1)
+	}
+}
+
+func main() {
+	L := lua.Newstate__1()
+	defer L.Close()
+	L.Openlibs()
+
+	// Go funcs become Lua functions with checked arguments.
+	L.PushGo(divmod)
+	L.Setglobal(c.Str("divmod"))
+	L.PushGo(strings.Repeat)
+	L.Setglobal(c.Str("rep"))
+	run(L, `print(divmod(17, 5))`)
+	run(L, `print(rep("ab", 3))`)
+	run(L, `print(pcall(divmod, 1, 0))`)
+	run(L, `print(pcall(divmod, "x", 1))`)
+
+	// Struct values are copied into tables, pointers are shared as userdata.
+	shape := &Shape{Name: "tri", Tags: map[string]bool{"closed": true}}
+	L.PushGo(*shape)
+	L.Setglobal(c.Str("copy"))
+	L.PushGo(shape)
+	L.Setglobal(c.Str("shape"))
+	run(L, `
+print(copy.name, copy.tags.closed, #copy.Points)
+shape:Add(0, 0); shape:Add(4, 0); shape:Add(0, 3)
+shape.name = "triangle"
+print(shape.name, #shape.Points, shape.Points[2].X)
+`)
+	fmt.Println(shape.Name, shape.Points)
+
+	// Lua values convert back to Go.
+	run(L, `return {name = "square", Points = {{X = 1, Y = 1}, {X = 2, Y = 2}}, tags = {filled = true}}`)
+	var sq Shape
+	if err := L.ToGo(-1, &sq); err != nil {
+		fmt.Println(err)
+	}
+	L.Pop(1)
+	fmt.Printf("%+v\n", sq)
+
+	run(L, `return function(a, b) return a .. "-" .. b, #a + #b end`)
+	var join func(a, b string) (string, int)
+	if err := L.ToGo(-1, &join); err != nil {
+		fmt.Println(err)
+	}
+	L.Pop(1)
+	fmt.Println(join("foo", "quux"))
+
+	run(L, `return {1, "two", 3.5, {k = false}}`)
+	var v any
+	L.ToGo(-1, &v)
+	L.Pop(1)
+	fmt.Printf("%#v\n", v)
+
+	var n int8
+	run(L, `return 1000`)
+	fmt.Println(L.ToGo(-1, &n))
+	L.Pop(1)
+}
+
+/* Expected output:
+3	2
+ababab
+false	division by zero
+false	bad argument #1 (lua: cannot convert string to int)
+tri	true	0
+triangle	3	4
+triangle [{0 0} {4 0} {0 3}]
+{Name:square Points:[{X:1 Y:1} {X:2 Y:2}] Tags:map[filled:true]}
+foo-quux 7
+[]interface {}{1, "two", 3.5, map[string]interface {}{"k":false}}
+lua: cannot convert number to int8
+*/
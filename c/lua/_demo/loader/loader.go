@@ -0,0 +1,63 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"math"
+
+	"github.com/goplus/lib/c/lua"
+)
+
+//go:embed lua
+var scripts embed.FS
+
+func init() {
+	lua.RegisterGoModule("mathx", map[string]any{
+		"hypot": math.Hypot,
+		"pi":    math.Pi,
+	})
+}
+
+func main() {
+	sub, _ := fs.Sub(scripts, "lua")
+	loader := lua.NewFSLoader(sub, "")
+
+	// The loader may be shared: the second state loads cached chunks.
+	for i := 0; i < 2; i++ {
+		vm := lua.NewVM()
+		vm.AddGoSearcher()
+		loader.AddSearcher(vm.State)
+
+		res, err := vm.DoString(`
+local greet = require "greet"
+local geo = require "geo"
+return greet.hello("embed"), geo.dist(3, 4), require("mathx").pi
+`)
+		fmt.Println(res, err)
+		vm.Close()
+	}
+
+	vm := lua.NewVM()
+	defer vm.Close()
+	vm.AddGoSearcher()
+	loader.AddSearcher(vm.State)
+	_, err := vm.DoString(`require "broken"`)
+	fmt.Println(err)
+	_, err = vm.DoString(`require "missing"`)
+	fmt.Println(err)
+}
+
+/* Expected output:
+[hello, embed 5 3.141592653589793] <nil>
+[hello, embed 5 3.141592653589793] <nil>
+error loading module 'broken' from file 'broken.lua':
+	broken.lua:2: '}' expected near <eof>
+module 'missing' not found:
+	no field package.preload['missing']
+	no file 'missing.lua'
+	no file 'missing/init.lua'
+	no file 'missing.luac'
+	no Go module 'missing'
+	...
+*/
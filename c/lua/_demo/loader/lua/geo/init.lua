@@ -0,0 +1,5 @@
+local mathx = require "mathx"
+
+return {
+	dist = function(x, y) return mathx.hypot(x, y) end,
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/c/lua"
+)
+
+// A tiny "counter" module implemented with lauxlib: counters are userdata
+// with a metatable, and their methods check the userdata type.
+
+var counterMeta = c.Str("demo.Counter")
+
+type counter struct {
+	value lua.Integer
+	step  lua.Integer
+}
+
+func newCounter(L *lua.State) c.Int {
+	step := L.Optinteger(1, 1)
+	cnt := (*counter)(L.Newuserdatauv(unsafe.Sizeof(counter{}), 0))
+	cnt.value, cnt.step = 0, step
+	L.LSetmetatable(counterMeta)
+	return 1
+}
+
+func checkCounter(L *lua.State) *counter {
+	return (*counter)(L.Checkudata(1, counterMeta))
+}
+
+func counterIncr(L *lua.State) c.Int {
+	cnt := checkCounter(L)
+	cnt.value += cnt.step
+	L.Pushinteger(cnt.value)
+	return 1
+}
+
+var modes = [...]*c.Char{c.Str("short"), c.Str("long"), nil}
+
+func counterFormat(L *lua.State) c.Int {
+	cnt := checkCounter(L)
+	var b lua.Buffer
+	L.Buffinit(&b)
+	b.Addstring(c.Str("counter("))
+	switch L.Checkoption(2, c.Str("short"), &modes[0]) {
+	case 0:
+		L.Pushfstring(c.Str("%d"), c.Int(cnt.value))
+	case 1:
+		L.Pushfstring(c.Str("value=%d, step=%d"), c.Int(cnt.value), c.Int(cnt.step))
+	}
+	b.Addvalue()
+	b.Addchar(')')
+	b.Pushresult()
+	return 1
+}
+
+var counterMethods = []lua.Reg{
+	{Name: c.Str("incr"), Func: counterIncr},
+	{Name: c.Str("format"), Func: counterFormat},
+	{Name: c.Str("__tostring"), Func: counterFormat},
+	{},
+}
+
+var counterFuncs = []lua.Reg{
+	{Name: c.Str("new"), Func: newCounter},
+	{},
+}
+
+func openCounter(L *lua.State) c.Int {
+	if L.Newmetatable(counterMeta) != 0 {
+		L.Setfuncs(&counterMethods[0], 0)
+		L.Pushvalue(-1)
+		L.Setfield(-2, c.Str("__index"))
+	}
+	L.Pop(1)
+	L.Newlib(counterFuncs)
+	return 1
+}
+
+func main() {
+	L := lua.Newstate__1()
+	defer L.Close()
+	L.Openlibs()
+
+	// Make the module available as the global "counter".
+	L.Requiref(c.Str("counter"), openCounter, 1)
+	L.Pop(1)
+
+	code := `
+local c = counter.new(3)
+c:incr(); c:incr()
+print(tostring(c), c:format("long"), #"abc")
+return function() error("boom") end
+`
+	if L.Loadbuffer(c.AllocaCStr(code), uintptr(len(code)), c.Str("=demo")) != lua.OK || L.Pcall(0, 1, 0) != lua.OK {
+		c.Printf(c.Str("error: %s\n"), L.Tostring(-1))
+		return
+	}
+
+	// Keep the returned function in the registry and call it later, with a
+	// message handler that adds a traceback to errors.
+	ref := L.Ref(lua.REGISTRYINDEX)
+	L.Pushcfunction(msgh)
+	L.Rawgeti(lua.REGISTRYINDEX, lua.Integer(ref))
+	if L.Pcall(0, 0, -2) != lua.OK {
+		c.Printf(c.Str("%s\n"), L.Tostring(-1))
+		L.Pop(1)
+	}
+	L.Pop(1)
+	L.Unref(lua.REGISTRYINDEX, ref)
+
+	// Calling a method on the wrong type is reported by Checkudata.
+	L.Dostring(c.Str(`counter.new().incr({})`))
+	c.Printf(c.Str("%s\n"), L.Tostring(-1))
+}
+
+func msgh(L *lua.State) c.Int {
+	L.Traceback(L, L.LTolstring(1, nil), 1)
+	return 1
+}
+
+/* Expected output:
+counter(6)	counter(value=6, step=3)	3
+demo:5: boom
+stack traceback:
+	[C]: in function 'error'
+	demo:5: in function <demo:5>
+[string "counter.new().incr({})"]:1: bad argument #1 to 'incr' (demo.Counter expected, got table)
+*/
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goplus/lib/c/lua"
+)
+
+func run(s *lua.Sandbox, code string) {
+	res := s.Run(code)
+	if res.Err != nil {
+		fmt.Printf("%s: %v\n", res.Limit, res.Err)
+		return
+	}
+	fmt.Println(res.Values)
+}
+
+func main() {
+	s, err := lua.NewSandbox(&lua.SandboxOptions{
+		MaxInstructions: 1_000_000,
+		MaxMemory:       1 << 20,
+		Timeout:         100 * time.Millisecond,
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer s.Close()
+
+	run(s, `return string.format("%d items", #{1, 2, 3})`)
+	run(s, `return io, os, load, dofile`)
+
+	// Limits stop the code even when it tries to catch the error.
+	run(s, `while true do pcall(function() for i = 1, 100 do end end) end`)
+	run(s, `local t = {} for i = 1, 1e7 do t[i] = i end`)
+	run(s, `return string.rep("x", 1 << 24)`)
+
+	// The state stays usable after a limit was reached.
+	run(s, `return 6 * 7`)
+
+	t, _ := lua.NewSandbox(&lua.SandboxOptions{Timeout: 50 * time.Millisecond})
+	defer t.Close()
+	run(t, `while true do end`)
+}
+
+/* Expected output:
+[3 items]
+[<nil> <nil> <nil> <nil>]
+instruction limit: instruction limit exceeded
+memory limit: not enough memory
+memory limit: not enough memory
+[42]
+time limit: time limit exceeded
+*/
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/goplus/lib/c/libuv"
+	"github.com/goplus/lib/c/lua"
+	"github.com/goplus/lib/c/lua/sched"
+	xio "github.com/goplus/lib/x/io"
+)
+
+func main() {
+	// A Go echo server for the Lua client below.
+	ln, err := libuv.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	vm := lua.NewVM()
+	defer vm.Close()
+	s := sched.New(vm, nil)
+	defer s.Close()
+	s.OpenLib()
+
+	// An async function backed by an x/io AsyncCall.
+	s.Register("upper", sched.Await(func(args []any) (xio.AsyncCall[string], error) {
+		str, _ := args[0].(string)
+		return &xio.PromiseImpl[string]{Func: func(resolve func(string, error)) {
+			go resolve(strings.ToUpper(str), nil)
+		}}, nil
+	}))
+	vm.SetGlobal("addr", ln.Addr().String())
+
+	vm.DoString(`
+function worker(name, ms)
+	for i = 1, 3 do
+		async.sleep(ms)
+		print(name, i)
+	end
+	return name .. " done"
+end
+`)
+	t1 := s.SpawnFunc("worker", "fast", 10)
+	t2 := s.SpawnFunc("worker", "slow", 25)
+	t3, _ := s.Spawn(`
+local conn = assert(async.connect("tcp", addr))
+async.write(conn, upper("hello, loop"))
+local reply = async.read(conn)
+async.close(conn)
+return reply
+`)
+	t4, _ := s.Spawn(`return async.read("not a conn")`)
+	s.Run()
+
+	for _, t := range []*sched.Task{t1, t2, t3, t4} {
+		fmt.Println(t.Result())
+	}
+}
+
+/* Expected output:
+fast	1
+fast	2
+slow	1
+fast	3
+slow	2
+slow	3
+[fast done] <nil>
+[slow done] <nil>
+[HELLO, LOOP] <nil>
+[<nil> bad argument #1 (connection expected)] <nil>
+*/
@@ -52,7 +52,7 @@ func main() {
 
 	// set index field
 	L.Pushinteger(123)
-	L.Seti(-2, c.Int(1))
+	L.Seti(-2, 1)
 
 	// set pointer key field
 	pointerKey := c.AllocaCStr("pointer key")
@@ -78,8 +78,8 @@ func main() {
 	L.Pop(1)
 
 	// get index field
-	L.Geti(-1, c.Int(1))
-	c.Printf(c.Str("Index[%d] value: %d\n"), 1, L.Tointeger(-1))
+	L.Geti(-1, 1)
+	c.Printf(c.Str("Index[%d] value: %lld\n"), 1, L.Tointeger(-1))
 	L.Pop(1)
 
 	c.Printf(c.Str("All entries in the table:\n"))
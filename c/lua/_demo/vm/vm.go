@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/goplus/lib/c/lua"
+)
+
+func main() {
+	vm := lua.NewVM()
+	defer vm.Close()
+
+	res, err := vm.DoString(`
+function greet(name, n)
+	return "hello " .. name, n * 2
+end
+return 1, "two", {3}
+`)
+	fmt.Println(res, err)
+
+	res, err = vm.Call("greet", "lua", 21)
+	fmt.Println(res, err)
+
+	res, err = vm.Call("string.format", "%s=%d", "x", 42)
+	fmt.Println(res, err)
+
+	// Syntax errors carry their status and no traceback.
+	_, err = vm.DoString(`function doubleNumber(x) ! return x * 2 end`)
+	var e *lua.Error
+	if errors.As(err, &e) {
+		fmt.Println(lua.StatusString(e.Status), e.Message)
+	}
+
+	// Runtime errors carry a traceback.
+	vm.DoString(`
+function inner() error("boom") end
+function outer() inner() end
+`)
+	_, err = vm.Call("outer")
+	if errors.As(err, &e) {
+		fmt.Println(lua.StatusString(e.Status), e.Message)
+		fmt.Println(e.Traceback)
+	}
+
+	// Error objects need not be strings.
+	_, err = vm.DoString(`error({code = 42})`)
+	if errors.As(err, &e) {
+		fmt.Println(e.Value)
+	}
+
+	// A Go panic in a Go func becomes a Lua error that Lua can catch...
+	vm.SetGlobal("explode", func() { panic("kaboom") })
+	res, err = vm.DoString(`return pcall(explode)`)
+	fmt.Println(res, err)
+
+	// ...and is raised again in Go if Lua does not catch it.
+	defer func() {
+		fmt.Println("recovered:", recover())
+	}()
+	vm.DoString(`explode()`)
+}
+
+/* Expected output:
+[1 two [3]] <nil>
+[hello lua 42] <nil>
+[x=42] <nil>
+ERRSYNTAX [string "function doubleNumber(x) ! return x * 2 end"]:1: unexpected symbol near '!'
+ERRRUN [string "..."]:2: boom
+stack traceback:
+	[C]: in function 'error'
+	[string "..."]:2: in function 'inner'
+	[string "..."]:3: in function 'outer'
+map[code:42]
+[false kaboom] <nil>
+recovered: kaboom
+*/
@@ -0,0 +1,159 @@
+package dbg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/c/lua"
+)
+
+// Coverage records which lines and functions of Lua code run, and how
+// often, and writes the result as an lcov tracefile.
+//
+// Lines of a function are known once the function has been called: the
+// lines of functions that never ran are missing from the report rather
+// than reported with a count of 0.
+type Coverage struct {
+	mu    sync.Mutex
+	files map[string]*fileCoverage
+}
+
+type fileCoverage struct {
+	lines map[int]int64
+	funcs map[int]*funcCoverage // by line defined
+}
+
+type funcCoverage struct {
+	name  string
+	calls int64
+}
+
+// NewCoverage returns an empty Coverage.
+func NewCoverage() *Coverage {
+	return &Coverage{files: make(map[string]*fileCoverage)}
+}
+
+// Start starts collecting coverage of the code run by L's state.
+func (cv *Coverage) Start(L *lua.State) {
+	attach(L, cv)
+}
+
+// Stop stops collecting coverage of L's state.
+func (cv *Coverage) Stop(L *lua.State) {
+	detach(L, cv)
+}
+
+func (cv *Coverage) hookMask() (c.Int, c.Int) {
+	return lua.MASKLINE | lua.MASKCALL, 0
+}
+
+func (cv *Coverage) file(name string) *fileCoverage {
+	f := cv.files[name]
+	if f == nil {
+		f = &fileCoverage{lines: make(map[int]int64), funcs: make(map[int]*funcCoverage)}
+		cv.files[name] = f
+	}
+	return f
+}
+
+func (cv *Coverage) hook(L *lua.State, ar *lua.Debug) {
+	if ar.Event == lua.HOOKLINE {
+		L.Getinfo(c.Str("S"), ar)
+		src := sourceName(ar)
+		cv.mu.Lock()
+		cv.file(src).lines[int(ar.Currentline)]++
+		cv.mu.Unlock()
+		return
+	}
+
+	// A call: count it, and record the lines of the function so that those
+	// that never run are reported.
+	L.Getinfo(c.Str("SnL"), ar)
+	fr := getFrame(ar)
+	if fr.what == "C" {
+		L.Pop(1)
+		return
+	}
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+	f := cv.file(fr.source)
+	fn := f.funcs[fr.lineDefined]
+	if fn == nil {
+		fn = &funcCoverage{name: fr.funcName()}
+		f.funcs[fr.lineDefined] = fn
+		if L.Type(-1) == lua.TABLE {
+			L.Pushnil()
+			for L.Next(-2) != 0 {
+				line := int(L.Tointegerx(-2, nil))
+				if _, ok := f.lines[line]; !ok {
+					f.lines[line] = 0
+				}
+				L.Pop(1)
+			}
+		}
+	}
+	L.Pop(1)
+	fn.calls++
+}
+
+// Lines returns the execution count of each line of the file named source
+// seen so far.
+func (cv *Coverage) Lines(source string) map[int]int64 {
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+	lines := make(map[int]int64)
+	if f := cv.files[source]; f != nil {
+		for line, n := range f.lines {
+			lines[line] = n
+		}
+	}
+	return lines
+}
+
+// WriteLcov writes the coverage recorded so far as an lcov tracefile.
+func (cv *Coverage) WriteLcov(w io.Writer) error {
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+	bw := bufio.NewWriter(w)
+	for _, name := range sortedKeys(cv.files) {
+		f := cv.files[name]
+		fmt.Fprintf(bw, "SF:%s\n", name)
+		starts := sortedKeys(f.funcs)
+		hit := 0
+		for _, line := range starts {
+			fmt.Fprintf(bw, "FN:%d,%s\n", line, f.funcs[line].name)
+		}
+		for _, line := range starts {
+			fn := f.funcs[line]
+			fmt.Fprintf(bw, "FNDA:%d,%s\n", fn.calls, fn.name)
+			if fn.calls > 0 {
+				hit++
+			}
+		}
+		fmt.Fprintf(bw, "FNF:%d\nFNH:%d\n", len(starts), hit)
+		hit = 0
+		lines := sortedKeys(f.lines)
+		for _, line := range lines {
+			n := f.lines[line]
+			fmt.Fprintf(bw, "DA:%d,%d\n", line, n)
+			if n > 0 {
+				hit++
+			}
+		}
+		fmt.Fprintf(bw, "LF:%d\nLH:%d\nend_of_record\n", len(lines), hit)
+	}
+	return bw.Flush()
+}
+
+func sortedKeys[K int | string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
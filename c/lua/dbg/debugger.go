@@ -0,0 +1,407 @@
+package dbg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/c/lua"
+)
+
+// Debugger stops Lua code at breakpoints and lets a front end step through
+// it and inspect variables, with a line protocol: when the code stops, the
+// Debugger writes
+//
+//	stopped <file>:<line> <reason>
+//
+// where reason is "entry", "breakpoint" or "step", then reads commands, one
+// per line, until one of them resumes the code. The output of each command
+// ends with a line "ok", or "error: <message>". The commands are:
+//
+//	break <file>:<line>    set a breakpoint (b)
+//	delete <file>:<line>   delete a breakpoint (d)
+//	breakpoints            list the breakpoints
+//	continue               resume until a breakpoint (c)
+//	step                   resume until the next line (s)
+//	next                   resume until the next line of the current function (n)
+//	finish                 resume until the current function returns (f)
+//	backtrace              print the call stack (bt)
+//	locals [level]         print the local variables of a frame (l)
+//	upvalues [level]       print the upvalues of the function of a frame (u)
+//	print <name> [level]   print a variable, looked up as in Lua (p)
+//	help                   list the commands (h)
+//
+// Frames are numbered from 0, the innermost. Files are matched by their
+// name or the end of their path. At end of input the Debugger deletes the
+// breakpoints and lets the code run.
+type Debugger struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	mu     sync.Mutex
+	breaks map[string]map[int]bool
+	mode   stepMode
+	target int // depth for next and finish
+	entry  bool
+}
+
+type stepMode int
+
+const (
+	modeRun stepMode = iota
+	modeStep
+	modeNext
+	modeFinish
+)
+
+// NewDebugger returns a Debugger reading commands from in and writing to
+// out.
+func NewDebugger(in io.Reader, out io.Writer) *Debugger {
+	return &Debugger{
+		in:     bufio.NewReader(in),
+		out:    out,
+		breaks: make(map[string]map[int]bool),
+	}
+}
+
+// Attach starts debugging L's state. If stopOnEntry is set, the code stops
+// at the first line it runs.
+func (d *Debugger) Attach(L *lua.State, stopOnEntry bool) {
+	d.mu.Lock()
+	if stopOnEntry {
+		d.mode, d.entry = modeStep, true
+	}
+	d.mu.Unlock()
+	attach(L, d)
+}
+
+// Detach stops debugging L's state.
+func (d *Debugger) Detach(L *lua.State) {
+	detach(L, d)
+}
+
+// SetBreakpoint sets a breakpoint at line of file.
+func (d *Debugger) SetBreakpoint(file string, line int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	lines := d.breaks[file]
+	if lines == nil {
+		lines = make(map[int]bool)
+		d.breaks[file] = lines
+	}
+	lines[line] = true
+}
+
+// ClearBreakpoint deletes the breakpoint at line of file, and reports
+// whether there was one.
+func (d *Debugger) ClearBreakpoint(file string, line int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.breaks[file][line] {
+		return false
+	}
+	delete(d.breaks[file], line)
+	if len(d.breaks[file]) == 0 {
+		delete(d.breaks, file)
+	}
+	return true
+}
+
+func (d *Debugger) hookMask() (c.Int, c.Int) {
+	return lua.MASKLINE, 0
+}
+
+func (d *Debugger) hook(L *lua.State, ar *lua.Debug) {
+	L.Getinfo(c.Str("S"), ar)
+	file, line := sourceName(ar), int(ar.Currentline)
+	depth := -1
+	reason := ""
+	d.mu.Lock()
+	switch {
+	case d.hasBreakpoint(file, line):
+		reason = "breakpoint"
+	case d.entry:
+		reason = "entry"
+	case d.mode == modeStep:
+		reason = "step"
+	case d.mode == modeNext, d.mode == modeFinish:
+		depth = stackDepth(L)
+		if d.mode == modeNext && depth <= d.target || d.mode == modeFinish && depth < d.target {
+			reason = "step"
+		}
+	}
+	if reason != "" {
+		d.entry = false
+	}
+	d.mu.Unlock()
+	if reason == "" {
+		return
+	}
+	if depth < 0 {
+		depth = stackDepth(L)
+	}
+	fmt.Fprintf(d.out, "stopped %s:%d %s\n", file, line, reason)
+	d.commands(L, depth)
+}
+
+func (d *Debugger) hasBreakpoint(file string, line int) bool {
+	for f, lines := range d.breaks {
+		if lines[line] && (file == f || strings.HasSuffix(file, "/"+f)) {
+			return true
+		}
+	}
+	return false
+}
+
+// stackDepth returns the number of active frames of L. It is computed from
+// the stack at each stop rather than counted from call and return events,
+// which errors, yields and coroutines would make drift.
+func stackDepth(L *lua.State) int {
+	var ar lua.Debug
+	n := 0
+	for L.Getstack(c.Int(n), &ar) != 0 {
+		n++
+	}
+	return n
+}
+
+// commands runs commands until one resumes the code stopped at depth.
+func (d *Debugger) commands(L *lua.State, depth int) {
+	for {
+		line, err := d.in.ReadString('\n')
+		if err != nil && line == "" {
+			d.mu.Lock()
+			d.breaks = make(map[string]map[int]bool)
+			d.mode = modeRun
+			d.mu.Unlock()
+			return
+		}
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			continue
+		}
+		resume, err := d.command(L, depth, args)
+		if err != nil {
+			fmt.Fprintf(d.out, "error: %v\n", err)
+			continue
+		}
+		fmt.Fprintln(d.out, "ok")
+		if resume {
+			return
+		}
+	}
+}
+
+func (d *Debugger) command(L *lua.State, depth int, args []string) (resume bool, err error) {
+	switch args[0] {
+	case "break", "b", "delete", "d":
+		if len(args) != 2 {
+			return false, fmt.Errorf("usage: %s <file>:<line>", args[0])
+		}
+		file, line, err := parseLocation(args[1])
+		if err != nil {
+			return false, err
+		}
+		if args[0][0] == 'b' {
+			d.SetBreakpoint(file, line)
+		} else if !d.ClearBreakpoint(file, line) {
+			return false, fmt.Errorf("no breakpoint at %s", args[1])
+		}
+	case "breakpoints":
+		d.mu.Lock()
+		for _, file := range sortedKeys(d.breaks) {
+			for _, line := range sortedKeys(d.breaks[file]) {
+				fmt.Fprintf(d.out, "%s:%d\n", file, line)
+			}
+		}
+		d.mu.Unlock()
+	case "continue", "c":
+		d.resume(modeRun, depth)
+		return true, nil
+	case "step", "s":
+		d.resume(modeStep, depth)
+		return true, nil
+	case "next", "n":
+		d.resume(modeNext, depth)
+		return true, nil
+	case "finish", "f":
+		d.resume(modeFinish, depth)
+		return true, nil
+	case "backtrace", "bt":
+		for i, f := range stack(L, 1000) {
+			fmt.Fprintf(d.out, "#%d %s\n", i, f.String())
+		}
+	case "locals", "l":
+		level, err := parseLevel(args, 1)
+		if err != nil {
+			return false, err
+		}
+		return false, d.locals(L, level, "")
+	case "upvalues", "u":
+		level, err := parseLevel(args, 1)
+		if err != nil {
+			return false, err
+		}
+		return false, d.upvalues(L, level, "")
+	case "print", "p":
+		if len(args) < 2 {
+			return false, fmt.Errorf("usage: print <name> [level]")
+		}
+		level, err := parseLevel(args, 2)
+		if err != nil {
+			return false, err
+		}
+		return false, d.print(L, level, args[1])
+	case "help", "h":
+		fmt.Fprintln(d.out, "commands: break, delete, breakpoints, continue, step, next, finish, backtrace, locals, upvalues, print, help")
+	default:
+		return false, fmt.Errorf("unknown command %q", args[0])
+	}
+	return false, nil
+}
+
+func (d *Debugger) resume(mode stepMode, depth int) {
+	d.mu.Lock()
+	d.mode, d.target = mode, depth
+	d.mu.Unlock()
+}
+
+func parseLocation(s string) (file string, line int, err error) {
+	i := strings.LastIndexByte(s, ':')
+	if i < 0 {
+		return "", 0, fmt.Errorf("bad location %q", s)
+	}
+	line, err = strconv.Atoi(s[i+1:])
+	if err != nil {
+		return "", 0, fmt.Errorf("bad line in %q", s)
+	}
+	return s[:i], line, nil
+}
+
+func parseLevel(args []string, i int) (c.Int, error) {
+	if len(args) <= i {
+		return 0, nil
+	}
+	level, err := strconv.Atoi(args[i])
+	if err != nil || level < 0 {
+		return 0, fmt.Errorf("bad level %q", args[i])
+	}
+	return c.Int(level), nil
+}
+
+// locals prints the local variables of the frame at level, or only the one
+// called name if name is not empty. It fails if the variable is not found.
+func (d *Debugger) locals(L *lua.State, level c.Int, name string) error {
+	var ar lua.Debug
+	if L.Getstack(level, &ar) == 0 {
+		return fmt.Errorf("no frame at level %d", level)
+	}
+	vars := make(map[string]string)
+	var names []string
+	for i := c.Int(1); ; i++ {
+		p := L.Getlocal(&ar, i)
+		if p == nil {
+			break
+		}
+		n := c.GoString(p)
+		if !strings.HasPrefix(n, "(") {
+			if _, ok := vars[n]; !ok {
+				names = append(names, n)
+			}
+			// Later locals shadow earlier ones of the same name.
+			vars[n] = formatValue(L, -1)
+		}
+		L.Pop(1)
+	}
+	return d.printVars(names, vars, name)
+}
+
+// upvalues prints the upvalues of the function of the frame at level, like
+// locals.
+func (d *Debugger) upvalues(L *lua.State, level c.Int, name string) error {
+	var ar lua.Debug
+	if L.Getstack(level, &ar) == 0 {
+		return fmt.Errorf("no frame at level %d", level)
+	}
+	L.Getinfo(c.Str("f"), &ar)
+	vars := make(map[string]string)
+	var names []string
+	for i := c.Int(1); ; i++ {
+		p := L.Getupvalue(-1, i)
+		if p == nil {
+			break
+		}
+		n := c.GoString(p)
+		if n == "" {
+			n = fmt.Sprintf("?%d", i)
+		}
+		names = append(names, n)
+		vars[n] = formatValue(L, -1)
+		L.Pop(1)
+	}
+	L.Pop(1)
+	return d.printVars(names, vars, name)
+}
+
+func (d *Debugger) printVars(names []string, vars map[string]string, name string) error {
+	if name == "" {
+		for _, n := range names {
+			fmt.Fprintf(d.out, "%s = %s\n", n, vars[n])
+		}
+		return nil
+	}
+	v, ok := vars[name]
+	if !ok {
+		return errNotFound
+	}
+	fmt.Fprintf(d.out, "%s = %s\n", name, v)
+	return nil
+}
+
+var errNotFound = fmt.Errorf("not found")
+
+// print prints the variable name as seen from the frame at level: a local,
+// else an upvalue, else a global. Globals are read with a raw access, as a
+// __index metamethod on the globals table could raise errors inside the hook.
+func (d *Debugger) print(L *lua.State, level c.Int, name string) error {
+	if err := d.locals(L, level, name); err != errNotFound {
+		return err
+	}
+	if err := d.upvalues(L, level, name); err != errNotFound {
+		return err
+	}
+	L.Pushglobaltable()
+	L.Pushstring(c.AllocaCStr(name))
+	L.Rawget(-2)
+	fmt.Fprintf(d.out, "%s = %s\n", name, formatValue(L, -1))
+	L.Pop(2)
+	return nil
+}
+
+// formatValue formats the value at idx without calling metamethods, which
+// could raise errors inside the hook.
+func formatValue(L *lua.State, idx c.Int) string {
+	switch tp := L.Type(idx); tp {
+	case lua.NIL:
+		return "nil"
+	case lua.BOOLEAN:
+		return strconv.FormatBool(L.Toboolean(idx))
+	case lua.NUMBER, lua.STRING:
+		L.Pushvalue(idx)
+		var n c.Ulong
+		p := L.Tolstring(-1, &n)
+		s := string(unsafe.Slice((*byte)(unsafe.Pointer(p)), n))
+		L.Pop(1)
+		if tp == lua.STRING {
+			return strconv.Quote(s)
+		}
+		return s
+	default:
+		return fmt.Sprintf("%s: %p", c.GoString(L.Typename(tp)), L.Topointer(idx))
+	}
+}
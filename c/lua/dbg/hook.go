@@ -0,0 +1,203 @@
+// Package dbg provides tools to diagnose Lua code, built on the debug hook
+// API: a Profiler writing pprof profiles, a Coverage collector writing lcov
+// reports, and a breakpoint Debugger driven by a line protocol.
+//
+// Lua has a single hook per thread, so the tools attached to a state share
+// it. They are attached to the main thread and are inherited by the
+// coroutines created afterwards; coroutines that already exist are not
+// observed.
+package dbg
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/c/lua"
+)
+
+// tool is a consumer of hook events.
+type tool interface {
+	// hookMask returns the events the tool needs, and the instruction count
+	// for MASKCOUNT.
+	hookMask() (mask c.Int, count c.Int)
+	// hook handles an event. It must not raise Lua errors.
+	hook(L *lua.State, ar *lua.Debug)
+}
+
+type session struct {
+	tools []tool
+}
+
+var sessions = struct {
+	sync.Mutex
+	m map[*lua.State]*session
+}{m: make(map[*lua.State]*session)}
+
+func mainThread(L *lua.State) *lua.State {
+	L.Rawgeti(lua.REGISTRYINDEX, lua.RIDX_MAINTHREAD)
+	main := L.Tothread(-1)
+	L.Pop(1)
+	return main
+}
+
+// attach adds t to the tools receiving the hook events of L's state.
+func attach(L *lua.State, t tool) {
+	main := mainThread(L)
+	sessions.Lock()
+	s := sessions.m[main]
+	if s == nil {
+		s = new(session)
+		sessions.m[main] = s
+	}
+	s.tools = append(s.tools, t)
+	sessions.Unlock()
+	s.update(main)
+}
+
+// detach removes t from the tools of L's state.
+func detach(L *lua.State, t tool) {
+	main := mainThread(L)
+	sessions.Lock()
+	s := sessions.m[main]
+	if s == nil {
+		sessions.Unlock()
+		return
+	}
+	for i, x := range s.tools {
+		if x == t {
+			s.tools = append(s.tools[:i], s.tools[i+1:]...)
+			break
+		}
+	}
+	if len(s.tools) == 0 {
+		delete(sessions.m, main)
+	}
+	sessions.Unlock()
+	s.update(main)
+}
+
+// update sets the hook of the main thread for the current tools.
+func (s *session) update(main *lua.State) {
+	var mask, count c.Int
+	sessions.Lock()
+	for _, t := range s.tools {
+		m, n := t.hookMask()
+		mask |= m
+		if m&lua.MASKCOUNT != 0 && (count == 0 || n < count) {
+			count = n
+		}
+	}
+	sessions.Unlock()
+	if mask == 0 {
+		main.Sethook(nil, 0, 0)
+		return
+	}
+	main.Sethook(onHook, mask, count)
+}
+
+func eventMask(event c.Int) c.Int {
+	switch event {
+	case lua.HOOKCALL, lua.HOOKTAILCALL:
+		return lua.MASKCALL
+	case lua.HOOKRET:
+		return lua.MASKRET
+	case lua.HOOKLINE:
+		return lua.MASKLINE
+	case lua.HOOKCOUNT:
+		return lua.MASKCOUNT
+	}
+	return 0
+}
+
+func onHook(L *lua.State, ar *lua.Debug) {
+	sessions.Lock()
+	s := sessions.m[mainThread(L)]
+	var tools []tool
+	if s != nil {
+		tools = append(tools, s.tools...)
+	}
+	sessions.Unlock()
+	mask := eventMask(ar.Event)
+	for _, t := range tools {
+		if m, _ := t.hookMask(); m&mask != 0 {
+			t.hook(L, ar)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// frame describes a function activation, from the fields of a Debug record
+// filled by Getinfo with "Sln".
+type frame struct {
+	source      string // file name, or short source for other chunks
+	name        string
+	what        string
+	line        int
+	lineDefined int
+}
+
+func getFrame(ar *lua.Debug) frame {
+	f := frame{
+		source:      sourceName(ar),
+		what:        c.GoString(ar.What),
+		line:        int(ar.Currentline),
+		lineDefined: int(ar.Linedefined),
+	}
+	if ar.Name != nil {
+		f.name = c.GoString(ar.Name)
+	}
+	// C functions have no lines.
+	if f.line < 0 {
+		f.line = 0
+	}
+	if f.lineDefined < 0 {
+		f.lineDefined = 0
+	}
+	return f
+}
+
+// sourceName returns the file name of a chunk loaded from a file, and its
+// short source otherwise.
+func sourceName(ar *lua.Debug) string {
+	if ar.Source != nil {
+		if src := c.GoString(ar.Source); strings.HasPrefix(src, "@") {
+			return src[1:]
+		}
+	}
+	return c.GoString(&ar.ShortSrc[0])
+}
+
+// funcName returns a name for the function of f.
+func (f *frame) funcName() string {
+	switch {
+	case f.what == "main":
+		return "main chunk"
+	case f.name != "":
+		return f.name
+	case f.what == "C":
+		return "?"
+	}
+	return fmt.Sprintf("function <%s:%d>", f.source, f.lineDefined)
+}
+
+func (f *frame) String() string {
+	if f.line > 0 {
+		return fmt.Sprintf("%s:%d: in %s", f.source, f.line, f.funcName())
+	}
+	return fmt.Sprintf("%s: in %s", f.source, f.funcName())
+}
+
+// stack returns the frames of the call stack of L, innermost first, up to
+// max frames.
+func stack(L *lua.State, max int) []frame {
+	var frames []frame
+	var ar lua.Debug
+	for level := c.Int(0); len(frames) < max && L.Getstack(level, &ar) != 0; level++ {
+		L.Getinfo(c.Str("Sln"), &ar)
+		frames = append(frames, getFrame(&ar))
+	}
+	return frames
+}
@@ -0,0 +1,295 @@
+package dbg
+
+import (
+	"compress/gzip"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/c/lua"
+)
+
+// ProfileMode selects what a Profiler records.
+type ProfileMode int
+
+const (
+	// Sampling records the call stack every interval instructions.
+	Sampling ProfileMode = iota
+	// Lines records the call stack at every line executed. It is slower but
+	// counts exactly how often each line runs.
+	Lines
+)
+
+// maxStackDepth bounds the frames recorded for a sample.
+const maxStackDepth = 64
+
+// Profiler records where Lua code spends its time and writes the result as
+// a pprof profile, to be read with "go tool pprof".
+type Profiler struct {
+	mode     ProfileMode
+	interval c.Int
+
+	mu        sync.Mutex
+	start     time.Time
+	duration  time.Duration
+	functions map[frameKey]uint64
+	locations map[locationKey]uint64
+	samples   map[string]*stackSample
+	order     []string
+}
+
+type frameKey struct {
+	name   string
+	source string
+	line   int
+}
+
+type locationKey struct {
+	function uint64
+	line     int
+}
+
+type stackSample struct {
+	locations []uint64
+	count     int64
+}
+
+// NewProfiler returns a Profiler in mode. In Sampling mode, interval is the
+// number of instructions between samples; if 0, 1000 is used.
+func NewProfiler(mode ProfileMode, interval int) *Profiler {
+	if interval <= 0 {
+		interval = 1000
+	}
+	return &Profiler{
+		mode:      mode,
+		interval:  c.Int(interval),
+		functions: make(map[frameKey]uint64),
+		locations: make(map[locationKey]uint64),
+		samples:   make(map[string]*stackSample),
+	}
+}
+
+// Start starts profiling the code run by L's state.
+func (p *Profiler) Start(L *lua.State) {
+	p.mu.Lock()
+	p.start = time.Now()
+	p.mu.Unlock()
+	attach(L, p)
+}
+
+// Stop stops profiling L's state.
+func (p *Profiler) Stop(L *lua.State) {
+	detach(L, p)
+	p.mu.Lock()
+	p.duration += time.Since(p.start)
+	p.mu.Unlock()
+}
+
+func (p *Profiler) hookMask() (c.Int, c.Int) {
+	if p.mode == Lines {
+		return lua.MASKLINE, 0
+	}
+	return lua.MASKCOUNT, p.interval
+}
+
+func (p *Profiler) hook(L *lua.State, ar *lua.Debug) {
+	frames := stack(L, maxStackDepth)
+	if len(frames) == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	locs := make([]uint64, len(frames))
+	var key []byte
+	for i := range frames {
+		locs[i] = p.location(&frames[i])
+		key = strconv.AppendUint(append(key, ','), locs[i], 10)
+	}
+	s := p.samples[string(key)]
+	if s == nil {
+		s = &stackSample{locations: locs}
+		p.samples[string(key)] = s
+		p.order = append(p.order, string(key))
+	}
+	s.count++
+}
+
+// location returns the id of the location of f.
+func (p *Profiler) location(f *frame) uint64 {
+	fk := frameKey{f.funcName(), f.source, f.lineDefined}
+	fid, ok := p.functions[fk]
+	if !ok {
+		fid = uint64(len(p.functions) + 1)
+		p.functions[fk] = fid
+	}
+	lk := locationKey{fid, f.line}
+	lid, ok := p.locations[lk]
+	if !ok {
+		lid = uint64(len(p.locations) + 1)
+		p.locations[lk] = lid
+	}
+	return lid
+}
+
+// WriteProfile writes the profile recorded so far in the gzipped protocol
+// buffer format of pprof.
+func (p *Profiler) WriteProfile(w io.Writer) error {
+	p.mu.Lock()
+	data := p.encode()
+	p.mu.Unlock()
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// Field numbers of the messages of profile.proto.
+const (
+	profileSampleType    = 1
+	profileSample        = 2
+	profileLocation      = 4
+	profileFunction      = 5
+	profileStringTable   = 6
+	profileTimeNanos     = 9
+	profileDurationNanos = 10
+	profilePeriodType    = 11
+	profilePeriod        = 12
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+	functionFilename   = 4
+	functionStartLine  = 5
+)
+
+func (p *Profiler) encode() []byte {
+	strs := map[string]int64{"": 0}
+	table := []string{""}
+	str := func(s string) uint64 {
+		i, ok := strs[s]
+		if !ok {
+			i = int64(len(table))
+			strs[s] = i
+			table = append(table, s)
+		}
+		return uint64(i)
+	}
+
+	var b protobuf
+	sampleType, unit := "samples", "count"
+	periodType := "instructions"
+	if p.mode == Lines {
+		sampleType, periodType = "lines", "lines"
+	}
+	b.message(profileSampleType, func(b *protobuf) {
+		b.uint64(valueTypeType, str(sampleType))
+		b.uint64(valueTypeUnit, str(unit))
+	})
+	for _, key := range p.order {
+		s := p.samples[key]
+		b.message(profileSample, func(b *protobuf) {
+			b.packed(sampleLocationID, s.locations)
+			b.packed(sampleValue, []uint64{uint64(s.count)})
+		})
+	}
+	for lk, id := range p.locations {
+		b.message(profileLocation, func(b *protobuf) {
+			b.uint64(locationID, id)
+			b.message(locationLine, func(b *protobuf) {
+				b.uint64(lineFunctionID, lk.function)
+				b.uint64(lineLine, uint64(lk.line))
+			})
+		})
+	}
+	for fk, id := range p.functions {
+		b.message(profileFunction, func(b *protobuf) {
+			b.uint64(functionID, id)
+			b.uint64(functionName, str(fk.name))
+			b.uint64(functionSystemName, str(fk.name))
+			b.uint64(functionFilename, str(fk.source))
+			b.uint64(functionStartLine, uint64(fk.line))
+		})
+	}
+	b.uint64(profileTimeNanos, uint64(p.start.UnixNano()))
+	b.uint64(profileDurationNanos, uint64(p.duration))
+	b.message(profilePeriodType, func(b *protobuf) {
+		b.uint64(valueTypeType, str(periodType))
+		b.uint64(valueTypeUnit, str(unit))
+	})
+	period := uint64(1)
+	if p.mode == Sampling {
+		period = uint64(p.interval)
+	}
+	b.uint64(profilePeriod, period)
+	// The string table must come last: encoding the other fields adds to it.
+	for _, s := range table {
+		b.string(profileStringTable, s)
+	}
+	return b.buf
+}
+
+// -----------------------------------------------------------------------------
+
+// protobuf is a minimal protocol buffer encoder.
+type protobuf struct {
+	buf []byte
+}
+
+func (b *protobuf) varint(x uint64) {
+	for x >= 0x80 {
+		b.buf = append(b.buf, byte(x)|0x80)
+		x >>= 7
+	}
+	b.buf = append(b.buf, byte(x))
+}
+
+func (b *protobuf) key(tag int, wire uint64) {
+	b.varint(uint64(tag)<<3 | wire)
+}
+
+func (b *protobuf) uint64(tag int, x uint64) {
+	if x == 0 {
+		return
+	}
+	b.key(tag, 0)
+	b.varint(x)
+}
+
+func (b *protobuf) string(tag int, s string) {
+	b.key(tag, 2)
+	b.varint(uint64(len(s)))
+	b.buf = append(b.buf, s...)
+}
+
+func (b *protobuf) packed(tag int, xs []uint64) {
+	var n protobuf
+	for _, x := range xs {
+		n.varint(x)
+	}
+	b.key(tag, 2)
+	b.varint(uint64(len(n.buf)))
+	b.buf = append(b.buf, n.buf...)
+}
+
+func (b *protobuf) message(tag int, f func(b *protobuf)) {
+	var n protobuf
+	f(&n)
+	b.key(tag, 2)
+	b.varint(uint64(len(n.buf)))
+	b.buf = append(b.buf, n.buf...)
+}
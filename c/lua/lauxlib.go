@@ -1,47 +1,195 @@
 package lua
 
 import (
-	_ "unsafe"
+	"unsafe"
 
 	"github.com/goplus/lib/c"
 )
 
 // /* global table */
+const GNAME = "_G"
 
 // /* extra error code for 'luaL_loadfilex' */
+const ERRFILE = ERRERR + 1
 
 // /* key, in the registry, for table of loaded modules */
+const LOADED_TABLE = "_LOADED"
 
 // /* key, in the registry, for table of preloaded loaders */
+const PRELOAD_TABLE = "_PRELOAD"
 
-// llgo:link (*State).Checkinteger C.luaL_checkinteger
-func (L *State) Checkinteger(arg c.Int) Integer { return 0 }
+type Reg struct {
+	Name *c.Char
+	Func CFunction
+}
+
+const NUMSIZES = 8*16 + 8
+
+// llgo:link (*State).Checkversion_ C.luaL_checkversion_
+func (L *State) Checkversion_(ver Number, sz uintptr) {}
+
+func (L *State) Checkversion() { L.Checkversion_(VERSION_NUM, NUMSIZES) }
+
+// llgo:link (*State).Getmetafield C.luaL_getmetafield
+func (L *State) Getmetafield(obj c.Int, e *c.Char) c.Int { return 0 }
+
+// llgo:link (*State).Callmeta C.luaL_callmeta
+func (L *State) Callmeta(obj c.Int, e *c.Char) c.Int { return 0 }
+
+// llgo:link (*State).LTolstring C.luaL_tolstring
+func (L *State) LTolstring(idx c.Int, len *c.Ulong) *c.Char { return nil }
+
+// llgo:link (*State).Argerror C.luaL_argerror
+func (L *State) Argerror(arg c.Int, extramsg *c.Char) c.Int { return 0 }
+
+// llgo:link (*State).Typeerror C.luaL_typeerror
+func (L *State) Typeerror(arg c.Int, tname *c.Char) c.Int { return 0 }
+
+// llgo:link (*State).Checklstring C.luaL_checklstring
+func (L *State) Checklstring(arg c.Int, l *c.Ulong) *c.Char { return nil }
+
+// llgo:link (*State).Optlstring C.luaL_optlstring
+func (L *State) Optlstring(arg c.Int, def *c.Char, l *c.Ulong) *c.Char { return nil }
 
 // llgo:link (*State).Checknumber C.luaL_checknumber
 func (L *State) Checknumber(arg c.Int) Number { return 0 }
 
+// llgo:link (*State).Optnumber C.luaL_optnumber
+func (L *State) Optnumber(arg c.Int, def Number) Number { return 0 }
+
+// llgo:link (*State).Checkinteger C.luaL_checkinteger
+func (L *State) Checkinteger(arg c.Int) Integer { return 0 }
+
+// llgo:link (*State).Optinteger C.luaL_optinteger
+func (L *State) Optinteger(arg c.Int, def Integer) Integer { return 0 }
+
+// llgo:link (*State).LCheckstack C.luaL_checkstack
+func (L *State) LCheckstack(sz c.Int, msg *c.Char) {}
+
+// llgo:link (*State).Checktype C.luaL_checktype
+func (L *State) Checktype(arg c.Int, t c.Int) {}
+
+// llgo:link (*State).Checkany C.luaL_checkany
+func (L *State) Checkany(arg c.Int) {}
+
+// llgo:link (*State).Newmetatable C.luaL_newmetatable
+func (L *State) Newmetatable(tname *c.Char) c.Int { return 0 }
+
+// llgo:link (*State).LSetmetatable C.luaL_setmetatable
+func (L *State) LSetmetatable(tname *c.Char) {}
+
+// llgo:link (*State).Testudata C.luaL_testudata
+func (L *State) Testudata(ud c.Int, tname *c.Char) c.Pointer { return nil }
+
+// llgo:link (*State).Checkudata C.luaL_checkudata
+func (L *State) Checkudata(ud c.Int, tname *c.Char) c.Pointer { return nil }
+
+// llgo:link (*State).Where C.luaL_where
+func (L *State) Where(lvl c.Int) {}
+
 // llgo:link (*State).LError C.luaL_error
 func (L *State) LError(format *c.Char, __llgo_va_list ...any) c.Int { return 0 }
 
+// llgo:link (*State).Checkoption C.luaL_checkoption
+func (L *State) Checkoption(arg c.Int, def *c.Char, lst **c.Char) c.Int { return 0 }
+
+// llgo:link (*State).Fileresult C.luaL_fileresult
+func (L *State) Fileresult(stat c.Int, fname *c.Char) c.Int { return 0 }
+
+// llgo:link (*State).Execresult C.luaL_execresult
+func (L *State) Execresult(stat c.Int) c.Int { return 0 }
+
 // /* predefined references */
+const (
+	NOREF  = -2
+	REFNIL = -1
+)
+
+// llgo:link (*State).Ref C.luaL_ref
+func (L *State) Ref(t c.Int) c.Int { return 0 }
+
+// llgo:link (*State).Unref C.luaL_unref
+func (L *State) Unref(t c.Int, ref c.Int) {}
 
 // llgo:link (*State).Loadfilex C.luaL_loadfilex
 func (L *State) Loadfilex(filename *c.Char, mode *c.Char) c.Int { return 0 }
 
 func (L *State) Loadfile(filename *c.Char) c.Int { return L.Loadfilex(filename, nil) }
 
+// llgo:link (*State).Loadbufferx C.luaL_loadbufferx
+func (L *State) Loadbufferx(buff *c.Char, sz uintptr, name *c.Char, mode *c.Char) c.Int { return 0 }
+
 // llgo:link (*State).Loadstring C.luaL_loadstring
 func (L *State) Loadstring(s *c.Char) c.Int { return 0 }
 
 //go:linkname Newstate__1 C.luaL_newstate
 func Newstate__1() *State
 
+// llgo:link (*State).LLen C.luaL_len
+func (L *State) LLen(idx c.Int) Integer { return 0 }
+
+// llgo:link (*Buffer).Addgsub C.luaL_addgsub
+func (B *Buffer) Addgsub(s *c.Char, p *c.Char, r *c.Char) {}
+
+// llgo:link (*State).Gsub C.luaL_gsub
+func (L *State) Gsub(s *c.Char, p *c.Char, r *c.Char) *c.Char { return nil }
+
+// llgo:link (*State).Setfuncs C.luaL_setfuncs
+func (L *State) Setfuncs(l *Reg, nup c.Int) {}
+
+// llgo:link (*State).Getsubtable C.luaL_getsubtable
+func (L *State) Getsubtable(idx c.Int, fname *c.Char) c.Int { return 0 }
+
+// llgo:link (*State).Traceback C.luaL_traceback
+func (L *State) Traceback(L1 *State, msg *c.Char, level c.Int) {}
+
+// llgo:link (*State).Requiref C.luaL_requiref
+func (L *State) Requiref(modname *c.Char, openf CFunction, glb c.Int) {}
+
 // /*
 // ** ===============================================================
 // ** some useful macros
 // ** ===============================================================
 // */
 
+// Newlibtable creates a table sized for the functions in l. The entry with
+// a nil Name that terminates the list for Setfuncs may be left out.
+func (L *State) Newlibtable(l []Reg) {
+	if n := len(l); n > 0 && l[n-1].Name == nil {
+		l = l[:n-1]
+	}
+	L.Createtable(0, c.Int(len(l)))
+}
+
+// Newlib creates a new table and registers the functions in l into it. The
+// terminating entry with a nil Name is added if l does not end with one.
+func (L *State) Newlib(l []Reg) {
+	L.Checkversion()
+	L.Newlibtable(l)
+	if n := len(l); n == 0 || l[n-1].Name != nil {
+		l = append(l[:n:n], Reg{})
+	}
+	L.Setfuncs(&l[0], 0)
+}
+
+func (L *State) Argcheck(cond bool, arg c.Int, extramsg *c.Char) {
+	if !cond {
+		L.Argerror(arg, extramsg)
+	}
+}
+
+func (L *State) Argexpected(cond bool, arg c.Int, tname *c.Char) {
+	if !cond {
+		L.Typeerror(arg, tname)
+	}
+}
+
+func (L *State) Checkstring(arg c.Int) *c.Char { return L.Checklstring(arg, nil) }
+
+func (L *State) Optstring(arg c.Int, def *c.Char) *c.Char { return L.Optlstring(arg, def, nil) }
+
+func (L *State) LTypename(i c.Int) *c.Char { return L.Typename(L.Type(i)) }
+
 func (L *State) Dofile(filename *c.Char) c.Int {
 	if loadResult := L.Loadfile(filename); loadResult != 0 {
 		return loadResult
@@ -56,12 +204,19 @@ func (L *State) Dostring(str *c.Char) c.Int {
 	return L.Pcall(c.Int(0), c.Int(MULTRET), c.Int(0))
 }
 
+func (L *State) LGetmetatable(tname *c.Char) c.Int { return L.Getfield(REGISTRYINDEX, tname) }
+
+func (L *State) Loadbuffer(buff *c.Char, sz uintptr, name *c.Char) c.Int {
+	return L.Loadbufferx(buff, sz, name, nil)
+}
+
 // /*
 // ** Perform arithmetic operations on lua_Integer values with wrap-around
 // ** semantics, as the Lua core does.
 // */
 
 // /* push the value used to represent failure/error */
+func (L *State) Pushfail() { L.Pushnil() }
 
 // /*
 // ** {======================================================
@@ -69,6 +224,63 @@ func (L *State) Dostring(str *c.Char) c.Int {
 // ** =======================================================
 // */
 
+const BUFFERSIZE = 16 * unsafe.Sizeof(uintptr(0)) * unsafe.Sizeof(Number(0))
+
+// Buffer is a luaL_Buffer. It points into itself while in use, so it must
+// not be copied after Buffinit.
+type Buffer struct {
+	B    *c.Char
+	Size uintptr
+	N    uintptr
+	L    *State
+	Init struct {
+		_ [0]float64
+		B [BUFFERSIZE]c.Char
+	}
+}
+
+func (B *Buffer) Bufflen() uintptr { return B.N }
+
+func (B *Buffer) Buffaddr() *c.Char { return B.B }
+
+func (B *Buffer) Addchar(ch c.Char) {
+	if B.N >= B.Size {
+		B.Prepbuffsize(1)
+	}
+	*(*c.Char)(c.Pointer(uintptr(c.Pointer(B.B)) + B.N)) = ch
+	B.N++
+}
+
+func (B *Buffer) Addsize(s uintptr) { B.N += s }
+
+func (B *Buffer) Buffsub(s uintptr) { B.N -= s }
+
+// llgo:link (*State).Buffinit C.luaL_buffinit
+func (L *State) Buffinit(B *Buffer) {}
+
+// llgo:link (*Buffer).Prepbuffsize C.luaL_prepbuffsize
+func (B *Buffer) Prepbuffsize(sz uintptr) *c.Char { return nil }
+
+// llgo:link (*Buffer).Addlstring C.luaL_addlstring
+func (B *Buffer) Addlstring(s *c.Char, l uintptr) {}
+
+// llgo:link (*Buffer).Addstring C.luaL_addstring
+func (B *Buffer) Addstring(s *c.Char) {}
+
+// llgo:link (*Buffer).Addvalue C.luaL_addvalue
+func (B *Buffer) Addvalue() {}
+
+// llgo:link (*Buffer).Pushresult C.luaL_pushresult
+func (B *Buffer) Pushresult() {}
+
+// llgo:link (*Buffer).Pushresultsize C.luaL_pushresultsize
+func (B *Buffer) Pushresultsize(sz uintptr) {}
+
+// llgo:link (*State).Buffinitsize C.luaL_buffinitsize
+func (L *State) Buffinitsize(B *Buffer, sz uintptr) *c.Char { return nil }
+
+func (B *Buffer) Prepbuffer() *c.Char { return B.Prepbuffsize(BUFFERSIZE) }
+
 // /* }====================================================== */
 
 // /*
@@ -84,6 +296,12 @@ func (L *State) Dostring(str *c.Char) c.Int {
 // */
 
 // #define LUA_FILEHANDLE          "FILE*"
+const FILEHANDLE = "FILE*"
+
+type Stream struct {
+	F      c.FilePtr /* stream (NULL for incompletely created streams) */
+	Closef CFunction /* to close stream (NULL for closed streams) */
+}
 
 // /* }====================================================== */
 
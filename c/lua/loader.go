@@ -0,0 +1,246 @@
+package lua
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+// addSearcher inserts the searcher at the top of the stack into
+// package.searchers, right after the preload searcher, and pops it.
+func (L *State) addSearcher() error {
+	defer L.Pop(1)
+	top := L.Gettop()
+	defer L.Settop(top)
+	L.Getsubtable(REGISTRYINDEX, c.Str(LOADED_TABLE))
+	if L.Getfield(-1, c.Str(LOADLIBNAME)) != TABLE {
+		return fmt.Errorf("lua: package library is not open")
+	}
+	if L.Getfield(-1, c.Str("searchers")) != TABLE {
+		return fmt.Errorf("lua: package.searchers is not a table")
+	}
+	for i := Integer(L.Rawlen(-1)); i >= 2; i-- {
+		L.Rawgeti(-1, i)
+		L.Rawseti(-2, i+1)
+	}
+	L.Pushvalue(top)
+	L.Rawseti(-2, 2)
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+// goModules holds the modules registered with RegisterModule and
+// RegisterGoModule: a CFunction, or a value for PushGo.
+var goModules = struct {
+	sync.RWMutex
+	m map[string]any
+}{m: make(map[string]any)}
+
+// RegisterModule registers open as the loader of the module name, for the
+// states where AddGoSearcher was called. Like a luaopen_ function, open
+// receives the module name and returns the module value.
+func RegisterModule(name string, open CFunction) {
+	goModules.Lock()
+	goModules.m[name] = open
+	goModules.Unlock()
+}
+
+// RegisterGoModule registers v as the module name, for the states where
+// AddGoSearcher was called: require returns v converted by PushGo. A
+// map[string]any of Go funcs thus gives a table of Lua functions.
+func RegisterGoModule(name string, v any) {
+	goModules.Lock()
+	goModules.m[name] = v
+	goModules.Unlock()
+}
+
+// AddGoSearcher adds to package.searchers a searcher finding the modules
+// registered with RegisterModule and RegisterGoModule. It is consulted
+// right after package.preload.
+func (L *State) AddGoSearcher() error {
+	L.Pushcfunction(goSearcher)
+	return L.addSearcher()
+}
+
+func goSearcher(L *State) c.Int {
+	return L.raise(L.protect(L.searchGo))
+}
+
+func (L *State) searchGo() c.Int {
+	name := L.toGoString(1)
+	goModules.RLock()
+	mod, ok := goModules.m[name]
+	goModules.RUnlock()
+	if !ok {
+		L.pushString(fmt.Sprintf("no Go module '%s'", name))
+		return 1
+	}
+	if open, ok := mod.(CFunction); ok {
+		L.Pushcfunction(open)
+	} else {
+		L.PushGo(mod)
+		L.Pushcclosure(goModuleLoader, 1)
+	}
+	L.pushString(":go:")
+	return 2
+}
+
+// goModuleLoader returns its upvalue, the value of a module registered with
+// RegisterGoModule.
+func goModuleLoader(L *State) c.Int {
+	L.Pushvalue(Upvalueindex(1))
+	return 1
+}
+
+// -----------------------------------------------------------------------------
+
+// DefaultFSPath is the search path used by NewFSLoader when none is given.
+const DefaultFSPath = "?.lua;?/init.lua;?.luac"
+
+// FSLoader loads Lua chunks and modules from a file system, such as an
+// embed.FS, so that scripts can ship inside the binary instead of being
+// found through LUA_PATH. Files may hold source code or binary chunks made
+// by Dump or luac.
+//
+// Chunks are cached by path: a source file is compiled once, and later
+// loads, in the same or other states, load its dumped binary chunk. An
+// FSLoader may be shared by states used from different goroutines.
+type FSLoader struct {
+	fsys  fs.FS
+	path  []string
+	strip bool
+
+	mu    sync.Mutex
+	cache map[string][]byte
+}
+
+// NewFSLoader returns an FSLoader for fsys. path is a list of templates
+// separated by semicolons, like package.path, in which '?' stands for the
+// module name with dots replaced by slashes; if empty, DefaultFSPath is
+// used.
+func NewFSLoader(fsys fs.FS, path string) *FSLoader {
+	if path == "" {
+		path = DefaultFSPath
+	}
+	return &FSLoader{fsys: fsys, path: strings.Split(path, ";"), cache: make(map[string][]byte)}
+}
+
+// SetStrip makes the cached chunks leave out debug information: they load
+// faster and use less memory, but errors lose their line numbers.
+func (l *FSLoader) SetStrip(strip bool) {
+	l.strip = strip
+}
+
+// Load loads the file name of the file system as a chunk and pushes it onto
+// the stack as a function, or returns an *Error.
+func (l *FSLoader) Load(L *State, name string) error {
+	l.mu.Lock()
+	chunk, ok := l.cache[name]
+	l.mu.Unlock()
+	if !ok {
+		data, err := fs.ReadFile(l.fsys, name)
+		if err != nil {
+			return &Error{Status: ERRFILE, Message: fmt.Sprintf("cannot read %s: %v", name, err)}
+		}
+		chunk = data
+	}
+	if err := L.loadError(loadChunk(L, chunk, "@"+name)); err != nil {
+		return err
+	}
+	if !ok {
+		if !bytes.HasPrefix(chunk, []byte(SIGNATURE)) {
+			chunk = dumpChunk(L, l.strip)
+		}
+		l.mu.Lock()
+		l.cache[name] = chunk
+		l.mu.Unlock()
+	}
+	return nil
+}
+
+func loadChunk(L *State, chunk []byte, name string) c.Int {
+	var p *c.Char
+	if len(chunk) > 0 {
+		p = (*c.Char)(unsafe.Pointer(&chunk[0]))
+	}
+	return L.Loadbufferx(p, uintptr(len(chunk)), c.AllocaCStr(name), nil)
+}
+
+// dumpChunk returns the binary chunk of the function at the top of the
+// stack.
+func dumpChunk(L *State, strip bool) []byte {
+	var buf []byte
+	var s c.Int
+	if strip {
+		s = 1
+	}
+	L.Dump(chunkWriter, c.Pointer(&buf), s)
+	return buf
+}
+
+func chunkWriter(L *State, p c.Pointer, sz c.Ulong, ud c.Pointer) c.Int {
+	buf := (*[]byte)(ud)
+	*buf = append(*buf, unsafe.Slice((*byte)(p), sz)...)
+	return OK
+}
+
+// find returns the first file matching the module name on the search path,
+// or "" and the list of files tried.
+func (l *FSLoader) find(name string) (file string, tried string) {
+	name = strings.ReplaceAll(name, ".", "/")
+	var msg strings.Builder
+	for _, tmpl := range l.path {
+		file := path.Clean(strings.ReplaceAll(tmpl, "?", name))
+		l.mu.Lock()
+		_, ok := l.cache[file]
+		l.mu.Unlock()
+		if ok {
+			return file, ""
+		}
+		if fi, err := fs.Stat(l.fsys, file); err == nil && !fi.IsDir() {
+			return file, ""
+		}
+		if msg.Len() > 0 {
+			msg.WriteString("\n\t")
+		}
+		fmt.Fprintf(&msg, "no file '%s'", file)
+	}
+	return "", msg.String()
+}
+
+// AddSearcher adds to package.searchers of L a searcher finding modules in
+// the file system. It is consulted right after package.preload.
+func (l *FSLoader) AddSearcher(L *State) error {
+	L.pushGoObject(reflect.ValueOf(l))
+	L.Pushcclosure(fsSearcher, 1)
+	return L.addSearcher()
+}
+
+func fsSearcher(L *State) c.Int {
+	return L.raise(L.protect(L.searchFS))
+}
+
+func (L *State) searchFS() c.Int {
+	obj, _ := L.toGoObject(Upvalueindex(1))
+	l := obj.Interface().(*FSLoader)
+	name := L.toGoString(1)
+	file, tried := l.find(name)
+	if file == "" {
+		L.pushString(tried)
+		return 1
+	}
+	if err := l.Load(L, file); err != nil {
+		L.pushString(fmt.Sprintf("error loading module '%s' from file '%s':\n\t%v", name, file, err))
+		return -1
+	}
+	L.pushString(file)
+	return 2
+}
@@ -10,7 +10,17 @@ const (
 	LLGoPackage = "link: $(pkg-config --libs lua); -llua -lm"
 )
 
+const (
+	VERSION_MAJOR_N   = 5
+	VERSION_MINOR_N   = 4
+	VERSION_NUM       = 504
+	VERSION_RELEASE_N = 6
+)
+
 /* mark for precompiled code ('<esc>Lua') */
+const (
+	SIGNATURE = "\x1bLua"
+)
 
 /* option for multiple returns in 'lua_pcall' and 'lua_call' */
 const (
@@ -78,10 +88,10 @@ const (
 type Number = c.Double
 
 /* type for integer functions */
-type Integer = c.Int
+type Integer = c.LongLong
 
 /* unsigned integer type */
-type Unsigned = c.Uint
+type Unsigned = c.UlongLong
 
 /* type for continuation-function contexts */
 type KContext = c.Pointer
@@ -227,7 +237,8 @@ func (L *State) Toboolean(idx c.Int) bool { return false }
 // llgo:link (*State).Tolstring C.lua_tolstring
 func (L *State) Tolstring(idx c.Int, len *c.Ulong) *c.Char { return nil }
 
-// LUA_API lua_Unsigned    (lua_rawlen) (State *L, int idx);
+// llgo:link (*State).Rawlen C.lua_rawlen
+func (L *State) Rawlen(idx c.Int) Unsigned { return 0 }
 
 // llgo:link (*State).Tocfunction C.lua_tocfunction
 func (L *State) Tocfunction(idx c.Int) CFunction { return nil }
@@ -245,6 +256,38 @@ func (L *State) Topointer(idx c.Int) c.Pointer { return nil }
  * Comparison and arithmetic functions
  */
 
+const (
+	OPADD  = 0 /* ORDER TM, ORDER OP */
+	OPSUB  = 1
+	OPMUL  = 2
+	OPMOD  = 3
+	OPPOW  = 4
+	OPDIV  = 5
+	OPIDIV = 6
+	OPBAND = 7
+	OPBOR  = 8
+	OPBXOR = 9
+	OPSHL  = 10
+	OPSHR  = 11
+	OPUNM  = 12
+	OPBNOT = 13
+)
+
+// llgo:link (*State).Arith C.lua_arith
+func (L *State) Arith(op c.Int) {}
+
+const (
+	OPEQ = 0
+	OPLT = 1
+	OPLE = 2
+)
+
+// llgo:link (*State).Rawequal C.lua_rawequal
+func (L *State) Rawequal(idx1 c.Int, idx2 c.Int) c.Int { return 0 }
+
+// llgo:link (*State).Compare C.lua_compare
+func (L *State) Compare(idx1 c.Int, idx2 c.Int, op c.Int) c.Int { return 0 }
+
 /*
  * push functions (C -> stack)
  */
@@ -554,7 +597,7 @@ const (
  */
 
 const (
-	MASKCALL  = 1 << HOOKCOUNT
+	MASKCALL  = 1 << HOOKCALL
 	MASKRET   = 1 << HOOKRET
 	MASKLINE  = 1 << HOOKLINE
 	MASKCOUNT = 1 << HOOKCOUNT
@@ -2,7 +2,58 @@ package lua
 
 import (
 	_ "unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+/* version suffix for environment variable names */
+const (
+	VERSUFFIX = "_5_4"
+)
+
+const (
+	COLIBNAME   = "coroutine"
+	TABLIBNAME  = "table"
+	IOLIBNAME   = "io"
+	OSLIBNAME   = "os"
+	STRLIBNAME  = "string"
+	UTF8LIBNAME = "utf8"
+	MATHLIBNAME = "math"
+	DBLIBNAME   = "debug"
+	LOADLIBNAME = "package"
 )
 
+//go:linkname OpenBase C.luaopen_base
+func OpenBase(L *State) c.Int
+
+//go:linkname OpenCoroutine C.luaopen_coroutine
+func OpenCoroutine(L *State) c.Int
+
+//go:linkname OpenTable C.luaopen_table
+func OpenTable(L *State) c.Int
+
+//go:linkname OpenIo C.luaopen_io
+func OpenIo(L *State) c.Int
+
+//go:linkname OpenOs C.luaopen_os
+func OpenOs(L *State) c.Int
+
+//go:linkname OpenString C.luaopen_string
+func OpenString(L *State) c.Int
+
+//go:linkname OpenUtf8 C.luaopen_utf8
+func OpenUtf8(L *State) c.Int
+
+//go:linkname OpenMath C.luaopen_math
+func OpenMath(L *State) c.Int
+
+//go:linkname OpenDebug C.luaopen_debug
+func OpenDebug(L *State) c.Int
+
+//go:linkname OpenPackage C.luaopen_package
+func OpenPackage(L *State) c.Int
+
+/* open all previous libraries */
+
 // llgo:link (*State).Openlibs C.luaL_openlibs
 func (L *State) Openlibs() {}
@@ -0,0 +1,905 @@
+package lua
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+// maxConvertDepth bounds how deeply nested tables, maps and slices are
+// converted, so that self-referencing values cannot recurse forever.
+const maxConvertDepth = 100
+
+// -----------------------------------------------------------------------------
+
+// goObjects keeps the Go values referenced by Lua userdata alive. Lua memory
+// is not scanned by the Go garbage collector, so userdata only stores a
+// handle into this table; the handle is released by the userdata's __gc.
+var goObjects = struct {
+	sync.Mutex
+	next uintptr
+	m    map[uintptr]reflect.Value
+}{m: make(map[uintptr]reflect.Value)}
+
+func newGoHandle(v reflect.Value) uintptr {
+	goObjects.Lock()
+	defer goObjects.Unlock()
+	goObjects.next++
+	goObjects.m[goObjects.next] = v
+	return goObjects.next
+}
+
+func goHandleValue(h uintptr) (reflect.Value, bool) {
+	goObjects.Lock()
+	defer goObjects.Unlock()
+	v, ok := goObjects.m[h]
+	return v, ok
+}
+
+func freeGoHandle(h uintptr) {
+	goObjects.Lock()
+	delete(goObjects.m, h)
+	goObjects.Unlock()
+}
+
+var goObjectMeta = c.Str("lua.GoObject")
+
+// pushGoObject pushes a userdata that refers to v.
+func (L *State) pushGoObject(v reflect.Value) {
+	h := (*uintptr)(L.Newuserdatauv(unsafe.Sizeof(uintptr(0)), 0))
+	*h = newGoHandle(v)
+	if L.Newmetatable(goObjectMeta) != 0 {
+		methods := []Reg{
+			{Name: c.Str("__gc"), Func: goObjectGC},
+			{Name: c.Str("__index"), Func: goObjectIndex},
+			{Name: c.Str("__newindex"), Func: goObjectNewindex},
+			{Name: c.Str("__len"), Func: goObjectLen},
+			{Name: c.Str("__eq"), Func: goObjectEq},
+			{Name: c.Str("__tostring"), Func: goObjectTostring},
+			{},
+		}
+		L.Setfuncs(&methods[0], 0)
+	}
+	L.Setmetatable(-2)
+}
+
+// toGoObject returns the Go value referred to by the userdata at idx, if it
+// was created by PushGo.
+func (L *State) toGoObject(idx c.Int) (reflect.Value, bool) {
+	h := (*uintptr)(L.Testudata(idx, goObjectMeta))
+	if h == nil {
+		return reflect.Value{}, false
+	}
+	return goHandleValue(*h)
+}
+
+// -----------------------------------------------------------------------------
+
+// protect runs fn, the body of a C function implemented in Go. A Go panic
+// is turned into a Lua error message on the stack and a negative result, so
+// that the caller can raise it with Error once no Go frames with pending
+// defers are left to skip. The panic value is saved so that pcall can raise
+// it again if the error reaches Go uncaught; an *Error is raised as its
+// message.
+func (L *State) protect(fn func() c.Int) (n c.Int) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(*Error); ok {
+				L.pushString(e.Message)
+			} else {
+				L.pushPanic(r)
+				L.savePanic(r)
+			}
+			n = -1
+		}
+	}()
+	return fn()
+}
+
+// raise ends a C function implemented in Go: it returns n, or raises the
+// error left on the stack by protect.
+func (L *State) raise(n c.Int) c.Int {
+	if n < 0 {
+		return L.Error()
+	}
+	return n
+}
+
+// pushPanic pushes the error value for a Go panic raised in a callback.
+func (L *State) pushPanic(r any) {
+	switch r := r.(type) {
+	case error:
+		L.pushErrorf("%s", r.Error())
+	default:
+		L.pushErrorf("%v", r)
+	}
+}
+
+// pushErrorf pushes an error message prefixed with the current position,
+// like luaL_error does, without raising it.
+func (L *State) pushErrorf(format string, args ...any) {
+	L.Where(1)
+	L.pushString(fmt.Sprintf(format, args...))
+	L.Concat(2)
+}
+
+// -----------------------------------------------------------------------------
+
+func (L *State) pushString(s string) {
+	L.Pushlstring(c.GoStringData(s), c.Ulong(len(s)))
+}
+
+func (L *State) pushBytes(b []byte) {
+	if len(b) == 0 {
+		L.pushString("")
+		return
+	}
+	L.Pushlstring((*c.Char)(unsafe.Pointer(&b[0])), c.Ulong(len(b)))
+}
+
+// toGoString returns the string or number at idx as a Go string. Unlike
+// Tolstring it never converts the value on the stack in place, which would
+// confuse Next during a table traversal.
+func (L *State) toGoString(idx c.Int) string {
+	L.Pushvalue(idx)
+	var n c.Ulong
+	p := L.Tolstring(-1, &n)
+	s := string(unsafe.Slice((*byte)(unsafe.Pointer(p)), n))
+	L.Pop(1)
+	return s
+}
+
+// toInt64 returns the number at idx as an int64, and false if it is not a
+// number with an integral value that fits in an int64. Unlike Tointegerx,
+// it does not convert strings.
+func (L *State) toInt64(idx c.Int) (int64, bool) {
+	if L.Type(idx) != NUMBER {
+		return 0, false
+	}
+	var isnum c.Int
+	n := L.Tointegerx(idx, &isnum)
+	return int64(n), isnum != 0
+}
+
+// -----------------------------------------------------------------------------
+
+// PushGo pushes a Go value onto the stack:
+//
+//   - nil, booleans, numbers and strings become the matching Lua values;
+//     []byte becomes a string.
+//   - Slices and arrays become sequences, and maps and structs become tables
+//     (a copy: later changes on either side are not shared). Struct fields
+//     are named by their `lua:"name"` tag or else their Go name; fields
+//     tagged `lua:"-"` and unexported fields are skipped.
+//   - Functions become Lua functions. Arguments are converted as by ToGo and
+//     checked, results are pushed as by PushGo, and a non-nil trailing error
+//     result is raised as a Lua error.
+//   - Pointers, channels and other values become userdata that refer to the
+//     Go value. Fields, elements and methods of the value are reached by
+//     indexing the userdata; methods take the receiver as their first
+//     argument, so they are called as obj:Method(...).
+func (L *State) PushGo(v any) {
+	if f, ok := v.(CFunction); ok {
+		L.Pushcfunction(f)
+		return
+	}
+	L.pushValue(reflect.ValueOf(v), 0)
+}
+
+func (L *State) pushValue(v reflect.Value, depth int) {
+	if !v.IsValid() || depth > maxConvertDepth {
+		L.Pushnil()
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			L.Pushboolean(1)
+		} else {
+			L.Pushboolean(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		L.Pushinteger(Integer(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := v.Uint(); u <= math.MaxInt64 {
+			L.Pushinteger(Integer(u))
+		} else {
+			L.Pushnumber(Number(u))
+		}
+	case reflect.Float32, reflect.Float64:
+		L.Pushnumber(Number(v.Float()))
+	case reflect.String:
+		L.pushString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			L.Pushnil()
+			return
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			L.pushBytes(v.Bytes())
+			return
+		}
+		fallthrough
+	case reflect.Array:
+		n := v.Len()
+		L.Createtable(c.Int(n), 0)
+		for i := 0; i < n; i++ {
+			L.pushValue(v.Index(i), depth+1)
+			L.Rawseti(-2, Integer(i+1))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			L.Pushnil()
+			return
+		}
+		L.Createtable(0, c.Int(v.Len()))
+		iter := v.MapRange()
+		for iter.Next() {
+			L.pushValue(iter.Key(), depth+1)
+			if L.Isnil(-1) || L.Type(-1) == NUMBER && L.Rawequal(-1, -1) == 0 {
+				// nil and NaN cannot be table keys.
+				L.Pop(1)
+				continue
+			}
+			L.pushValue(iter.Value(), depth+1)
+			L.Rawset(-3)
+		}
+	case reflect.Struct:
+		fields := structFields(v.Type())
+		L.Createtable(0, c.Int(len(fields)))
+		for _, f := range fields {
+			L.pushString(f.name)
+			L.pushValue(v.FieldByIndex(f.index), depth+1)
+			L.Rawset(-3)
+		}
+	case reflect.Interface:
+		L.pushValue(v.Elem(), depth)
+	case reflect.Pointer:
+		if v.IsNil() {
+			L.Pushnil()
+			return
+		}
+		L.pushGoObject(v)
+	case reflect.Func:
+		if v.IsNil() {
+			L.Pushnil()
+			return
+		}
+		L.pushGoObject(v)
+		L.Pushcclosure(goFuncCall, 1)
+	case reflect.UnsafePointer:
+		L.Pushlightuserdata(c.Pointer(v.UnsafePointer()))
+	default:
+		L.pushGoObject(v)
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+type structField struct {
+	name  string
+	index []int
+}
+
+var structFieldCache sync.Map // reflect.Type -> []structField
+
+// structFields returns the exported fields of struct type t with their Lua
+// names.
+func structFields(t reflect.Type) []structField {
+	if fields, ok := structFieldCache.Load(t); ok {
+		return fields.([]structField)
+	}
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("lua"); ok {
+			tag, _, _ = strings.Cut(tag, ",")
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, structField{name: name, index: f.Index})
+	}
+	structFieldCache.Store(t, fields)
+	return fields
+}
+
+func fieldByName(v reflect.Value, name string) (reflect.Value, bool) {
+	for _, f := range structFields(v.Type()) {
+		if f.name == name {
+			return v.FieldByIndex(f.index), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// -----------------------------------------------------------------------------
+
+// ToGo converts the value at idx and stores it in the value ptr points to.
+// It is the inverse of PushGo: tables convert to slices, arrays, maps and
+// structs, Lua functions convert to Go funcs that call them, and userdata
+// created by PushGo convert back to the Go values they refer to. Into an
+// interface type, values convert to nil, bool, int64, float64, string,
+// []any (for sequences), map[string]any or map[any]any (for other tables),
+// func(...any) ([]any, error) (for functions), or the Go value of userdata.
+//
+// Lua functions converted to Go are kept in the registry until the state is
+// closed.
+func (L *State) ToGo(idx c.Int, ptr any) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("lua: ToGo needs a non-nil pointer, got %T", ptr)
+	}
+	idx = L.Absindex(idx)
+	top := L.Gettop()
+	defer L.Settop(top)
+	return L.toValue(idx, v.Elem(), 0)
+}
+
+func (L *State) typeError(idx c.Int, t reflect.Type) error {
+	return fmt.Errorf("lua: cannot convert %s to %v", c.GoString(L.LTypename(idx)), t)
+}
+
+// nilable reports whether a Lua nil can be converted to type t.
+func nilable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Pointer, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+func (L *State) toValue(idx c.Int, v reflect.Value, depth int) error {
+	t := v.Type()
+	if depth > maxConvertDepth {
+		return fmt.Errorf("lua: value nested too deeply to convert to %v", t)
+	}
+	tp := L.Type(idx)
+	if tp == USERDATA {
+		if obj, ok := L.toGoObject(idx); ok {
+			switch {
+			case obj.Type().AssignableTo(t):
+				v.Set(obj)
+			case obj.Kind() == reflect.Pointer && obj.Elem().Type().AssignableTo(t):
+				v.Set(obj.Elem())
+			default:
+				return fmt.Errorf("lua: cannot convert %v to %v", obj.Type(), t)
+			}
+			return nil
+		}
+	}
+	if tp == NIL || tp == NONE {
+		if t.Kind() != reflect.Bool && !nilable(t) {
+			return L.typeError(idx, t)
+		}
+		v.Set(reflect.Zero(t))
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		x, err := L.toAny(idx, depth)
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+		xv := reflect.ValueOf(x)
+		if !xv.Type().AssignableTo(t) {
+			return fmt.Errorf("lua: cannot convert %v to %v", xv.Type(), t)
+		}
+		v.Set(xv)
+	case reflect.Bool:
+		if tp != BOOLEAN {
+			return L.typeError(idx, t)
+		}
+		v.SetBool(L.Toboolean(idx))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := L.toInt64(idx)
+		if !ok || v.OverflowInt(n) {
+			return L.typeError(idx, t)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := L.toInt64(idx)
+		if !ok || n < 0 || v.OverflowUint(uint64(n)) {
+			return L.typeError(idx, t)
+		}
+		v.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		if tp != NUMBER {
+			return L.typeError(idx, t)
+		}
+		v.SetFloat(float64(L.Tonumberx(idx, nil)))
+	case reflect.String:
+		if tp != STRING && tp != NUMBER {
+			return L.typeError(idx, t)
+		}
+		v.SetString(L.toGoString(idx))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && tp == STRING {
+			v.SetBytes([]byte(L.toGoString(idx)))
+			return nil
+		}
+		if tp != TABLE {
+			return L.typeError(idx, t)
+		}
+		n := int(L.Rawlen(idx))
+		s := reflect.MakeSlice(t, n, n)
+		for i := 0; i < n; i++ {
+			L.Rawgeti(idx, Integer(i+1))
+			err := L.toValue(L.Gettop(), s.Index(i), depth+1)
+			L.Pop(1)
+			if err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Array:
+		if tp != TABLE {
+			return L.typeError(idx, t)
+		}
+		n := int(L.Rawlen(idx))
+		if n > v.Len() {
+			return fmt.Errorf("lua: table of length %d does not fit in %v", n, t)
+		}
+		for i := 0; i < n; i++ {
+			L.Rawgeti(idx, Integer(i+1))
+			err := L.toValue(L.Gettop(), v.Index(i), depth+1)
+			L.Pop(1)
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if tp != TABLE {
+			return L.typeError(idx, t)
+		}
+		m := reflect.MakeMap(t)
+		L.Pushnil()
+		for L.Next(idx) != 0 {
+			key := reflect.New(t.Key()).Elem()
+			val := reflect.New(t.Elem()).Elem()
+			top := L.Gettop()
+			if err := L.toValue(top-1, key, depth+1); err != nil {
+				return err
+			}
+			if err := L.toValue(top, val, depth+1); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, val)
+			L.Pop(1)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		if tp != TABLE {
+			return L.typeError(idx, t)
+		}
+		for _, f := range structFields(t) {
+			L.pushString(f.name)
+			L.Rawget(idx)
+			if !L.Isnil(-1) {
+				if err := L.toValue(L.Gettop(), v.FieldByIndex(f.index), depth+1); err != nil {
+					return fmt.Errorf("field %s: %w", f.name, err)
+				}
+			}
+			L.Pop(1)
+		}
+	case reflect.Pointer:
+		p := reflect.New(t.Elem())
+		if err := L.toValue(idx, p.Elem(), depth+1); err != nil {
+			return err
+		}
+		v.Set(p)
+	case reflect.Func:
+		if tp != FUNCTION {
+			return L.typeError(idx, t)
+		}
+		v.Set(L.luaFunc(idx, t))
+	case reflect.UnsafePointer:
+		if tp != LIGHTUSERDATA && tp != USERDATA {
+			return L.typeError(idx, t)
+		}
+		v.SetPointer(unsafe.Pointer(L.Touserdata(idx)))
+	default:
+		return L.typeError(idx, t)
+	}
+	return nil
+}
+
+// toAny converts the value at idx to its natural Go representation.
+func (L *State) toAny(idx c.Int, depth int) (any, error) {
+	switch L.Type(idx) {
+	case NIL, NONE:
+		return nil, nil
+	case BOOLEAN:
+		return L.Toboolean(idx), nil
+	case NUMBER:
+		if L.Isinteger(idx) != 0 {
+			return int64(L.Tointegerx(idx, nil)), nil
+		}
+		return float64(L.Tonumberx(idx, nil)), nil
+	case STRING:
+		return L.toGoString(idx), nil
+	case TABLE:
+		return L.toAnyTable(idx, depth)
+	case FUNCTION:
+		return L.luaFunc(idx, anyFuncType).Interface(), nil
+	case USERDATA:
+		if obj, ok := L.toGoObject(idx); ok {
+			return obj.Interface(), nil
+		}
+		return L.Touserdata(idx), nil
+	case LIGHTUSERDATA:
+		return L.Touserdata(idx), nil
+	case THREAD:
+		return L.Tothread(idx), nil
+	}
+	return nil, fmt.Errorf("lua: cannot convert %s", c.GoString(L.LTypename(idx)))
+}
+
+func (L *State) toAnyTable(idx c.Int, depth int) (any, error) {
+	if depth > maxConvertDepth {
+		return nil, fmt.Errorf("lua: table nested too deeply")
+	}
+	n := int(L.Rawlen(idx))
+	var keys, vals []any
+	allStrings := true
+	L.Pushnil()
+	for L.Next(idx) != 0 {
+		top := L.Gettop()
+		k, err := L.toAny(top-1, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		val, err := L.toAny(top, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := k.(string); !ok {
+			allStrings = false
+		}
+		keys = append(keys, k)
+		vals = append(vals, val)
+		L.Pop(1)
+	}
+	if n > 0 && len(keys) == n && isSequence(keys, n) {
+		// Only the keys 1..n: a sequence.
+		s := make([]any, n)
+		for i, k := range keys {
+			s[k.(int64)-1] = vals[i]
+		}
+		return s, nil
+	}
+	if allStrings {
+		m := make(map[string]any, len(keys))
+		for i, k := range keys {
+			m[k.(string)] = vals[i]
+		}
+		return m, nil
+	}
+	m := make(map[any]any, len(keys))
+	for i, k := range keys {
+		if !reflect.TypeOf(k).Comparable() {
+			// Such as a table key, converted to a slice or map.
+			return nil, fmt.Errorf("lua: cannot convert table key of type %T", k)
+		}
+		m[k] = vals[i]
+	}
+	return m, nil
+}
+
+// isSequence reports whether the n keys are the integers 1..n. The length of
+// a table with holes may be any border, so matching counts are not enough.
+func isSequence(keys []any, n int) bool {
+	for _, k := range keys {
+		i, ok := k.(int64)
+		if !ok || i < 1 || i > int64(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// -----------------------------------------------------------------------------
+
+var (
+	anyFuncType  = reflect.TypeOf((func(...any) ([]any, error))(nil))
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	anySliceType = reflect.TypeOf([]any(nil))
+)
+
+// mainThread returns the main thread of L's state.
+func (L *State) mainThread() *State {
+	L.Rawgeti(REGISTRYINDEX, RIDX_MAINTHREAD)
+	main := L.Tothread(-1)
+	L.Pop(1)
+	return main
+}
+
+// luaFunc returns a Go func of type t that calls the Lua function at idx on
+// the main thread. Its results are converted to t's results; a trailing
+// error result receives Lua errors as *Error, which otherwise panic.
+func (L *State) luaFunc(idx c.Int, t reflect.Type) reflect.Value {
+	L.Pushvalue(idx)
+	ref := L.Ref(REGISTRYINDEX)
+	main := L.mainThread()
+	return reflect.MakeFunc(t, func(args []reflect.Value) []reflect.Value {
+		if t.IsVariadic() {
+			last := args[len(args)-1]
+			args = args[:len(args)-1]
+			for i := 0; i < last.Len(); i++ {
+				args = append(args, last.Index(i))
+			}
+		}
+		return main.callRef(ref, args, t)
+	})
+}
+
+// callRef calls the function stored in the registry under ref with args and
+// converts its results to the results of function type t.
+func (L *State) callRef(ref c.Int, args []reflect.Value, t reflect.Type) []reflect.Value {
+	numOut := t.NumOut()
+	hasErr := numOut > 0 && t.Out(numOut-1) == errorType
+	if hasErr {
+		numOut--
+	}
+	out := make([]reflect.Value, t.NumOut())
+	for i := range out {
+		out[i] = reflect.New(t.Out(i)).Elem()
+	}
+	fail := func(err error) []reflect.Value {
+		if !hasErr {
+			panic(err)
+		}
+		out[numOut].Set(reflect.ValueOf(&err).Elem())
+		return out
+	}
+
+	top := L.Gettop()
+	defer L.Settop(top)
+	L.Rawgeti(REGISTRYINDEX, Integer(ref))
+	for _, arg := range args {
+		L.pushValue(arg, 0)
+	}
+	if err := L.pcall(c.Int(len(args)), MULTRET); err != nil {
+		return fail(err)
+	}
+	nres := int(L.Gettop() - top)
+	if numOut == 1 && t.Out(0) == anySliceType {
+		// func(...any) ([]any, error) receives every result.
+		res := make([]any, nres)
+		for i := range res {
+			x, err := L.toAny(top+1+c.Int(i), 0)
+			if err != nil {
+				return fail(err)
+			}
+			res[i] = x
+		}
+		out[0].Set(reflect.ValueOf(res))
+		return out
+	}
+	for i := 0; i < numOut && i < nres; i++ {
+		if err := L.toValue(top+1+c.Int(i), out[i], 0); err != nil {
+			return fail(err)
+		}
+	}
+	return out
+}
+
+// -----------------------------------------------------------------------------
+
+// goFuncCall is the C function behind Go funcs pushed by PushGo. Its first
+// upvalue is the userdata referring to the func.
+func goFuncCall(L *State) c.Int {
+	return L.raise(L.protect(L.callGoFunc))
+}
+
+func (L *State) callGoFunc() c.Int {
+	fn, _ := L.toGoObject(Upvalueindex(1))
+	t := fn.Type()
+	nargs := int(L.Gettop())
+	numIn := t.NumIn()
+	in := make([]reflect.Value, 0, numIn)
+	for i := 0; i < numIn; i++ {
+		at := t.In(i)
+		if t.IsVariadic() && i == numIn-1 {
+			for j := i; j < nargs; j++ {
+				v := reflect.New(at.Elem()).Elem()
+				if err := L.toValue(c.Int(j+1), v, 0); err != nil {
+					L.pushErrorf("bad argument #%d (%v)", j+1, err)
+					return -1
+				}
+				in = append(in, v)
+			}
+			break
+		}
+		v := reflect.New(at).Elem()
+		if err := L.toValue(c.Int(i+1), v, 0); err != nil {
+			if i >= nargs {
+				L.pushErrorf("bad argument #%d (%v expected, got no value)", i+1, at)
+			} else {
+				L.pushErrorf("bad argument #%d (%v)", i+1, err)
+			}
+			return -1
+		}
+		in = append(in, v)
+	}
+
+	out := fn.Call(in)
+	if n := len(out); n > 0 && t.Out(n-1) == errorType {
+		if err := out[n-1]; !err.IsNil() {
+			L.pushErrorf("%s", err.Interface().(error).Error())
+			return -1
+		}
+		out = out[:n-1]
+	}
+	L.LCheckstack(c.Int(len(out)), nil)
+	for _, v := range out {
+		L.pushValue(v, 0)
+	}
+	return c.Int(len(out))
+}
+
+// -----------------------------------------------------------------------------
+
+func goObjectGC(L *State) c.Int {
+	if h := (*uintptr)(L.Testudata(1, goObjectMeta)); h != nil {
+		freeGoHandle(*h)
+		*h = 0
+	}
+	return 0
+}
+
+// goObjectIndex implements obj[key]: methods of the value, then fields of a
+// struct, elements of a slice or array (from 1) or entries of a map.
+func goObjectIndex(L *State) c.Int {
+	return L.raise(L.protect(func() c.Int {
+		obj, _ := L.toGoObject(1)
+		if L.Type(2) == STRING {
+			name := L.toGoString(2)
+			if m, ok := obj.Type().MethodByName(name); ok {
+				L.pushValue(m.Func, 0)
+				return 1
+			}
+		}
+		v := reflect.Indirect(obj)
+		switch v.Kind() {
+		case reflect.Struct:
+			if L.Type(2) == STRING {
+				if f, ok := fieldByName(v, L.toGoString(2)); ok {
+					if f.Kind() == reflect.Struct && f.CanAddr() {
+						// Index nested structs in place.
+						L.pushGoObject(f.Addr())
+					} else {
+						L.pushValue(f, 0)
+					}
+					return 1
+				}
+			}
+		case reflect.Slice, reflect.Array:
+			if i, ok := L.toInt64(2); ok && i >= 1 && i <= int64(v.Len()) {
+				L.pushValue(v.Index(int(i-1)), 0)
+				return 1
+			}
+		case reflect.Map:
+			key := reflect.New(v.Type().Key()).Elem()
+			if L.toValue(2, key, 0) == nil {
+				L.pushValue(v.MapIndex(key), 0)
+				return 1
+			}
+		}
+		L.Pushnil()
+		return 1
+	}))
+}
+
+// goObjectNewindex implements obj[key] = value for struct fields, slice and
+// array elements and map entries.
+func goObjectNewindex(L *State) c.Int {
+	return L.raise(L.protect(func() c.Int {
+		obj, _ := L.toGoObject(1)
+		v := reflect.Indirect(obj)
+		switch v.Kind() {
+		case reflect.Struct:
+			name := L.toGoString(2)
+			f, ok := fieldByName(v, name)
+			if !ok || !f.CanSet() {
+				L.pushErrorf("cannot set field %s of %v", name, v.Type())
+				return -1
+			}
+			if err := L.toValue(3, f, 0); err != nil {
+				L.pushErrorf("field %s: %v", name, err)
+				return -1
+			}
+		case reflect.Slice, reflect.Array:
+			i, ok := L.toInt64(2)
+			if !ok || i < 1 || i > int64(v.Len()) || !v.Index(int(i-1)).CanSet() {
+				L.pushErrorf("index out of range for %v", v.Type())
+				return -1
+			}
+			if err := L.toValue(3, v.Index(int(i-1)), 0); err != nil {
+				L.pushErrorf("%v", err)
+				return -1
+			}
+		case reflect.Map:
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := L.toValue(2, key, 0); err != nil {
+				L.pushErrorf("%v", err)
+				return -1
+			}
+			if L.Isnil(3) {
+				v.SetMapIndex(key, reflect.Value{})
+				return 0
+			}
+			val := reflect.New(v.Type().Elem()).Elem()
+			if err := L.toValue(3, val, 0); err != nil {
+				L.pushErrorf("%v", err)
+				return -1
+			}
+			v.SetMapIndex(key, val)
+		default:
+			L.pushErrorf("cannot index %v", obj.Type())
+			return -1
+		}
+		return 0
+	}))
+}
+
+func goObjectLen(L *State) c.Int {
+	return L.raise(L.protect(func() c.Int {
+		obj, _ := L.toGoObject(1)
+		v := reflect.Indirect(obj)
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.String, reflect.Chan:
+			L.Pushinteger(Integer(v.Len()))
+			return 1
+		}
+		L.pushErrorf("attempt to get length of %v", obj.Type())
+		return -1
+	}))
+}
+
+func goObjectEq(L *State) c.Int {
+	a, ok1 := L.toGoObject(1)
+	b, ok2 := L.toGoObject(2)
+	eq := ok1 && ok2 && a.Type() == b.Type()
+	if eq {
+		switch a.Kind() {
+		case reflect.Pointer, reflect.Chan, reflect.Map, reflect.UnsafePointer:
+			eq = a.Pointer() == b.Pointer()
+		default:
+			eq = a.CanInterface() && b.CanInterface() && a.Type().Comparable() && a.Interface() == b.Interface()
+		}
+	}
+	if eq {
+		L.Pushboolean(1)
+	} else {
+		L.Pushboolean(0)
+	}
+	return 1
+}
+
+func goObjectTostring(L *State) c.Int {
+	obj, _ := L.toGoObject(1)
+	if obj.IsValid() && obj.CanInterface() {
+		L.pushString(fmt.Sprintf("%v", obj.Interface()))
+	} else {
+		L.pushString(fmt.Sprintf("%v", obj.Type()))
+	}
+	return 1
+}
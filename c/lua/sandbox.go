@@ -0,0 +1,398 @@
+package lua
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/goplus/lib/c"
+)
+
+// Limit identifies a resource limit of a Sandbox.
+type Limit int
+
+const (
+	NoLimit          Limit = iota // no limit was reached
+	InstructionLimit              // SandboxOptions.MaxInstructions
+	MemoryLimit                   // SandboxOptions.MaxMemory
+	TimeLimit                     // SandboxOptions.Timeout
+)
+
+func (l Limit) String() string {
+	switch l {
+	case NoLimit:
+		return "no limit"
+	case InstructionLimit:
+		return "instruction limit"
+	case MemoryLimit:
+		return "memory limit"
+	case TimeLimit:
+		return "time limit"
+	}
+	return fmt.Sprintf("Limit(%d)", int(l))
+}
+
+// SafeLibs are the libraries a Sandbox opens by default: the base library
+// without its loading functions, and the libraries with no access to the
+// host.
+var SafeLibs = []string{GNAME, COLIBNAME, TABLIBNAME, STRLIBNAME, UTF8LIBNAME, MATHLIBNAME}
+
+// SandboxOptions configures a Sandbox.
+type SandboxOptions struct {
+	// Libs lists the standard libraries to open by name, "_G" being the base
+	// library. If nil, SafeLibs are opened. Even when listed, the os library
+	// only keeps clock, date, difftime and time, and the package library can
+	// only require preloaded modules. The debug library lets code remove the
+	// instruction and time limits and should not be listed for untrusted
+	// code.
+	Libs []string
+
+	// AllowLoad keeps load in the base library, restricted to text chunks.
+	// dofile and loadfile are always removed.
+	AllowLoad bool
+
+	// MaxInstructions is the number of VM instructions each run may execute,
+	// or 0 for no limit.
+	MaxInstructions int64
+
+	// MaxMemory is the number of bytes the state may allocate, or 0 for no
+	// limit.
+	MaxMemory uintptr
+
+	// Timeout is the wall-clock time each run may take, or 0 for no limit.
+	// It is checked between instructions: time spent inside a single call to
+	// a C or Go function is not interrupted.
+	Timeout time.Duration
+
+	// HookInterval is the number of instructions between two checks of the
+	// instruction and time limits. The default is 1000.
+	HookInterval c.Int
+}
+
+// Result is the outcome of running code in a Sandbox.
+type Result struct {
+	Values []any // the results of the code, if it succeeded
+	Err    error // nil, or the error that stopped the code
+	Limit  Limit // the limit that stopped the code, or NoLimit
+
+	Instructions int64         // instructions executed, in steps of HookInterval
+	Memory       uintptr       // bytes allocated by the state when the run ended
+	PeakMemory   uintptr       // the most bytes allocated during the run
+	Elapsed      time.Duration // wall-clock time of the run
+}
+
+// Sandbox runs untrusted code in a state of its own, with only the allowed
+// libraries opened and with limits on the instructions, memory and time it
+// may use. A Sandbox must not be used by several goroutines at once.
+type Sandbox struct {
+	vm      *VM
+	limits  *sandboxLimits
+	hooked  bool
+	timeout time.Duration
+}
+
+// sandboxLimits is the allocator's user data: it tracks memory use, and the
+// count hook finds it with Getallocf to check the other limits.
+type sandboxLimits struct {
+	used, peak uintptr
+	maxMemory  uintptr
+	enforce    bool
+
+	count    int64
+	maxCount int64
+	interval c.Int
+	deadline time.Time
+	tripped  Limit
+}
+
+func sandboxAlloc(ud c.Pointer, ptr c.Pointer, osize c.Ulong, nsize c.Ulong) c.Pointer {
+	l := (*sandboxLimits)(ud)
+	if ptr == nil {
+		// osize holds the type of the new object.
+		osize = 0
+	}
+	if nsize == 0 {
+		c.Free(ptr)
+		l.used -= uintptr(osize)
+		return nil
+	}
+	grow := uintptr(nsize) > uintptr(osize)
+	if grow && l.enforce && l.maxMemory > 0 && l.used-uintptr(osize)+uintptr(nsize) > l.maxMemory {
+		l.tripped = MemoryLimit
+		return nil
+	}
+	p := c.Realloc(ptr, uintptr(nsize))
+	if p == nil {
+		return nil
+	}
+	l.used = l.used - uintptr(osize) + uintptr(nsize)
+	if l.used > l.peak {
+		l.peak = l.used
+	}
+	return p
+}
+
+var (
+	errInstructionLimit = c.Str("instruction limit exceeded")
+	errTimeLimit        = c.Str("time limit exceeded")
+)
+
+// sandboxHook is the count hook checking the instruction and time limits.
+// Once a limit is reached it raises an error on every instruction, so that
+// the code cannot recover with pcall and go on.
+func sandboxHook(L *State, ar *Debug) {
+	var ud c.Pointer
+	L.Getallocf(&ud)
+	l := (*sandboxLimits)(ud)
+	l.count += int64(L.Gethookcount())
+	if l.tripped == NoLimit || l.tripped == MemoryLimit {
+		switch {
+		case l.maxCount > 0 && l.count > l.maxCount:
+			l.tripped = InstructionLimit
+		case !l.deadline.IsZero() && time.Now().After(l.deadline):
+			l.tripped = TimeLimit
+		default:
+			return
+		}
+	}
+	if L.Gethookcount() != 1 {
+		L.Sethook(sandboxHook, MASKCOUNT, 1)
+	}
+	if l.tripped == InstructionLimit {
+		L.Pushstring(errInstructionLimit)
+	} else {
+		L.Pushstring(errTimeLimit)
+	}
+	L.Error()
+}
+
+var sandboxOpeners = map[string]CFunction{
+	GNAME:       OpenBase,
+	COLIBNAME:   OpenCoroutine,
+	TABLIBNAME:  OpenTable,
+	IOLIBNAME:   OpenIo,
+	OSLIBNAME:   OpenOs,
+	STRLIBNAME:  OpenString,
+	UTF8LIBNAME: OpenUtf8,
+	MATHLIBNAME: OpenMath,
+	DBLIBNAME:   OpenDebug,
+	LOADLIBNAME: OpenPackage,
+}
+
+// NewSandbox creates a Sandbox configured by opts, which may be nil.
+func NewSandbox(opts *SandboxOptions) (*Sandbox, error) {
+	if opts == nil {
+		opts = new(SandboxOptions)
+	}
+	libs := opts.Libs
+	if libs == nil {
+		libs = SafeLibs
+	}
+	for _, name := range libs {
+		if _, ok := sandboxOpeners[name]; !ok {
+			return nil, fmt.Errorf("lua: unknown library %q", name)
+		}
+	}
+	l := &sandboxLimits{
+		maxMemory: opts.MaxMemory,
+		maxCount:  opts.MaxInstructions,
+		interval:  opts.HookInterval,
+	}
+	if l.interval <= 0 {
+		l.interval = 1000
+	}
+	L := Newstate__0(sandboxAlloc, c.Pointer(l))
+	if L == nil {
+		return nil, fmt.Errorf("lua: cannot create state")
+	}
+	for _, name := range libs {
+		L.Requiref(c.AllocaCStr(name), sandboxOpeners[name], 1)
+		switch name {
+		case GNAME:
+			restrictBase(L, opts.AllowLoad)
+		case OSLIBNAME:
+			restrictOs(L)
+		case LOADLIBNAME:
+			restrictPackage(L)
+		}
+		L.Pop(1)
+	}
+	s := &Sandbox{vm: &VM{L}, limits: l}
+	s.hooked = opts.MaxInstructions > 0 || opts.Timeout > 0
+	s.timeout = opts.Timeout
+	return s, nil
+}
+
+// clearFields sets the named fields of the table at the top of the stack to
+// nil.
+func clearFields(L *State, names ...string) {
+	for _, name := range names {
+		L.Pushnil()
+		L.Setfield(-2, c.AllocaCStr(name))
+	}
+}
+
+func restrictBase(L *State, allowLoad bool) {
+	clearFields(L, "dofile", "loadfile")
+	if !allowLoad {
+		clearFields(L, "load")
+		return
+	}
+	L.Getfield(-1, c.Str("load"))
+	L.Pushcclosure(textLoad, 1)
+	L.Setfield(-2, c.Str("load"))
+}
+
+// textLoad is load restricted to text chunks. Its upvalue is the original
+// load.
+func textLoad(L *State) c.Int {
+	if L.Gettop() < 3 {
+		L.Settop(3)
+	}
+	L.Pushstring(c.Str("t"))
+	L.Replace(3)
+	L.Pushvalue(Upvalueindex(1))
+	L.Insert(1)
+	L.Call(L.Gettop()-1, MULTRET)
+	return L.Gettop()
+}
+
+func restrictOs(L *State) {
+	clearFields(L, "execute", "exit", "getenv", "remove", "rename", "setlocale", "tmpname")
+}
+
+func restrictPackage(L *State) {
+	clearFields(L, "loadlib")
+	L.Pushstring(c.Str(""))
+	L.Setfield(-2, c.Str("path"))
+	L.Pushstring(c.Str(""))
+	L.Setfield(-2, c.Str("cpath"))
+	// Keep only the preload searcher.
+	L.Getfield(-1, c.Str("searchers"))
+	for i := L.Rawlen(-1); i > 1; i-- {
+		L.Pushnil()
+		L.Rawseti(-2, Integer(i))
+	}
+	L.Pop(1)
+}
+
+// VM returns the VM of the sandbox, to set globals or inspect the state.
+// Code run through it directly is not subject to the instruction and time
+// limits.
+func (s *Sandbox) VM() *VM {
+	return s.vm
+}
+
+// Close closes the state of the sandbox.
+func (s *Sandbox) Close() {
+	s.vm.Close()
+}
+
+// Run runs code, which must be text, and returns its results.
+func (s *Sandbox) Run(code string) *Result {
+	L := s.vm.State
+	return s.run(func() c.Int {
+		return L.Loadbufferx(c.GoStringData(code), uintptr(len(code)), c.AllocaCStr(chunkName(code)), c.Str("t"))
+	})
+}
+
+// Call calls the function fn, named as for VM.Call, with args and returns
+// its results. The function is looked up with raw accesses, and both the
+// lookup and the conversion of args happen in the protected call, under the
+// limits, so that neither a metamethod nor a memory error can escape it.
+func (s *Sandbox) Call(fn string, args ...any) *Result {
+	L := s.vm.State
+	return s.run(func() c.Int {
+		push := func() c.Int {
+			rawPushPath(L, fn)
+			L.LCheckstack(c.Int(len(args)), nil)
+			for _, arg := range args {
+				L.PushGo(arg)
+			}
+			return c.Int(len(args))
+		}
+		L.pushGoObject(reflect.ValueOf(push))
+		L.Pushcclosure(sandboxCall, 1)
+		return OK
+	})
+}
+
+// sandboxCall is the function called by Sandbox.Call. Its upvalue refers to
+// a func that pushes the function to call and its arguments, and returns
+// the number of arguments.
+func sandboxCall(L *State) c.Int {
+	push, _ := L.toGoObject(Upvalueindex(1))
+	nargs := L.raise(L.protect(push.Interface().(func() c.Int)))
+	L.Call(nargs, MULTRET)
+	return L.Gettop()
+}
+
+// rawPushPath pushes the value at path, as accepted by VM.Call, without
+// invoking metamethods.
+func rawPushPath(L *State, path string) {
+	L.Pushglobaltable()
+	for {
+		name, rest, more := strings.Cut(path, ".")
+		if L.Type(-1) != TABLE {
+			L.Pop(1)
+			L.Pushnil()
+			return
+		}
+		L.pushString(name)
+		L.Rawget(-2)
+		L.Remove(-2)
+		if !more {
+			return
+		}
+		path = rest
+	}
+}
+
+// run pushes a function with load, then calls it under the limits. The
+// memory limit is only enforced while Lua code runs, so that loading errors
+// and results can always be converted.
+func (s *Sandbox) run(load func() c.Int) *Result {
+	L, l := s.vm.State, s.limits
+	base := L.Gettop()
+	l.count, l.tripped, l.peak = 0, NoLimit, l.used
+	res := new(Result)
+	start := time.Now()
+	status := load()
+
+	if s.timeout > 0 {
+		l.deadline = start.Add(s.timeout)
+	}
+	if s.hooked {
+		L.Sethook(sandboxHook, MASKCOUNT, l.interval)
+	}
+	l.enforce = true
+	if status == OK {
+		status = L.pcallStatus(0, MULTRET)
+	}
+	l.enforce = false
+	// Leave the state free of limits for code run through VM.
+	tripped := l.tripped
+	if s.hooked {
+		L.Sethook(nil, 0, 0)
+	}
+	l.tripped, l.deadline = NoLimit, time.Time{}
+
+	res.Elapsed = time.Since(start)
+	res.Instructions = l.count
+	if status != OK {
+		res.Err = L.callError(status)
+		switch {
+		case tripped == MemoryLimit && (status == ERRMEM || status == ERRERR):
+			res.Limit = MemoryLimit
+		case tripped == InstructionLimit || tripped == TimeLimit:
+			res.Limit = tripped
+		}
+		L.Settop(base)
+		L.Gc(GCCOLLECT)
+	} else {
+		res.Values, res.Err = L.results(base)
+	}
+	res.Memory, res.PeakMemory = l.used, l.peak
+	return res
+}
@@ -0,0 +1,134 @@
+package sched
+
+import (
+	"errors"
+	"io"
+	"net"
+
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/c/libuv"
+	"github.com/goplus/lib/c/lua"
+	xio "github.com/goplus/lib/x/io"
+)
+
+// errNotPromise is returned by Await for an AsyncCall that is not an
+// *io.PromiseImpl: the Chan of the other implementations is nil.
+var errNotPromise = errors.New("async call is not an *io.PromiseImpl")
+
+// Await returns an AsyncFunc that calls start with the converted Lua
+// arguments and resumes the task with the value of the x/io AsyncCall it
+// returns, or with its error. The call must be an *io.PromiseImpl, as its
+// result cannot be received from the other AsyncCall implementations.
+func Await[T any](start func(args []any) (xio.AsyncCall[T], error)) AsyncFunc {
+	return func(L *lua.State, resolve Resolve) error {
+		args, err := Args(L)
+		if err != nil {
+			return err
+		}
+		call, err := start(args)
+		if err != nil {
+			return err
+		}
+		p, ok := call.(*xio.PromiseImpl[T])
+		if !ok {
+			return errNotPromise
+		}
+		go func() {
+			v := <-p.Chan()
+			if p.Err != nil {
+				resolve(nil, p.Err)
+				return
+			}
+			resolve([]any{v}, nil)
+		}()
+		return nil
+	}
+}
+
+// OpenLib sets the global "async" to a table of async functions:
+//
+//	async.sleep(ms)                  waits for ms milliseconds
+//	async.connect(network, address)  dials with libuv.Dial and returns a connection
+//	async.read(conn [, n])           reads up to n bytes (default 4096), nil at EOF
+//	async.write(conn, data)          writes data and returns true
+//	async.close(conn)                closes the connection and returns true
+//
+// Connections are Go net.Conn values; their methods must not be called
+// directly from Lua, as they block the loop.
+func (s *Scheduler) OpenLib() {
+	L := s.vm.State
+	L.Createtable(0, 5)
+	s.Push(s.Sleep)
+	L.Setfield(-2, c.Str("sleep"))
+	s.Push(Go(asyncConnect))
+	L.Setfield(-2, c.Str("connect"))
+	s.Push(Go(asyncRead))
+	L.Setfield(-2, c.Str("read"))
+	s.Push(Go(asyncWrite))
+	L.Setfield(-2, c.Str("write"))
+	s.Push(Go(asyncClose))
+	L.Setfield(-2, c.Str("close"))
+	L.Setglobal(c.Str("async"))
+}
+
+var errBadConn = errors.New("bad argument #1 (connection expected)")
+
+func asyncConnect(args []any) ([]any, error) {
+	network, _ := argAt(args, 0).(string)
+	address, _ := argAt(args, 1).(string)
+	conn, err := libuv.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return []any{conn}, nil
+}
+
+func asyncRead(args []any) ([]any, error) {
+	conn, ok := argAt(args, 0).(net.Conn)
+	if !ok {
+		return nil, errBadConn
+	}
+	n := 4096
+	if size, ok := argAt(args, 1).(int64); ok && size > 0 {
+		n = int(size)
+	}
+	buf := make([]byte, n)
+	n, err := conn.Read(buf)
+	if n > 0 {
+		return []any{buf[:n]}, nil
+	}
+	if err == io.EOF {
+		return []any{nil}, nil
+	}
+	return nil, err
+}
+
+func asyncWrite(args []any) ([]any, error) {
+	conn, ok := argAt(args, 0).(net.Conn)
+	if !ok {
+		return nil, errBadConn
+	}
+	data, _ := argAt(args, 1).(string)
+	if _, err := conn.Write([]byte(data)); err != nil {
+		return nil, err
+	}
+	return []any{true}, nil
+}
+
+func asyncClose(args []any) ([]any, error) {
+	conn, ok := argAt(args, 0).(net.Conn)
+	if !ok {
+		return nil, errBadConn
+	}
+	if err := conn.Close(); err != nil {
+		return nil, err
+	}
+	return []any{true}, nil
+}
+
+func argAt(args []any, i int) any {
+	if i < len(args) {
+		return args[i]
+	}
+	return nil
+}
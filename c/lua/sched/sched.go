@@ -0,0 +1,416 @@
+// Package sched runs Lua coroutines on a libuv loop and lets them wait for
+// asynchronous Go operations without blocking the host.
+//
+// A task is a coroutine started by Spawn or SpawnFunc. When it calls an
+// async function (one registered with Register, or one of the "async"
+// library opened by OpenLib), the function starts an operation and the task
+// yields; it is resumed with the results of the operation once they are
+// ready, so that Lua code reads sequentially:
+//
+//	async.sleep(100)
+//	local conn = async.connect("tcp", "example.com:80")
+//	async.write(conn, "GET / HTTP/1.0\r\n\r\n")
+//	local data = async.read(conn)
+//
+// Async functions return nil and an error message when the operation
+// fails. A task may also call coroutine.yield to let other tasks run.
+package sched
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/c/libuv"
+	"github.com/goplus/lib/c/lua"
+)
+
+// Resolve delivers the results of an asynchronous operation: the task
+// waiting for it receives results converted with PushGo, or nil and the
+// message of err. It may be called from any goroutine, and only once.
+type Resolve func(results []any, err error)
+
+// AsyncFunc starts an asynchronous operation for a call from Lua, whose
+// arguments are on the stack of L, and arranges for resolve to be called
+// when it completes. It must not touch L afterwards. If it returns an
+// error, no operation was started and the error is raised in Lua.
+type AsyncFunc func(L *lua.State, resolve Resolve) error
+
+// Scheduler runs Lua tasks on a libuv loop. All its methods, and the Lua
+// state, must be used from the goroutine running Run, or before Run is
+// called; only Resolve functions may be called from other goroutines.
+type Scheduler struct {
+	vm   *lua.VM
+	loop *libuv.Loop
+	own  bool
+	wake libuv.Async
+
+	mu    sync.Mutex
+	queue []func()
+
+	tasks   map[*lua.State]*Task
+	pending int
+	callRef c.Int // reference to the callPath chunk, 0 until loaded
+}
+
+// New creates a Scheduler for the tasks of vm, run on loop. If loop is nil
+// the Scheduler creates a loop of its own.
+func New(vm *lua.VM, loop *libuv.Loop) *Scheduler {
+	s := &Scheduler{vm: vm, loop: loop, tasks: make(map[*lua.State]*Task)}
+	if loop == nil {
+		s.loop = (*libuv.Loop)(c.Malloc(libuv.LoopSize()))
+		s.loop.Init()
+		s.own = true
+	}
+	s.loop.Async(&s.wake, onWakeup)
+	s.wake.Data = c.Pointer(s)
+	// The wakeup handle only keeps the loop alive while operations are
+	// pending, see hold.
+	s.wake.Unref()
+	return s
+}
+
+// VM returns the VM the tasks run in.
+func (s *Scheduler) VM() *lua.VM {
+	return s.vm
+}
+
+// Loop returns the loop the tasks run on.
+func (s *Scheduler) Loop() *libuv.Loop {
+	return s.loop
+}
+
+// Run runs the loop until all tasks are done and no other handles of the
+// loop are active.
+func (s *Scheduler) Run() {
+	s.loop.Run(libuv.RUN_DEFAULT)
+}
+
+// Close releases the loop resources of the Scheduler, and the loop itself
+// if it was created by New. It does not close the VM.
+func (s *Scheduler) Close() {
+	if s.callRef != 0 {
+		s.vm.Unref(lua.REGISTRYINDEX, s.callRef)
+		s.callRef = 0
+	}
+	s.wake.CloseFunc(nil)
+	s.loop.Run(libuv.RUN_DEFAULT)
+	if s.own {
+		s.loop.Close()
+		c.Free(c.Pointer(s.loop))
+	}
+}
+
+func onWakeup(a *libuv.Async) {
+	s := (*Scheduler)(a.Data)
+	s.mu.Lock()
+	queue := s.queue
+	s.queue = nil
+	s.mu.Unlock()
+	for _, fn := range queue {
+		fn()
+	}
+}
+
+// post runs fn on the loop thread.
+func (s *Scheduler) post(fn func()) {
+	s.mu.Lock()
+	s.queue = append(s.queue, fn)
+	s.mu.Unlock()
+	s.wake.Send()
+}
+
+// hold keeps the loop running until the matching release, for work that
+// is not tied to a libuv handle.
+func (s *Scheduler) hold() {
+	if s.pending++; s.pending == 1 {
+		s.wake.Ref()
+	}
+}
+
+func (s *Scheduler) release() {
+	if s.pending--; s.pending == 0 {
+		s.wake.Unref()
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// Task is a Lua coroutine run by a Scheduler.
+type Task struct {
+	s       *Scheduler
+	co      *lua.State
+	ref     c.Int
+	waiting bool
+
+	done   chan struct{}
+	values []any
+	err    error
+}
+
+// Spawn starts a task running the chunk code.
+func (s *Scheduler) Spawn(code string) (*Task, error) {
+	if err := s.vm.LoadString(code); err != nil {
+		return nil, err
+	}
+	t := s.newTask()
+	s.vm.Xmove(t.co, 1)
+	s.start(t, 0)
+	return t, nil
+}
+
+// callPath is the chunk SpawnFunc starts its tasks with: it looks up the
+// function inside the task, so that an error raised by a metamethod on the
+// way ends the task instead of escaping to the host.
+const callPath = `
+local path = ...
+local fn = _ENV
+for name in path:gmatch("[^.]+") do
+	fn = fn[name]
+end
+return fn(select(2, ...))
+`
+
+// SpawnFunc starts a task calling the function fn, named as for
+// lua.VM.Call, with args.
+func (s *Scheduler) SpawnFunc(fn string, args ...any) *Task {
+	L := s.vm.State
+	if s.callRef == 0 {
+		if err := s.vm.LoadString(callPath); err != nil {
+			panic(err)
+		}
+		s.callRef = L.Ref(lua.REGISTRYINDEX)
+	}
+	t := s.newTask()
+	co := t.co
+	co.Rawgeti(lua.REGISTRYINDEX, lua.Integer(s.callRef))
+	co.LCheckstack(c.Int(len(args))+1, nil)
+	co.PushGo(fn)
+	for _, arg := range args {
+		co.PushGo(arg)
+	}
+	s.start(t, c.Int(len(args))+1)
+	return t
+}
+
+func (s *Scheduler) newTask() *Task {
+	L := s.vm.State
+	co := L.Newthread()
+	t := &Task{s: s, co: co, ref: L.Ref(lua.REGISTRYINDEX), done: make(chan struct{})}
+	s.tasks[co] = t
+	return t
+}
+
+// start runs the first step of t on the loop.
+func (s *Scheduler) start(t *Task, nargs c.Int) {
+	s.hold()
+	s.post(func() {
+		s.release()
+		t.step(nargs)
+	})
+}
+
+// Done returns a channel closed when the task ends.
+func (t *Task) Done() <-chan struct{} {
+	return t.done
+}
+
+// Result returns the values returned by the task, or the error that ended
+// it. It must only be called once the task is done.
+func (t *Task) Result() ([]any, error) {
+	return t.values, t.err
+}
+
+// resolver returns the Resolve function resuming t. The results are only
+// delivered once *started is set, when the operation was started and the
+// loop held for it; otherwise they are dropped.
+func (t *Task) resolver(started *bool) Resolve {
+	var once sync.Once
+	return func(results []any, err error) {
+		once.Do(func() {
+			t.s.post(func() {
+				if !*started {
+					return
+				}
+				t.s.release()
+				t.resume(results, err)
+			})
+		})
+	}
+}
+
+func (t *Task) resume(results []any, err error) {
+	co := t.co
+	if err != nil {
+		co.Pushnil()
+		co.PushGo(err.Error())
+		t.step(2)
+		return
+	}
+	co.LCheckstack(c.Int(len(results)), nil)
+	for _, v := range results {
+		co.PushGo(v)
+	}
+	t.step(c.Int(len(results)))
+}
+
+// step resumes the coroutine of t with the nargs values on its stack.
+func (t *Task) step(nargs c.Int) {
+	s, co := t.s, t.co
+	var nres c.Int
+	status := co.Resume(s.vm.State, nargs, &nres)
+	switch status {
+	case lua.YIELD:
+		if t.waiting {
+			// Yielded by an async function: its Resolve resumes the task.
+			t.waiting = false
+			return
+		}
+		// A plain coroutine.yield: run again after the other work queued.
+		co.Pop(nres)
+		s.start(t, 0)
+	case lua.OK:
+		values := make([]any, nres)
+		for i := range values {
+			co.ToGo(c.Int(i)-nres, &values[i])
+		}
+		co.Pop(nres)
+		t.finish(values, nil)
+	default:
+		t.finish(nil, t.resumeError(status))
+	}
+}
+
+// resumeError converts the error of the coroutine. A Go panic raised again
+// by ResumeError is reported as an error, as there is no Go caller left to
+// receive it on the loop.
+func (t *Task) resumeError(status c.Int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic in Go function: %v", r)
+		}
+	}()
+	return t.co.ResumeError(status)
+}
+
+func (t *Task) finish(values []any, err error) {
+	s := t.s
+	delete(s.tasks, t.co)
+	s.vm.Unref(lua.REGISTRYINDEX, t.ref)
+	t.values, t.err = values, err
+	close(t.done)
+}
+
+// -----------------------------------------------------------------------------
+
+// asyncFunc is the upvalue of the Lua functions pushed by Push. It is
+// released with the function by the garbage collector.
+type asyncFunc struct {
+	s  *Scheduler
+	fn AsyncFunc
+}
+
+// Push pushes fn onto the stack of the Scheduler's VM as a Lua function.
+func (s *Scheduler) Push(fn AsyncFunc) {
+	L := s.vm.State
+	L.PushGo(&asyncFunc{s, fn})
+	L.Pushcclosure(callAsync, 1)
+}
+
+// Register sets the global name to fn.
+func (s *Scheduler) Register(name string, fn AsyncFunc) {
+	s.Push(fn)
+	s.vm.Setglobal(c.AllocaCStr(name))
+}
+
+// callAsync is the C function behind async functions. Yielding unwinds the
+// C stack, so the Go work is done by startAsync, which returns before.
+func callAsync(L *lua.State) c.Int {
+	if !startAsync(L) {
+		return L.Error()
+	}
+	return L.Yield(0)
+}
+
+func startAsync(L *lua.State) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			pushError(L, fmt.Sprint(r))
+			ok = false
+		}
+	}()
+	var f *asyncFunc
+	L.ToGo(lua.Upvalueindex(1), &f)
+	t := f.s.tasks[L]
+	if t == nil {
+		pushError(L, "async function called outside of a task")
+		return false
+	}
+	started := false
+	if err := f.fn(L, t.resolver(&started)); err != nil {
+		pushError(L, err.Error())
+		return false
+	}
+	L.Settop(0)
+	t.waiting = true
+	started = true
+	f.s.hold()
+	return true
+}
+
+// pushError pushes msg prefixed with the current position, like
+// luaL_error, without raising it.
+func pushError(L *lua.State, msg string) {
+	L.Where(1)
+	L.PushGo(msg)
+	L.Concat(2)
+}
+
+// Args converts the arguments of a call from Lua, as by lua.State.ToGo into
+// interface values.
+func Args(L *lua.State) ([]any, error) {
+	args := make([]any, L.Gettop())
+	for i := range args {
+		if err := L.ToGo(c.Int(i+1), &args[i]); err != nil {
+			return nil, fmt.Errorf("bad argument #%d (%v)", i+1, err)
+		}
+	}
+	return args, nil
+}
+
+// Go returns an AsyncFunc that runs fn with the converted Lua arguments on
+// a new goroutine. It lets Lua call blocking Go code without blocking the
+// loop.
+func Go(fn func(args []any) ([]any, error)) AsyncFunc {
+	return func(L *lua.State, resolve Resolve) error {
+		args, err := Args(L)
+		if err != nil {
+			return err
+		}
+		go func() {
+			resolve(fn(args))
+		}()
+		return nil
+	}
+}
+
+// Sleep is an AsyncFunc that waits for a number of milliseconds on a
+// libuv timer of the Scheduler's loop.
+func (s *Scheduler) Sleep(L *lua.State, resolve Resolve) error {
+	var ms float64
+	if err := L.ToGo(1, &ms); err != nil || ms < 0 {
+		return fmt.Errorf("bad argument #1 to 'sleep' (non-negative number expected)")
+	}
+	timer := new(libuv.Timer)
+	libuv.InitTimer(s.loop, timer)
+	handle := (*libuv.Handle)(unsafe.Pointer(timer))
+	err := timer.StartFunc(uint64(ms), 0, func() {
+		handle.CloseFunc(nil)
+		resolve(nil, nil)
+	})
+	if err != nil {
+		handle.CloseFunc(nil)
+	}
+	return err
+}
@@ -0,0 +1,302 @@
+package lua
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/goplus/lib/c"
+)
+
+// Error is an error raised while loading or running Lua code.
+type Error struct {
+	// Status is the status code of the failed call: ERRRUN, ERRSYNTAX,
+	// ERRMEM, ERRERR or ERRFILE.
+	Status c.Int
+
+	// Message is the error message, or the result of tostring on the error
+	// object when it is not a string.
+	Message string
+
+	// Traceback is the stack traceback, as produced by luaL_traceback, at
+	// the point where a runtime error was raised. Syntax, file and memory
+	// errors have no traceback.
+	Traceback string
+
+	// Value is the error object converted as by ToGo into an interface.
+	Value any
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// StatusString returns the name of a thread status code.
+func StatusString(status c.Int) string {
+	switch status {
+	case OK:
+		return "OK"
+	case YIELD:
+		return "YIELD"
+	case ERRRUN:
+		return "ERRRUN"
+	case ERRSYNTAX:
+		return "ERRSYNTAX"
+	case ERRMEM:
+		return "ERRMEM"
+	case ERRERR:
+		return "ERRERR"
+	case ERRFILE:
+		return "ERRFILE"
+	}
+	return fmt.Sprintf("status(%d)", status)
+}
+
+// -----------------------------------------------------------------------------
+
+var tracebackKey = c.Str("lua.traceback")
+
+// errorHandler is the message handler of protected calls made from Go. It
+// leaves the error object unchanged and saves a traceback in the registry.
+func errorHandler(L *State) c.Int {
+	L.Traceback(L, nil, 1)
+	L.Setfield(REGISTRYINDEX, tracebackKey)
+	return 1
+}
+
+// goPanics records, for each state, the last Go panic turned into a Lua
+// error by protect, so that it can be raised again once the error gets back
+// to Go without having been caught by Lua code.
+var goPanics sync.Map // *State (main thread) -> *goPanic
+
+type goPanic struct {
+	msg   string
+	value any
+}
+
+func (L *State) savePanic(value any) {
+	goPanics.Store(L.mainThread(), &goPanic{msg: L.toGoString(-1), value: value})
+}
+
+// pcall calls a function in protected mode with a message handler that
+// collects a traceback, like Pcall(nargs, nresults, msgh). It returns nil or
+// an *Error built from the error object, which is removed from the stack.
+//
+// A Go panic that was turned into a Lua error inside a Go func called by
+// this function, and that Lua code did not catch, is raised again.
+func (L *State) pcall(nargs, nresults c.Int) error {
+	return L.callError(L.pcallStatus(nargs, nresults))
+}
+
+// pcallStatus is the first half of pcall: it makes the call and returns its
+// status, leaving the error object on the stack.
+func (L *State) pcallStatus(nargs, nresults c.Int) c.Int {
+	base := L.Gettop() - nargs
+	L.Pushcfunction(errorHandler)
+	L.Insert(base)
+	status := L.Pcall(nargs, nresults, base)
+	L.Remove(base)
+	return status
+}
+
+// callError is the second half of pcall: it converts the error object left
+// by a call that returned status.
+func (L *State) callError(status c.Int) error {
+	p, _ := goPanics.LoadAndDelete(L.mainThread())
+	if status == OK {
+		return nil
+	}
+	err := L.newError(status)
+	if p, ok := p.(*goPanic); ok && p.msg == err.Message {
+		panic(p.value)
+	}
+	return err
+}
+
+// ResumeError converts the error left on the coroutine L by a Resume that
+// returned status, with the traceback of the coroutine, and pops it. It
+// returns nil if status is OK or YIELD. Like the calls of VM, it raises
+// again a Go panic that was turned into the error.
+func (L *State) ResumeError(status c.Int) error {
+	if status == OK || status == YIELD {
+		return nil
+	}
+	L.Traceback(L, nil, 0)
+	traceback := L.toGoString(-1)
+	L.Pop(1)
+	err := L.callError(status)
+	err.(*Error).Traceback = traceback
+	return err
+}
+
+// newError pops the error object at the top of the stack and returns it as
+// an *Error.
+func (L *State) newError(status c.Int) *Error {
+	err := &Error{Status: status}
+	err.Value, _ = L.toAny(-1, 0)
+	if L.Type(-1) == STRING {
+		err.Message = L.toGoString(-1)
+	} else {
+		L.LTolstring(-1, nil)
+		err.Message = L.toGoString(-1)
+		L.Pop(1)
+	}
+	L.Pop(1)
+	if L.Getfield(REGISTRYINDEX, tracebackKey) == STRING {
+		err.Traceback = L.toGoString(-1)
+	}
+	L.Pop(1)
+	L.Pushnil()
+	L.Setfield(REGISTRYINDEX, tracebackKey)
+	return err
+}
+
+// loadError pops the error object left by a failed load.
+func (L *State) loadError(status c.Int) error {
+	if status == OK {
+		return nil
+	}
+	return L.newError(status)
+}
+
+// -----------------------------------------------------------------------------
+
+// VM wraps a State with a Go API that converts arguments and results with
+// PushGo and ToGo and reports failures as *Error values instead of status
+// codes.
+//
+// Go panics in Go funcs called from Lua are raised as Lua errors, which Lua
+// code can catch with pcall. If the error reaches the VM call uncaught, the
+// original panic is raised again in Go. Conversely, a Lua error in a Lua
+// function called through a Go func value without an error result panics
+// with an *Error, which becomes a Lua error again if it crosses back into
+// Lua.
+type VM struct {
+	*State
+}
+
+// NewVM creates a new state with all standard libraries opened.
+func NewVM() *VM {
+	L := Newstate__1()
+	L.Openlibs()
+	return &VM{L}
+}
+
+// chunkName returns the chunk name used for a string chunk: like
+// luaL_loadstring, it is the source itself, but only its first line, and at
+// most IDSIZE bytes of it, are kept since that is all luaO_chunkid shows.
+// This keeps the name small enough to be allocated on the stack.
+func chunkName(code string) string {
+	if i := strings.IndexByte(code, '\n'); i >= 0 {
+		code = code[:i+1]
+	}
+	if len(code) > IDSIZE {
+		code = code[:IDSIZE]
+	}
+	return code
+}
+
+// LoadString loads code as a Lua chunk and pushes it onto the stack as a
+// function without running it. The chunk may be text or binary.
+func (vm *VM) LoadString(code string) error {
+	L := vm.State
+	return L.loadError(L.Loadbufferx(c.GoStringData(code), uintptr(len(code)), c.AllocaCStr(chunkName(code)), nil))
+}
+
+// LoadFile loads the file name as a Lua chunk and pushes it onto the stack
+// as a function without running it.
+func (vm *VM) LoadFile(name string) error {
+	L := vm.State
+	return L.loadError(L.Loadfilex(c.AllocaCStr(name), nil))
+}
+
+// DoString runs code and returns its results.
+func (vm *VM) DoString(code string) ([]any, error) {
+	if err := vm.LoadString(code); err != nil {
+		return nil, err
+	}
+	return vm.call(0)
+}
+
+// DoFile runs the file name and returns its results.
+func (vm *VM) DoFile(name string) ([]any, error) {
+	if err := vm.LoadFile(name); err != nil {
+		return nil, err
+	}
+	return vm.call(0)
+}
+
+// Call calls the function fn with args converted by PushGo and returns its
+// results. fn names a global, or a field of a global table such as
+// "string.format".
+func (vm *VM) Call(fn string, args ...any) ([]any, error) {
+	L := vm.State
+	vm.pushPath(fn)
+	L.LCheckstack(c.Int(len(args)), nil)
+	for _, arg := range args {
+		L.PushGo(arg)
+	}
+	return vm.call(c.Int(len(args)))
+}
+
+// call calls the function below the nargs arguments at the top of the stack
+// and returns all of its results.
+func (vm *VM) call(nargs c.Int) ([]any, error) {
+	L := vm.State
+	base := L.Gettop() - nargs - 1
+	if err := L.pcall(nargs, MULTRET); err != nil {
+		return nil, err
+	}
+	return L.results(base)
+}
+
+// results converts the values above base and pops them.
+func (L *State) results(base c.Int) ([]any, error) {
+	defer L.Settop(base)
+	n := L.Gettop() - base
+	if n == 0 {
+		return nil, nil
+	}
+	res := make([]any, n)
+	for i := range res {
+		v, err := L.toAny(base+1+c.Int(i), 0)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+// pushPath pushes the value of a global, or of a dotted path of fields
+// starting at a global.
+func (vm *VM) pushPath(path string) {
+	L := vm.State
+	name, rest, more := strings.Cut(path, ".")
+	L.Getglobal(c.AllocaCStr(name))
+	for more {
+		name, rest, more = strings.Cut(rest, ".")
+		if L.Type(-1) != TABLE {
+			L.Pop(1)
+			L.Pushnil()
+			return
+		}
+		L.pushString(name)
+		L.Gettable(-2)
+		L.Remove(-2)
+	}
+}
+
+// SetGlobal sets the global name to v converted by PushGo.
+func (vm *VM) SetGlobal(name string, v any) {
+	vm.PushGo(v)
+	vm.Setglobal(c.AllocaCStr(name))
+}
+
+// GetGlobal converts the value at path, as accepted by Call, with ToGo.
+func (vm *VM) GetGlobal(path string, ptr any) error {
+	L := vm.State
+	vm.pushPath(path)
+	defer L.Pop(1)
+	return L.ToGo(-1, ptr)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/goplus/lib/c/openssl"
+)
+
+func main() {
+	p, err := openssl.GeneratePrime(256, false)
+	if err != nil {
+		panic(err)
+	}
+	isPrime, err := p.ProbablyPrime()
+	fmt.Println("prime:", p, isPrime, err)
+
+	x, err := openssl.FromBig(new(big.Int).Lsh(big.NewInt(1), 200))
+	if err != nil {
+		panic(err)
+	}
+	y := openssl.NewInt(65537)
+
+	r1, err := x.ModExp(y, p)
+	if err != nil {
+		panic(err)
+	}
+	mc, err := openssl.NewMontContext(p)
+	if err != nil {
+		panic(err)
+	}
+	r2, err := mc.ModExp(x, y)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("mod exp:", r1.Cmp(r2) == 0, r1.Hex())
+
+	want := new(big.Int).Exp(x.Big(), y.Big(), p.Big())
+	fmt.Println("math/big agrees:", want.Cmp(r1.Big()) == 0)
+
+	inv, err := x.ModInverse(p)
+	if err != nil {
+		panic(err)
+	}
+	one, _ := inv.ModMul(x, p)
+	fmt.Println("x * x^-1 mod p =", one)
+
+	if _, err = x.Quo(openssl.NewInt(0)); err != nil {
+		fmt.Println("division by zero:", err)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/goplus/lib/c/openssl"
+)
+
+func main() {
+	key := make([]byte, 32)
+	openssl.RANDBytes(key)
+
+	aead, err := openssl.NewGCM(key)
+	if err != nil {
+		panic(err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	openssl.RANDBytes(nonce)
+
+	sealed := aead.Seal(nil, nonce, []byte("Hello, World!"), []byte("header"))
+	fmt.Printf("AES-256-GCM: %x\n", sealed)
+
+	opened, err := aead.Open(nil, nonce, sealed, []byte("header"))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Opened: %s\n", opened)
+
+	iv := make([]byte, 16)
+	stream, err := openssl.NewCTR(key, iv)
+	if err != nil {
+		panic(err)
+	}
+	buf := []byte("Hello, World!")
+	stream.XORKeyStream(buf, buf)
+	fmt.Printf("AES-256-CTR: %x\n", buf)
+}
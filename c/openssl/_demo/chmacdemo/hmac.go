@@ -26,9 +26,8 @@ func main() {
 		c.Fprintf(c.Stderr, c.Str("%s\n"), c.Str("Error initializing HMAC_CTX"))
 		return
 	}
-	ret = ctx.UpdateString(str)
-	if ret == 0 {
-		c.Fprintf(c.Stderr, c.Str("%s\n"), c.Str("Error updating HMAC_CTX"))
+	if err := ctx.UpdateString(str); err != nil {
+		fmt.Println("Error updating HMAC_CTX:", err)
 		return
 	}
 	ret = ctx.Final(unsafe.SliceData(digest), &digestLen)
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"crypto"
+	"fmt"
+
+	"github.com/goplus/lib/c/openssl"
+)
+
+func main() {
+	password := []byte("correct horse battery staple")
+	salt := []byte("NaCl")
+
+	key, err := openssl.HKDF(crypto.SHA256, []byte("input keying material"), salt, []byte("demo"), 32)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("HKDF:     %x\n", key)
+
+	key, err = openssl.PBKDF2(password, salt, 4096, 32, crypto.SHA256)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("PBKDF2:   %x\n", key)
+
+	key, err = openssl.Scrypt(password, salt, 1<<14, 8, 1, 32)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("scrypt:   %x\n", key)
+
+	key2, err := openssl.Argon2id(password, salt, 1, 64*1024, 4, 32)
+	if err != nil {
+		fmt.Println("Argon2id:", err)
+	} else {
+		fmt.Printf("Argon2id: %x\n", key2)
+	}
+
+	fmt.Println("equal:", openssl.ConstantTimeEqual(key, key), openssl.ConstantTimeEqual(key, key2))
+}
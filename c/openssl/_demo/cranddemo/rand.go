@@ -9,9 +9,18 @@ import (
 func main() {
 	b := make([]byte, 10)
 
-	openssl.RANDBytes(b)
+	if err := openssl.RANDBytes(b); err != nil {
+		panic(err)
+	}
 	fmt.Printf("%x\n", b)
 
-	openssl.RANDPrivBytes(b)
+	if err := openssl.RANDPrivBytes(b); err != nil {
+		panic(err)
+	}
 	fmt.Printf("%x\n", b)
+
+	// Failures carry the drained OpenSSL error queue.
+	if _, err := openssl.ParsePrivateKeyPEM([]byte("not a key")); err != nil {
+		fmt.Println(err)
+	}
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/goplus/lib/c/openssl"
+)
+
+func main() {
+	msg := []byte("Hello, World!")
+	digest := sha256.Sum256(msg)
+
+	ecKey, err := openssl.GenerateECDSAKey(elliptic.P256())
+	if err != nil {
+		panic(err)
+	}
+	sig, err := ecKey.Sign(nil, digest[:], crypto.SHA256)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("ECDSA signature: %x\n", sig)
+	fmt.Println("ECDSA verify:", ecKey.PublicKey().Verify(digest[:], sig, crypto.SHA256))
+
+	edKey, err := openssl.GenerateEd25519Key()
+	if err != nil {
+		panic(err)
+	}
+	sig, err = edKey.Sign(nil, msg, crypto.Hash(0))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Ed25519 signature: %x\n", sig)
+	fmt.Println("Ed25519 verify:", edKey.PublicKey().Verify(msg, sig, crypto.Hash(0)))
+
+	pem, err := edKey.PublicKey().MarshalPEM()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s", pem)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+
+	"github.com/goplus/lib/c/openssl"
+)
+
+func main() {
+	raw, err := net.Dial("tcp", "example.com:443")
+	if err != nil {
+		panic(err)
+	}
+	conn, err := openssl.Client(raw, &openssl.Config{
+		ServerName: "example.com",
+		NextProtos: []string{"http/1.1"},
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	if err = conn.Handshake(); err != nil {
+		panic(err)
+	}
+	state := conn.ConnectionState()
+	fmt.Printf("version: %#x, alpn: %q\n", state.Version, state.NegotiatedProtocol)
+
+	fmt.Fprintf(conn, "GET / HTTP/1.1\r\nHost: example.com\r\nConnection: close\r\n\r\n")
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(line)
+}
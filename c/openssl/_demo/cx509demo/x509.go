@@ -0,0 +1,86 @@
+package main
+
+import (
+	"crypto/elliptic"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/goplus/lib/c/openssl"
+)
+
+func main() {
+	caKey, err := openssl.GenerateECDSAKey(elliptic.P256())
+	if err != nil {
+		panic(err)
+	}
+	now := time.Now()
+	ca, err := openssl.CreateCertificate(&x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Demo Root CA", Organization: []string{"GoPlus"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil, caKey.PublicKey(), caKey)
+	if err != nil {
+		panic(err)
+	}
+
+	leafKey, err := openssl.GenerateEd25519Key()
+	if err != nil {
+		panic(err)
+	}
+	csr, err := openssl.CreateCertificateRequest(&x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "demo.example.com"},
+		DNSNames: []string{"demo.example.com", "www.example.com"},
+	}, leafKey)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("CSR signature:", csr.CheckSignature())
+
+	csrPub, err := csr.PublicKey()
+	if err != nil {
+		panic(err)
+	}
+	leaf, err := openssl.CreateCertificate(&x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               csr.Subject(),
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              csr.DNSNames(),
+	}, ca, csrPub, caKey)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("subject:", leaf.Subject())
+	fmt.Println("issuer:", leaf.Issuer())
+	fmt.Println("serial:", leaf.SerialNumber())
+	fmt.Println("DNS names:", leaf.DNSNames())
+	fmt.Println("valid:", leaf.NotBefore(), "-", leaf.NotAfter())
+
+	roots := openssl.NewCertPool()
+	if err = roots.AddCert(ca); err != nil {
+		panic(err)
+	}
+	chain, err := leaf.Verify(openssl.VerifyOptions{Roots: roots, DNSName: "www.example.com"})
+	fmt.Println("verify:", len(chain), err)
+
+	_, err = leaf.Verify(openssl.VerifyOptions{Roots: roots, CurrentTime: now.Add(48 * time.Hour)})
+	if verr, ok := err.(*openssl.VerifyError); ok {
+		fmt.Println("expired:", verr.Code, verr.Depth, verr.Reason)
+	}
+
+	std, err := leaf.X509Certificate()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("crypto/x509:", std.Subject, std.DNSNames)
+}
@@ -3,3 +3,7 @@
 void opensslFree(void *ptr) {
     OPENSSL_free(ptr);
 }
+
+void *opensslMalloc(size_t num) {
+    return OPENSSL_malloc(num);
+}
@@ -0,0 +1,203 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openssl
+
+import (
+	"crypto/cipher"
+	"errors"
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+// -----------------------------------------------------------------------------
+
+const (
+	gcmStandardNonceSize = 12
+	gcmTagSize           = 16
+
+	chacha20Poly1305KeySize   = 32
+	chacha20Poly1305NonceSize = 12
+	chacha20Poly1305TagSize   = 16
+)
+
+var errOpen = errors.New("cipher: message authentication failed")
+
+type evpAEAD struct {
+	typ       *EVP_CIPHER
+	key       []byte
+	nonceSize int
+	tagSize   int
+}
+
+// NewGCM returns AES in Galois Counter Mode with the standard nonce
+// length, implemented by OpenSSL. The key selects AES-128, AES-192, or
+// AES-256.
+func NewGCM(key []byte) (cipher.AEAD, error) {
+	typ, err := aesGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return newEvpAEAD(typ, key, gcmStandardNonceSize, gcmTagSize), nil
+}
+
+// NewChaCha20Poly1305 returns a ChaCha20-Poly1305 AEAD (RFC 8439) that uses
+// the given 256-bit key, implemented by OpenSSL.
+func NewChaCha20Poly1305(key []byte) (cipher.AEAD, error) {
+	if len(key) != chacha20Poly1305KeySize {
+		return nil, errors.New("chacha20poly1305: bad key length")
+	}
+	return newEvpAEAD(EVP_chacha20_poly1305(), key,
+		chacha20Poly1305NonceSize, chacha20Poly1305TagSize), nil
+}
+
+func newEvpAEAD(typ *EVP_CIPHER, key []byte, nonceSize, tagSize int) *evpAEAD {
+	return &evpAEAD{
+		typ:       typ,
+		key:       append([]byte(nil), key...),
+		nonceSize: nonceSize,
+		tagSize:   tagSize,
+	}
+}
+
+func (a *evpAEAD) NonceSize() int { return a.nonceSize }
+
+func (a *evpAEAD) Overhead() int { return a.tagSize }
+
+func (a *evpAEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != a.nonceSize {
+		panic("crypto/cipher: incorrect nonce length given to AEAD")
+	}
+	ret, out := sliceForAppend(dst, len(plaintext)+a.tagSize)
+
+	ctx := NewEVP_CIPHER_CTX()
+	if ctx == nil {
+		panic("openssl: EVP_CIPHER_CTX_new failed")
+	}
+	defer ctx.Free()
+
+	if !a.init(ctx, nonce, 1) {
+		panic("openssl: EVP_EncryptInit_ex failed")
+	}
+	if !cipherUpdate(ctx, nil, additionalData) || !cipherUpdate(ctx, out, plaintext) {
+		panic("openssl: EVP_EncryptUpdate failed")
+	}
+	var final [EVP_MAX_BLOCK_LENGTH]byte
+	var outl c.Int
+	if ctx.EncryptFinalEx(&final[0], &outl) != 1 {
+		panic("openssl: EVP_EncryptFinal_ex failed")
+	}
+	tag := out[len(plaintext):]
+	if ctx.Ctrl(EVP_CTRL_AEAD_GET_TAG, c.Int(a.tagSize), unsafe.Pointer(unsafe.SliceData(tag))) != 1 {
+		panic("openssl: EVP_CTRL_AEAD_GET_TAG failed")
+	}
+	return ret
+}
+
+func (a *evpAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != a.nonceSize {
+		panic("crypto/cipher: incorrect nonce length given to AEAD")
+	}
+	if len(ciphertext) < a.tagSize {
+		return nil, errOpen
+	}
+	tag := ciphertext[len(ciphertext)-a.tagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-a.tagSize]
+	ret, out := sliceForAppend(dst, len(ciphertext))
+
+	ctx := NewEVP_CIPHER_CTX()
+	if ctx == nil {
+		return nil, NewError("EVP_CIPHER_CTX_new")
+	}
+	defer ctx.Free()
+
+	if !a.init(ctx, nonce, 0) {
+		return nil, NewError("EVP_DecryptInit_ex")
+	}
+	// The tag is copied into the context before decryption, so out may
+	// safely overlap ciphertext.
+	if ctx.Ctrl(EVP_CTRL_AEAD_SET_TAG, c.Int(a.tagSize), unsafe.Pointer(unsafe.SliceData(tag))) != 1 {
+		return nil, NewError("EVP_CTRL_AEAD_SET_TAG")
+	}
+	if !cipherUpdate(ctx, nil, additionalData) || !cipherUpdate(ctx, out, ciphertext) {
+		zero(out)
+		ERRClearError()
+		return nil, errOpen
+	}
+	var final [EVP_MAX_BLOCK_LENGTH]byte
+	var outl c.Int
+	if ctx.DecryptFinalEx(&final[0], &outl) != 1 {
+		zero(out)
+		ERRClearError()
+		return nil, errOpen
+	}
+	return ret, nil
+}
+
+// init prepares ctx for a single AEAD operation: enc is 1 to seal and 0 to
+// open.
+func (a *evpAEAD) init(ctx *EVP_CIPHER_CTX, nonce []byte, enc c.Int) bool {
+	if ctx.CipherInitEx(a.typ, nil, nil, nil, enc) != 1 {
+		return false
+	}
+	if ctx.Ctrl(EVP_CTRL_AEAD_SET_IVLEN, c.Int(len(nonce)), nil) != 1 {
+		return false
+	}
+	return ctx.CipherInitEx(nil, nil, unsafe.SliceData(a.key), unsafe.SliceData(nonce), enc) == 1
+}
+
+// cipherUpdate feeds in to ctx, writing output to out. A nil out feeds in as
+// additional authenticated data.
+func cipherUpdate(ctx *EVP_CIPHER_CTX, out, in []byte) bool {
+	for len(in) > 0 {
+		n := len(in)
+		if n > maxUpdateLen {
+			n = maxUpdateLen
+		}
+		var outl c.Int
+		if ctx.CipherUpdate(bytesData(out), &outl, unsafe.SliceData(in), c.Int(n)) != 1 {
+			return false
+		}
+		if out != nil {
+			out = out[n:]
+		}
+		in = in[n:]
+	}
+	return true
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// sliceForAppend takes a slice and a requested number of bytes. It returns a
+// slice with the contents of the given slice followed by that many bytes and a
+// second slice that aliases into it and contains only the extra bytes.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,207 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openssl
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"runtime"
+	"sync"
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+// -----------------------------------------------------------------------------
+
+func aesECB(key []byte) (*EVP_CIPHER, error) {
+	switch len(key) {
+	case 16:
+		return EVP_aes_128_ecb(), nil
+	case 24:
+		return EVP_aes_192_ecb(), nil
+	case 32:
+		return EVP_aes_256_ecb(), nil
+	}
+	return nil, aes.KeySizeError(len(key))
+}
+
+func aesCTR(key []byte) (*EVP_CIPHER, error) {
+	switch len(key) {
+	case 16:
+		return EVP_aes_128_ctr(), nil
+	case 24:
+		return EVP_aes_192_ctr(), nil
+	case 32:
+		return EVP_aes_256_ctr(), nil
+	}
+	return nil, aes.KeySizeError(len(key))
+}
+
+func aesGCM(key []byte) (*EVP_CIPHER, error) {
+	switch len(key) {
+	case 16:
+		return EVP_aes_128_gcm(), nil
+	case 24:
+		return EVP_aes_192_gcm(), nil
+	case 32:
+		return EVP_aes_256_gcm(), nil
+	}
+	return nil, aes.KeySizeError(len(key))
+}
+
+// maxUpdateLen bounds a single EVP_*Update call, whose length is a C int.
+const maxUpdateLen = 1 << 30
+
+// bytesData returns a pointer to the first element of b, or nil if b is empty.
+func bytesData(b []byte) *byte {
+	if len(b) == 0 {
+		return nil
+	}
+	return unsafe.SliceData(b)
+}
+
+// -----------------------------------------------------------------------------
+
+type aesBlock struct {
+	mu  sync.Mutex
+	enc *EVP_CIPHER_CTX
+	dec *EVP_CIPHER_CTX
+}
+
+// NewAESCipher creates and returns a new cipher.Block backed by OpenSSL.
+// The key argument should be the AES key, either 16, 24, or 32 bytes to
+// select AES-128, AES-192, or AES-256.
+func NewAESCipher(key []byte) (cipher.Block, error) {
+	typ, err := aesECB(key)
+	if err != nil {
+		return nil, err
+	}
+	b := &aesBlock{enc: NewEVP_CIPHER_CTX(), dec: NewEVP_CIPHER_CTX()}
+	runtime.SetFinalizer(b, (*aesBlock).free)
+	if b.enc == nil || b.dec == nil {
+		return nil, NewError("EVP_CIPHER_CTX_new")
+	}
+	if b.enc.EncryptInitEx(typ, nil, unsafe.SliceData(key), nil) != 1 ||
+		b.dec.DecryptInitEx(typ, nil, unsafe.SliceData(key), nil) != 1 {
+		return nil, NewError("EVP_CipherInit_ex")
+	}
+	b.enc.SetPadding(0)
+	b.dec.SetPadding(0)
+	return b, nil
+}
+
+func (b *aesBlock) free() {
+	if b.enc != nil {
+		b.enc.Free()
+	}
+	if b.dec != nil {
+		b.dec.Free()
+	}
+}
+
+func (b *aesBlock) BlockSize() int { return aes.BlockSize }
+
+func (b *aesBlock) Encrypt(dst, src []byte) {
+	if len(src) < aes.BlockSize {
+		panic("crypto/aes: input not full block")
+	}
+	if len(dst) < aes.BlockSize {
+		panic("crypto/aes: output not full block")
+	}
+	var outl c.Int
+	b.mu.Lock()
+	ret := b.enc.EncryptUpdate(unsafe.SliceData(dst), &outl, unsafe.SliceData(src), aes.BlockSize)
+	b.mu.Unlock()
+	runtime.KeepAlive(b)
+	if ret != 1 {
+		panic("openssl: EVP_EncryptUpdate failed")
+	}
+}
+
+func (b *aesBlock) Decrypt(dst, src []byte) {
+	if len(src) < aes.BlockSize {
+		panic("crypto/aes: input not full block")
+	}
+	if len(dst) < aes.BlockSize {
+		panic("crypto/aes: output not full block")
+	}
+	var outl c.Int
+	b.mu.Lock()
+	ret := b.dec.DecryptUpdate(unsafe.SliceData(dst), &outl, unsafe.SliceData(src), aes.BlockSize)
+	b.mu.Unlock()
+	runtime.KeepAlive(b)
+	if ret != 1 {
+		panic("openssl: EVP_DecryptUpdate failed")
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+type aesCTRStream struct {
+	ctx *EVP_CIPHER_CTX
+}
+
+// NewCTR returns a cipher.Stream which encrypts/decrypts using AES in
+// counter mode. The key selects AES-128, AES-192, or AES-256, and iv must
+// be aes.BlockSize bytes long.
+func NewCTR(key, iv []byte) (cipher.Stream, error) {
+	typ, err := aesCTR(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, errors.New("openssl: IV length must equal block size")
+	}
+	s := &aesCTRStream{ctx: NewEVP_CIPHER_CTX()}
+	runtime.SetFinalizer(s, (*aesCTRStream).free)
+	if s.ctx == nil {
+		return nil, NewError("EVP_CIPHER_CTX_new")
+	}
+	if s.ctx.EncryptInitEx(typ, nil, unsafe.SliceData(key), unsafe.SliceData(iv)) != 1 {
+		return nil, NewError("EVP_EncryptInit_ex")
+	}
+	return s, nil
+}
+
+func (s *aesCTRStream) free() {
+	if s.ctx != nil {
+		s.ctx.Free()
+	}
+}
+
+func (s *aesCTRStream) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("crypto/cipher: output smaller than input")
+	}
+	for len(src) > 0 {
+		n := len(src)
+		if n > maxUpdateLen {
+			n = maxUpdateLen
+		}
+		var outl c.Int
+		ret := s.ctx.EncryptUpdate(unsafe.SliceData(dst), &outl, unsafe.SliceData(src), c.Int(n))
+		if ret != 1 {
+			panic("openssl: EVP_EncryptUpdate failed")
+		}
+		dst, src = dst[n:], src[n:]
+	}
+	runtime.KeepAlive(s)
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openssl
+
+import (
+	_ "unsafe"
+
+	"github.com/goplus/lib/c"
+	ctime "github.com/goplus/lib/c/time"
+)
+
+// -----------------------------------------------------------------------------
+
+const (
+	MBSTRING_FLAG = 0x1000
+	MBSTRING_UTF8 = MBSTRING_FLAG
+	MBSTRING_ASC  = MBSTRING_FLAG | 1
+)
+
+// ASN1_STRING is also used as ASN1_IA5STRING, ASN1_OCTET_STRING and the
+// other ASN.1 string types.
+type ASN1_STRING struct {
+	Unused [0]byte
+}
+
+// int ASN1_STRING_length(const ASN1_STRING *x);
+//
+// llgo:link (*ASN1_STRING).Length C.ASN1_STRING_length
+func (*ASN1_STRING) Length() c.Int { return 0 }
+
+// const unsigned char *ASN1_STRING_get0_data(const ASN1_STRING *x);
+//
+// llgo:link (*ASN1_STRING).Get0Data C.ASN1_STRING_get0_data
+func (*ASN1_STRING) Get0Data() *byte { return nil }
+
+// int ASN1_STRING_to_UTF8(unsigned char **out, const ASN1_STRING *in);
+//
+//go:linkname ASN1StringToUTF8 C.ASN1_STRING_to_UTF8
+func ASN1StringToUTF8(out **byte, in *ASN1_STRING) c.Int
+
+// -----------------------------------------------------------------------------
+
+type ASN1_INTEGER struct {
+	Unused [0]byte
+}
+
+// void ASN1_INTEGER_free(ASN1_INTEGER *a);
+//
+// llgo:link (*ASN1_INTEGER).Free C.ASN1_INTEGER_free
+func (*ASN1_INTEGER) Free() {}
+
+// BIGNUM *ASN1_INTEGER_to_BN(const ASN1_INTEGER *ai, BIGNUM *bn);
+//
+// llgo:link (*ASN1_INTEGER).ToBN C.ASN1_INTEGER_to_BN
+func (*ASN1_INTEGER) ToBN(bn *BIGNUM) *BIGNUM { return nil }
+
+// ASN1_INTEGER *BN_to_ASN1_INTEGER(const BIGNUM *bn, ASN1_INTEGER *ai);
+//
+//go:linkname BNToASN1Integer C.BN_to_ASN1_INTEGER
+func BNToASN1Integer(bn *BIGNUM, ai *ASN1_INTEGER) *ASN1_INTEGER
+
+// -----------------------------------------------------------------------------
+
+type ASN1_TIME struct {
+	Unused [0]byte
+}
+
+// ASN1_TIME *ASN1_TIME_set(ASN1_TIME *s, time_t t);
+//
+// llgo:link (*ASN1_TIME).Set C.ASN1_TIME_set
+func (*ASN1_TIME) Set(t ctime.TimeT) *ASN1_TIME { return nil }
+
+// int ASN1_TIME_to_tm(const ASN1_TIME *s, struct tm *tm);
+//
+// llgo:link (*ASN1_TIME).ToTm C.ASN1_TIME_to_tm
+func (*ASN1_TIME) ToTm(tm *ctime.Tm) c.Int { return 0 }
+
+// -----------------------------------------------------------------------------
+
+type ASN1_OBJECT struct {
+	Unused [0]byte
+}
+
+// int OBJ_obj2nid(const ASN1_OBJECT *o);
+//
+//go:linkname OBJObj2nid C.OBJ_obj2nid
+func OBJObj2nid(o *ASN1_OBJECT) c.Int
+
+// int OBJ_obj2txt(char *buf, int buf_len, const ASN1_OBJECT *a, int no_name);
+//
+//go:linkname OBJObj2txt C.OBJ_obj2txt
+func OBJObj2txt(buf *c.Char, bufLen c.Int, a *ASN1_OBJECT, noName c.Int) c.Int
+
+// const char *OBJ_nid2sn(int n);
+//
+//go:linkname OBJNid2sn C.OBJ_nid2sn
+func OBJNid2sn(n c.Int) *c.Char
+
+// int OBJ_txt2nid(const char *s);
+//
+//go:linkname OBJTxt2nid C.OBJ_txt2nid
+func OBJTxt2nid(s *c.Char) c.Int
+
+// -----------------------------------------------------------------------------
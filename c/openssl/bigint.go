@@ -0,0 +1,555 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openssl
+
+import (
+	"errors"
+	"math/big"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/goplus/lib/c"
+)
+
+// -----------------------------------------------------------------------------
+
+// bnCtx owns a BN_CTX. BN_CTX holds scratch space and is not safe for
+// concurrent use, so each operation borrows one from bnCtxPool.
+type bnCtx struct {
+	ctx *BN_CTX
+}
+
+func (p *bnCtx) free() {
+	p.ctx.Free()
+}
+
+var bnCtxPool = sync.Pool{
+	New: func() any {
+		ctx := BN_CTXNew()
+		if ctx == nil {
+			return nil
+		}
+		p := &bnCtx{ctx: ctx}
+		runtime.SetFinalizer(p, (*bnCtx).free)
+		return p
+	},
+}
+
+// withBNCtx calls f with a pooled BN_CTX.
+func withBNCtx(f func(ctx *BN_CTX) c.Int) c.Int {
+	p, _ := bnCtxPool.Get().(*bnCtx)
+	if p == nil {
+		return 0
+	}
+	ret := f(p.ctx)
+	bnCtxPool.Put(p)
+	return ret
+}
+
+// -----------------------------------------------------------------------------
+
+// Int is an arbitrary-precision integer held in an OpenSSL BIGNUM. Ints
+// are immutable: every operation returns a new Int, so they may be shared
+// freely between goroutines. The BIGNUM is freed by a finalizer.
+type Int struct {
+	bn *BIGNUM
+}
+
+func newInt(bn *BIGNUM) *Int {
+	x := &Int{bn: bn}
+	runtime.SetFinalizer(x, (*Int).free)
+	return x
+}
+
+func (x *Int) free() {
+	x.bn.ClearFree()
+}
+
+// compute stores the result of f in a new Int. fn names the OpenSSL
+// function in the error returned when f fails.
+func compute(fn string, f func(r *BIGNUM, ctx *BN_CTX) c.Int, args ...*Int) (*Int, error) {
+	r := BNNew()
+	if r == nil {
+		return nil, NewError("BN_new")
+	}
+	ret := withBNCtx(func(ctx *BN_CTX) c.Int {
+		return f(r, ctx)
+	})
+	runtime.KeepAlive(args)
+	if ret != 1 {
+		r.Free()
+		return nil, NewError(fn)
+	}
+	return newInt(r), nil
+}
+
+// NewInt returns an Int set to v.
+func NewInt(v int64) *Int {
+	bn := BNNew()
+	if bn == nil {
+		panic("openssl: BN_new failed")
+	}
+	if v < 0 {
+		bn.SetWord(BN_ULONG(-uint64(v)))
+		bn.SetNegative(1)
+	} else {
+		bn.SetWord(BN_ULONG(v))
+	}
+	return newInt(bn)
+}
+
+// NewIntFromBytes interprets buf as the bytes of a big-endian unsigned
+// integer, as big.Int.SetBytes does.
+func NewIntFromBytes(buf []byte) (*Int, error) {
+	bn := BNBin2bn(bytesData(buf), c.Int(len(buf)), nil)
+	if bn == nil {
+		return nil, NewError("BN_bin2bn")
+	}
+	return newInt(bn), nil
+}
+
+// FromBig converts x to an Int.
+func FromBig(x *big.Int) (*Int, error) {
+	ret, err := NewIntFromBytes(x.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if x.Sign() < 0 {
+		ret.bn.SetNegative(1)
+	}
+	return ret, nil
+}
+
+// ParseInt parses s in base 10 or 16, with an optional leading '-'. Hex
+// digits take no "0x" prefix.
+func ParseInt(s string, base int) (*Int, error) {
+	digits := strings.TrimPrefix(s, "-")
+	if digits == "" {
+		return nil, errors.New("openssl: invalid integer " + s)
+	}
+	var bn *BIGNUM
+	var n c.Int
+	switch base {
+	case 10:
+		n = BNDec2bn(&bn, c.AllocaCStr(s))
+	case 16:
+		n = BNHex2bn(&bn, c.AllocaCStr(s))
+	default:
+		return nil, errors.New("openssl: unsupported base")
+	}
+	if int(n) != len(s) {
+		if bn != nil {
+			bn.Free()
+		}
+		return nil, errors.New("openssl: invalid integer " + s)
+	}
+	return newInt(bn), nil
+}
+
+// Big converts x to a *big.Int.
+func (x *Int) Big() *big.Int {
+	ret := new(big.Int).SetBytes(x.Bytes())
+	if x.Sign() < 0 {
+		ret.Neg(ret)
+	}
+	return ret
+}
+
+// Bytes returns the absolute value of x as a big-endian byte slice.
+func (x *Int) Bytes() []byte {
+	defer runtime.KeepAlive(x)
+	buf := make([]byte, x.bn.NumBytes())
+	x.bn.Bn2bin(bytesData(buf))
+	return buf
+}
+
+// FillBytes sets buf to the absolute value of x as a zero-extended
+// big-endian byte slice. It panics if the value does not fit.
+func (x *Int) FillBytes(buf []byte) []byte {
+	defer runtime.KeepAlive(x)
+	if x.bn.Bn2binpad(bytesData(buf), c.Int(len(buf))) < 0 {
+		panic("openssl: value does not fit in buffer")
+	}
+	return buf
+}
+
+// String returns the decimal representation of x.
+func (x *Int) String() string {
+	defer runtime.KeepAlive(x)
+	return bnString(x.bn.Bn2dec())
+}
+
+// Hex returns the upper-case hexadecimal representation of x.
+func (x *Int) Hex() string {
+	defer runtime.KeepAlive(x)
+	return bnString(x.bn.Bn2hex())
+}
+
+func bnString(s *c.Char) string {
+	if s == nil {
+		return "<nil>"
+	}
+	defer FreeCStr(s)
+	return c.GoString(s)
+}
+
+// BitLen returns the length of the absolute value of x in bits.
+func (x *Int) BitLen() int {
+	defer runtime.KeepAlive(x)
+	return int(x.bn.NumBits())
+}
+
+// Bit returns the value of the i'th bit of the absolute value of x.
+func (x *Int) Bit(i int) uint {
+	defer runtime.KeepAlive(x)
+	return uint(x.bn.IsBitSet(c.Int(i)))
+}
+
+// Sign returns -1, 0 or +1 according to the sign of x.
+func (x *Int) Sign() int {
+	defer runtime.KeepAlive(x)
+	switch {
+	case x.bn.IsZero() != 0:
+		return 0
+	case x.bn.IsNegative() != 0:
+		return -1
+	}
+	return 1
+}
+
+// Cmp compares x and y and returns -1, 0 or +1.
+func (x *Int) Cmp(y *Int) int {
+	defer runtime.KeepAlive(x)
+	defer runtime.KeepAlive(y)
+	return int(x.bn.Cmp(y.bn))
+}
+
+// CmpAbs compares the absolute values of x and y.
+func (x *Int) CmpAbs(y *Int) int {
+	defer runtime.KeepAlive(x)
+	defer runtime.KeepAlive(y)
+	return int(x.bn.Ucmp(y.bn))
+}
+
+// BN returns the underlying BIGNUM for use with the raw bindings. The
+// caller must not modify it and must keep x alive while using it.
+func (x *Int) BN() *BIGNUM {
+	return x.bn
+}
+
+// -----------------------------------------------------------------------------
+
+// Neg returns -x.
+func (x *Int) Neg() (*Int, error) {
+	return compute("BN_copy", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		if r.Copy(x.bn) == nil {
+			return 0
+		}
+		if r.IsZero() == 0 {
+			r.SetNegative(1 - x.bn.IsNegative())
+		}
+		return 1
+	}, x)
+}
+
+// Abs returns |x|.
+func (x *Int) Abs() (*Int, error) {
+	return compute("BN_copy", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		if r.Copy(x.bn) == nil {
+			return 0
+		}
+		r.SetNegative(0)
+		return 1
+	}, x)
+}
+
+// Add returns x + y.
+func (x *Int) Add(y *Int) (*Int, error) {
+	return compute("BN_add", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.Add(x.bn, y.bn)
+	}, x, y)
+}
+
+// Sub returns x - y.
+func (x *Int) Sub(y *Int) (*Int, error) {
+	return compute("BN_sub", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.Sub(x.bn, y.bn)
+	}, x, y)
+}
+
+// Mul returns x * y.
+func (x *Int) Mul(y *Int) (*Int, error) {
+	return compute("BN_mul", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.Mul(x.bn, y.bn, ctx)
+	}, x, y)
+}
+
+// Sqr returns x * x.
+func (x *Int) Sqr() (*Int, error) {
+	return compute("BN_sqr", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.Sqr(x.bn, ctx)
+	}, x)
+}
+
+// QuoRem returns the quotient x/y and the remainder x%y, truncated towards
+// zero as big.Int.QuoRem does. It fails if y is zero.
+func (x *Int) QuoRem(y *Int) (q, r *Int, err error) {
+	rem := BNNew()
+	if rem == nil {
+		return nil, nil, NewError("BN_new")
+	}
+	q, err = compute("BN_div", func(dv *BIGNUM, ctx *BN_CTX) c.Int {
+		return dv.Div(rem, x.bn, y.bn, ctx)
+	}, x, y)
+	if err != nil {
+		rem.Free()
+		return nil, nil, err
+	}
+	return q, newInt(rem), nil
+}
+
+// Quo returns x/y truncated towards zero. It fails if y is zero.
+func (x *Int) Quo(y *Int) (*Int, error) {
+	return compute("BN_div", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.Div(nil, x.bn, y.bn, ctx)
+	}, x, y)
+}
+
+// Rem returns x%y with the sign of x. It fails if y is zero.
+func (x *Int) Rem(y *Int) (*Int, error) {
+	return compute("BN_mod", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.Mod(x.bn, y.bn, ctx)
+	}, x, y)
+}
+
+// Mod returns x modulo m in the range [0, |m|). It fails if m is zero.
+func (x *Int) Mod(m *Int) (*Int, error) {
+	return compute("BN_nnmod", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.Nnmod(x.bn, m.bn, ctx)
+	}, x, m)
+}
+
+// Exp returns x**y. y must not be negative.
+func (x *Int) Exp(y *Int) (*Int, error) {
+	if y.Sign() < 0 {
+		return nil, errors.New("openssl: negative exponent")
+	}
+	return compute("BN_exp", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.Exp(x.bn, y.bn, ctx)
+	}, x, y)
+}
+
+// ModExp returns x**y mod m. y must not be negative and m must be
+// positive.
+func (x *Int) ModExp(y, m *Int) (*Int, error) {
+	if y.Sign() < 0 {
+		return nil, errors.New("openssl: negative exponent")
+	}
+	return compute("BN_mod_exp", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.ModExp(x.bn, y.bn, m.bn, ctx)
+	}, x, y, m)
+}
+
+// ModAdd returns (x + y) mod m.
+func (x *Int) ModAdd(y, m *Int) (*Int, error) {
+	return compute("BN_mod_add", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.ModAdd(x.bn, y.bn, m.bn, ctx)
+	}, x, y, m)
+}
+
+// ModSub returns (x - y) mod m.
+func (x *Int) ModSub(y, m *Int) (*Int, error) {
+	return compute("BN_mod_sub", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.ModSub(x.bn, y.bn, m.bn, ctx)
+	}, x, y, m)
+}
+
+// ModMul returns (x * y) mod m.
+func (x *Int) ModMul(y, m *Int) (*Int, error) {
+	return compute("BN_mod_mul", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.ModMul(x.bn, y.bn, m.bn, ctx)
+	}, x, y, m)
+}
+
+// ModInverse returns the multiplicative inverse of x modulo m. It fails if
+// x and m are not coprime.
+func (x *Int) ModInverse(m *Int) (*Int, error) {
+	return compute("BN_mod_inverse", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		if r.ModInverse(x.bn, m.bn, ctx) == nil {
+			return 0
+		}
+		return 1
+	}, x, m)
+}
+
+// ModSqrt returns a square root of x modulo the prime p. It fails if x is
+// not a square mod p.
+func (x *Int) ModSqrt(p *Int) (*Int, error) {
+	return compute("BN_mod_sqrt", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		if r.ModSqrt(x.bn, p.bn, ctx) == nil {
+			return 0
+		}
+		return 1
+	}, x, p)
+}
+
+// GCD returns the greatest common divisor of x and y, which is never
+// negative.
+func (x *Int) GCD(y *Int) (*Int, error) {
+	return compute("BN_gcd", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.Gcd(x.bn, y.bn, ctx)
+	}, x, y)
+}
+
+// Lsh returns x << n.
+func (x *Int) Lsh(n uint) (*Int, error) {
+	return compute("BN_lshift", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.Lshift(x.bn, c.Int(n))
+	}, x)
+}
+
+// Rsh returns |x| >> n with the sign of x, i.e. it truncates towards zero
+// unlike big.Int.Rsh.
+func (x *Int) Rsh(n uint) (*Int, error) {
+	return compute("BN_rshift", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.Rshift(x.bn, c.Int(n))
+	}, x)
+}
+
+// -----------------------------------------------------------------------------
+
+// RandInt returns a uniform random value in [0, max). max must be
+// positive.
+func RandInt(max *Int) (*Int, error) {
+	return compute("BN_rand_range", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.RandRange(max.bn)
+	}, max)
+}
+
+// GeneratePrime returns a random prime of exactly bits bits. If safe is
+// true, (p-1)/2 is also prime.
+func GeneratePrime(bits int, safe bool) (*Int, error) {
+	var s c.Int
+	if safe {
+		s = 1
+	}
+	return compute("BN_generate_prime_ex2", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.GeneratePrimeEx2(c.Int(bits), s, nil, nil, nil, ctx)
+	})
+}
+
+// ProbablyPrime reports whether x is prime, using enough Miller-Rabin
+// rounds for a false positive probability below 2**-128.
+func (x *Int) ProbablyPrime() (bool, error) {
+	var err error
+	ret := withBNCtx(func(ctx *BN_CTX) c.Int {
+		return x.bn.CheckPrime(ctx, nil)
+	})
+	runtime.KeepAlive(x)
+	if ret < 0 {
+		err = NewError("BN_check_prime")
+	}
+	return ret == 1, err
+}
+
+// -----------------------------------------------------------------------------
+
+// MontContext holds precomputed Montgomery values for an odd modulus. It
+// speeds up repeated modular multiplication and exponentiation with the
+// same modulus, and is safe for concurrent use once created.
+type MontContext struct {
+	mont *BN_MONT_CTX
+	m    *Int
+}
+
+func (mc *MontContext) free() {
+	mc.mont.Free()
+}
+
+// NewMontContext prepares Montgomery arithmetic modulo m, which must be
+// odd and positive.
+func NewMontContext(m *Int) (*MontContext, error) {
+	if m.Sign() <= 0 || m.bn.IsOdd() == 0 {
+		return nil, errors.New("openssl: Montgomery modulus must be odd and positive")
+	}
+	mont := BN_MONT_CTXNew()
+	if mont == nil {
+		return nil, NewError("BN_MONT_CTX_new")
+	}
+	mc := &MontContext{mont: mont, m: m}
+	runtime.SetFinalizer(mc, (*MontContext).free)
+	if withBNCtx(func(ctx *BN_CTX) c.Int { return mont.Set(m.bn, ctx) }) != 1 {
+		return nil, NewError("BN_MONT_CTX_set")
+	}
+	return mc, nil
+}
+
+// Modulus returns the modulus of mc.
+func (mc *MontContext) Modulus() *Int {
+	return mc.m
+}
+
+// ModExp returns x**y mod the modulus of mc. y must not be negative.
+func (mc *MontContext) ModExp(x, y *Int) (*Int, error) {
+	if y.Sign() < 0 {
+		return nil, errors.New("openssl: negative exponent")
+	}
+	defer runtime.KeepAlive(mc)
+	return compute("BN_mod_exp_mont", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.ModExpMont(x.bn, y.bn, mc.m.bn, ctx, mc.mont)
+	}, x, y)
+}
+
+// ModExpConstTime is like ModExp, but takes time independent of the
+// value of the secret exponent y.
+func (mc *MontContext) ModExpConstTime(x, y *Int) (*Int, error) {
+	if y.Sign() < 0 {
+		return nil, errors.New("openssl: negative exponent")
+	}
+	defer runtime.KeepAlive(mc)
+	return compute("BN_mod_exp_mont_consttime", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.ModExpMontConsttime(x.bn, y.bn, mc.m.bn, ctx, mc.mont)
+	}, x, y)
+}
+
+// ToMont converts x, which must be in [0, m), into Montgomery form.
+func (mc *MontContext) ToMont(x *Int) (*Int, error) {
+	defer runtime.KeepAlive(mc)
+	return compute("BN_to_montgomery", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.ToMontgomery(x.bn, mc.mont, ctx)
+	}, x)
+}
+
+// FromMont converts x out of Montgomery form.
+func (mc *MontContext) FromMont(x *Int) (*Int, error) {
+	defer runtime.KeepAlive(mc)
+	return compute("BN_from_montgomery", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.FromMontgomery(x.bn, mc.mont, ctx)
+	}, x)
+}
+
+// MulMont multiplies x and y, both in Montgomery form, and returns the
+// product in Montgomery form.
+func (mc *MontContext) MulMont(x, y *Int) (*Int, error) {
+	defer runtime.KeepAlive(mc)
+	return compute("BN_mod_mul_montgomery", func(r *BIGNUM, ctx *BN_CTX) c.Int {
+		return r.ModMulMontgomery(x.bn, y.bn, mc.mont, ctx)
+	}, x, y)
+}
+
+// -----------------------------------------------------------------------------
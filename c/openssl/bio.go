@@ -17,6 +17,7 @@
 package openssl
 
 import (
+	"io"
 	"unsafe"
 
 	"github.com/goplus/lib/c"
@@ -24,10 +25,37 @@ import (
 
 // -----------------------------------------------------------------------------
 
+const (
+	BIO_CTRL_RESET    = 1
+	BIO_CTRL_EOF      = 2
+	BIO_CTRL_INFO     = 3
+	BIO_CTRL_PENDING  = 10
+	BIO_CTRL_FLUSH    = 11
+	BIO_CTRL_WPENDING = 13
+
+	BIO_C_SET_BUF_MEM_EOF_RETURN = 130
+)
+
+type BIO_METHOD struct {
+	Unused [0]byte
+}
+
+// const BIO_METHOD *BIO_s_mem(void);
+//
+//go:linkname BIOSMem C.BIO_s_mem
+func BIOSMem() *BIO_METHOD
+
+// -----------------------------------------------------------------------------
+
 type BIO struct {
 	Unused [0]byte
 }
 
+// BIO *BIO_new(const BIO_METHOD *type);
+//
+//go:linkname BIONew C.BIO_new
+func BIONew(typ *BIO_METHOD) *BIO
+
 // BIO *BIO_new_mem_buf(const void *buf, int len);
 //
 //go:linkname BIONewMemBuf C.BIO_new_mem_buf
@@ -43,6 +71,41 @@ func (*BIO) Free() c.Int { return 0 }
 // llgo:link (*BIO).UpRef C.BIO_up_ref
 func (*BIO) UpRef() c.Int { return 0 }
 
+// void BIO_free_all(BIO *a);
+//
+// llgo:link (*BIO).FreeAll C.BIO_free_all
+func (*BIO) FreeAll() {}
+
+// long BIO_ctrl(BIO *bp, int cmd, long larg, void *parg);
+//
+// llgo:link (*BIO).Ctrl C.BIO_ctrl
+func (*BIO) Ctrl(cmd c.Int, larg c.Long, parg unsafe.Pointer) c.Long { return 0 }
+
+// size_t BIO_ctrl_pending(BIO *b);
+//
+// llgo:link (*BIO).CtrlPending C.BIO_ctrl_pending
+func (*BIO) CtrlPending() uintptr { return 0 }
+
+// size_t BIO_ctrl_wpending(BIO *b);
+//
+// llgo:link (*BIO).CtrlWpending C.BIO_ctrl_wpending
+func (*BIO) CtrlWpending() uintptr { return 0 }
+
+// long BIO_get_mem_data(BIO *b, char **pp);
+func (b *BIO) GetMemData(pp **c.Char) c.Long {
+	return b.Ctrl(BIO_CTRL_INFO, 0, unsafe.Pointer(pp))
+}
+
+// int BIO_set_mem_eof_return(BIO *b, int v);
+func (b *BIO) SetMemEofReturn(v c.Int) c.Int {
+	return c.Int(b.Ctrl(BIO_C_SET_BUF_MEM_EOF_RETURN, c.Long(v), nil))
+}
+
+// int BIO_read(BIO *b, void *data, int dlen);
+//
+// llgo:link (*BIO).Read C.BIO_read
+func (*BIO) Read(data unsafe.Pointer, dlen c.Int) c.Int { return 0 }
+
 // int BIO_read_ex(BIO *b, void *data, size_t dlen, size_t *readbytes);
 //
 // llgo:link (*BIO).ReadEx C.BIO_read_ex
@@ -58,4 +121,42 @@ func (*BIO) Write(data unsafe.Pointer, dlen c.Int) c.Int { return 0 }
 // llgo:link (*BIO).WriteEx C.BIO_write_ex
 func (*BIO) WriteEx(data unsafe.Pointer, dlen uintptr, written *uintptr) c.Int { return 0 }
 
+// ReadBytes reads up to len(data) bytes from b. It returns io.EOF when no
+// data is available and OpenSSL reported no error.
+func (b *BIO) ReadBytes(data []byte) (int, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+	var n uintptr
+	if b.ReadEx(unsafe.Pointer(unsafe.SliceData(data)), uintptr(len(data)), &n) != 1 {
+		if ERRPeekError() == 0 {
+			return 0, io.EOF
+		}
+		return 0, NewError("BIO_read_ex")
+	}
+	return int(n), nil
+}
+
+// WriteBytes writes data to b.
+func (b *BIO) WriteBytes(data []byte) (int, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+	var n uintptr
+	if b.WriteEx(unsafe.Pointer(unsafe.SliceData(data)), uintptr(len(data)), &n) != 1 {
+		return int(n), NewError("BIO_write_ex")
+	}
+	return int(n), nil
+}
+
+// bioMemBytes returns a copy of the data held by a memory BIO.
+func bioMemBytes(b *BIO) []byte {
+	var p *c.Char
+	n := b.GetMemData(&p)
+	if n <= 0 || p == nil {
+		return nil
+	}
+	return append([]byte(nil), unsafe.Slice((*byte)(unsafe.Pointer(p)), n)...)
+}
+
 // -----------------------------------------------------------------------------
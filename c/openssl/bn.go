@@ -187,6 +187,16 @@ func (*BIGNUM) Bn2dec() *c.Char { return nil }
 // llgo:link (*BIGNUM).CStr C.BN_bn2dec
 func (*BIGNUM) CStr() *c.Char { return nil }
 
+// int BN_num_bits(const BIGNUM *a);
+//
+// llgo:link (*BIGNUM).NumBits C.BN_num_bits
+func (*BIGNUM) NumBits() c.Int { return 0 }
+
+// #define BN_num_bytes(a) ((BN_num_bits(a)+7)/8)
+func (bn *BIGNUM) NumBytes() c.Int {
+	return (bn.NumBits() + 7) / 8
+}
+
 // int BN_hex2bn(BIGNUM **a, const char *str);
 //
 //go:linkname BNHex2bn C.BN_hex2bn
@@ -300,12 +310,12 @@ func (*BIGNUM) Uadd(a, b *BIGNUM) c.Int { return 0 }
 // int BN_mul(BIGNUM *r, const BIGNUM *a, const BIGNUM *b, BN_CTX *ctx);
 //
 // llgo:link (*BIGNUM).Mul C.BN_mul
-func (*BIGNUM) Mul(r, a, b *BIGNUM, ctx *BN_CTX) c.Int { return 0 }
+func (*BIGNUM) Mul(a, b *BIGNUM, ctx *BN_CTX) c.Int { return 0 }
 
 // int BN_sqr(BIGNUM *r, const BIGNUM *a, BN_CTX *ctx);
 //
 // llgo:link (*BIGNUM).Sqr C.BN_sqr
-func (*BIGNUM) Sqr(r, a *BIGNUM, ctx *BN_CTX) c.Int { return 0 }
+func (*BIGNUM) Sqr(a *BIGNUM, ctx *BN_CTX) c.Int { return 0 }
 
 /** BN_set_negative sets sign of a BIGNUM
  * \param  b  pointer to the BIGNUM object
@@ -333,7 +343,42 @@ func (*BIGNUM) Div(rem, m, d *BIGNUM, ctx *BN_CTX) c.Int { return 0 }
 // int BN_nnmod(BIGNUM *r, const BIGNUM *m, const BIGNUM *d, BN_CTX *ctx);
 //
 // llgo:link (*BIGNUM).Nnmod C.BN_nnmod
-func (*BIGNUM) Nnmod(r, m, d *BIGNUM, ctx *BN_CTX) c.Int { return 0 }
+func (*BIGNUM) Nnmod(m, d *BIGNUM, ctx *BN_CTX) c.Int { return 0 }
+
+// # define BN_mod(rem,m,d,ctx) BN_div(NULL,(rem),(m),(d),(ctx))
+func (rem *BIGNUM) Mod(m, d *BIGNUM, ctx *BN_CTX) c.Int {
+	return (*BIGNUM)(nil).Div(rem, m, d, ctx)
+}
+
+// int BN_mod_add(BIGNUM *r, const BIGNUM *a, const BIGNUM *b, const BIGNUM *m, BN_CTX *ctx);
+//
+// llgo:link (*BIGNUM).ModAdd C.BN_mod_add
+func (*BIGNUM) ModAdd(a, b, m *BIGNUM, ctx *BN_CTX) c.Int { return 0 }
+
+// int BN_mod_sub(BIGNUM *r, const BIGNUM *a, const BIGNUM *b, const BIGNUM *m, BN_CTX *ctx);
+//
+// llgo:link (*BIGNUM).ModSub C.BN_mod_sub
+func (*BIGNUM) ModSub(a, b, m *BIGNUM, ctx *BN_CTX) c.Int { return 0 }
+
+// int BN_mod_mul(BIGNUM *r, const BIGNUM *a, const BIGNUM *b, const BIGNUM *m, BN_CTX *ctx);
+//
+// llgo:link (*BIGNUM).ModMul C.BN_mod_mul
+func (*BIGNUM) ModMul(a, b, m *BIGNUM, ctx *BN_CTX) c.Int { return 0 }
+
+// int BN_mod_sqr(BIGNUM *r, const BIGNUM *a, const BIGNUM *m, BN_CTX *ctx);
+//
+// llgo:link (*BIGNUM).ModSqr C.BN_mod_sqr
+func (*BIGNUM) ModSqr(a, m *BIGNUM, ctx *BN_CTX) c.Int { return 0 }
+
+// BIGNUM *BN_mod_inverse(BIGNUM *ret, const BIGNUM *a, const BIGNUM *n, BN_CTX *ctx);
+//
+// llgo:link (*BIGNUM).ModInverse C.BN_mod_inverse
+func (*BIGNUM) ModInverse(a, n *BIGNUM, ctx *BN_CTX) *BIGNUM { return nil }
+
+// BIGNUM *BN_mod_sqrt(BIGNUM *ret, const BIGNUM *a, const BIGNUM *p, BN_CTX *ctx);
+//
+// llgo:link (*BIGNUM).ModSqrt C.BN_mod_sqrt
+func (*BIGNUM) ModSqrt(a, p *BIGNUM, ctx *BN_CTX) *BIGNUM { return nil }
 
 // int BN_cmp(const BIGNUM *a, const BIGNUM *b);
 //
@@ -390,6 +435,20 @@ func (*BIGNUM) Exp(a, p *BIGNUM, ctx *BN_CTX) c.Int { return 0 }
 // llgo:link (*BIGNUM).ModExp C.BN_mod_exp
 func (*BIGNUM) ModExp(a, p, m *BIGNUM, ctx *BN_CTX) c.Int { return 0 }
 
+// int BN_mod_exp_mont(BIGNUM *r, const BIGNUM *a, const BIGNUM *p,
+// const BIGNUM *m, BN_CTX *ctx, BN_MONT_CTX *m_ctx);
+//
+// llgo:link (*BIGNUM).ModExpMont C.BN_mod_exp_mont
+func (*BIGNUM) ModExpMont(a, p, m *BIGNUM, ctx *BN_CTX, mont *BN_MONT_CTX) c.Int { return 0 }
+
+// int BN_mod_exp_mont_consttime(BIGNUM *rr, const BIGNUM *a, const BIGNUM *p,
+// const BIGNUM *m, BN_CTX *ctx, BN_MONT_CTX *in_mont);
+//
+// llgo:link (*BIGNUM).ModExpMontConsttime C.BN_mod_exp_mont_consttime
+func (*BIGNUM) ModExpMontConsttime(a, p, m *BIGNUM, ctx *BN_CTX, mont *BN_MONT_CTX) c.Int {
+	return 0
+}
+
 // int BN_gcd(BIGNUM *r, const BIGNUM *a, const BIGNUM *b, BN_CTX *ctx);
 //
 // llgo:link (*BIGNUM).Gcd C.BN_gcd
@@ -402,8 +461,99 @@ func (*BIGNUM) AreCoprime(b *BIGNUM, ctx *BN_CTX) c.Int { return 0 }
 
 // -----------------------------------------------------------------------------
 
+// const BIGNUM *BN_value_one(void);
+//
+//go:linkname BNValueOne C.BN_value_one
+func BNValueOne() *BIGNUM
+
+const (
+	BN_RAND_TOP_ANY = -1
+	BN_RAND_TOP_ONE = 0
+	BN_RAND_TOP_TWO = 1
+
+	BN_RAND_BOTTOM_ANY = 0
+	BN_RAND_BOTTOM_ODD = 1
+)
+
+// int BN_rand(BIGNUM *rnd, int bits, int top, int bottom);
+//
+// llgo:link (*BIGNUM).Rand C.BN_rand
+func (*BIGNUM) Rand(bits, top, bottom c.Int) c.Int { return 0 }
+
+// int BN_priv_rand(BIGNUM *rnd, int bits, int top, int bottom);
+//
+// llgo:link (*BIGNUM).PrivRand C.BN_priv_rand
+func (*BIGNUM) PrivRand(bits, top, bottom c.Int) c.Int { return 0 }
+
+// int BN_rand_range(BIGNUM *rnd, const BIGNUM *range);
+//
+// llgo:link (*BIGNUM).RandRange C.BN_rand_range
+func (*BIGNUM) RandRange(rng *BIGNUM) c.Int { return 0 }
+
+// int BN_priv_rand_range(BIGNUM *rnd, const BIGNUM *range);
+//
+// llgo:link (*BIGNUM).PrivRandRange C.BN_priv_rand_range
+func (*BIGNUM) PrivRandRange(rng *BIGNUM) c.Int { return 0 }
+
+// -----------------------------------------------------------------------------
+
 type BN_GENCB struct {
 	Unused [0]byte
 }
 
+// int BN_generate_prime_ex2(BIGNUM *ret, int bits, int safe,
+// const BIGNUM *add, const BIGNUM *rem, BN_GENCB *cb, BN_CTX *ctx);
+//
+// llgo:link (*BIGNUM).GeneratePrimeEx2 C.BN_generate_prime_ex2
+func (*BIGNUM) GeneratePrimeEx2(bits, safe c.Int, add, rem *BIGNUM, cb *BN_GENCB, ctx *BN_CTX) c.Int {
+	return 0
+}
+
+// int BN_check_prime(const BIGNUM *p, BN_CTX *ctx, BN_GENCB *cb);
+//
+// llgo:link (*BIGNUM).CheckPrime C.BN_check_prime
+func (*BIGNUM) CheckPrime(ctx *BN_CTX, cb *BN_GENCB) c.Int { return 0 }
+
+// -----------------------------------------------------------------------------
+
+type BN_MONT_CTX struct {
+	Unused [0]byte
+}
+
+// BN_MONT_CTX *BN_MONT_CTX_new(void);
+//
+//go:linkname BN_MONT_CTXNew C.BN_MONT_CTX_new
+func BN_MONT_CTXNew() *BN_MONT_CTX
+
+// void BN_MONT_CTX_free(BN_MONT_CTX *mont);
+//
+// llgo:link (*BN_MONT_CTX).Free C.BN_MONT_CTX_free
+func (*BN_MONT_CTX) Free() {}
+
+// int BN_MONT_CTX_set(BN_MONT_CTX *mont, const BIGNUM *mod, BN_CTX *ctx);
+//
+// llgo:link (*BN_MONT_CTX).Set C.BN_MONT_CTX_set
+func (*BN_MONT_CTX) Set(mod *BIGNUM, ctx *BN_CTX) c.Int { return 0 }
+
+// BN_MONT_CTX *BN_MONT_CTX_copy(BN_MONT_CTX *to, BN_MONT_CTX *from);
+//
+// llgo:link (*BN_MONT_CTX).Copy C.BN_MONT_CTX_copy
+func (*BN_MONT_CTX) Copy(from *BN_MONT_CTX) *BN_MONT_CTX { return nil }
+
+// int BN_mod_mul_montgomery(BIGNUM *r, const BIGNUM *a, const BIGNUM *b,
+// BN_MONT_CTX *mont, BN_CTX *ctx);
+//
+// llgo:link (*BIGNUM).ModMulMontgomery C.BN_mod_mul_montgomery
+func (*BIGNUM) ModMulMontgomery(a, b *BIGNUM, mont *BN_MONT_CTX, ctx *BN_CTX) c.Int { return 0 }
+
+// int BN_to_montgomery(BIGNUM *r, const BIGNUM *a, BN_MONT_CTX *mont, BN_CTX *ctx);
+//
+// llgo:link (*BIGNUM).ToMontgomery C.BN_to_montgomery
+func (*BIGNUM) ToMontgomery(a *BIGNUM, mont *BN_MONT_CTX, ctx *BN_CTX) c.Int { return 0 }
+
+// int BN_from_montgomery(BIGNUM *r, const BIGNUM *a, BN_MONT_CTX *mont, BN_CTX *ctx);
+//
+// llgo:link (*BIGNUM).FromMontgomery C.BN_from_montgomery
+func (*BIGNUM) FromMontgomery(a *BIGNUM, mont *BN_MONT_CTX, ctx *BN_CTX) c.Int { return 0 }
+
 // -----------------------------------------------------------------------------
@@ -0,0 +1,984 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openssl
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+	ctime "github.com/goplus/lib/c/time"
+)
+
+// -----------------------------------------------------------------------------
+
+// Certificate is an X.509 certificate held by OpenSSL.
+type Certificate struct {
+	x *X509
+}
+
+func newCertificate(x *X509) *Certificate {
+	cert := &Certificate{x: x}
+	runtime.SetFinalizer(cert, (*Certificate).free)
+	return cert
+}
+
+func (cert *Certificate) free() {
+	cert.x.Free()
+}
+
+// ParseCertificatePEM parses the first "CERTIFICATE" PEM block in data.
+func ParseCertificatePEM(data []byte) (*Certificate, error) {
+	certs, err := parseCertificatesPEM(data)
+	if err != nil {
+		return nil, err
+	}
+	freeCertificates(certs[1:])
+	return newCertificate(certs[0]), nil
+}
+
+// ParseCertificatesPEM parses every "CERTIFICATE" PEM block in data, such
+// as a chain or a bundle of roots.
+func ParseCertificatesPEM(data []byte) ([]*Certificate, error) {
+	certs, err := parseCertificatesPEM(data)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Certificate, len(certs))
+	for i, x := range certs {
+		ret[i] = newCertificate(x)
+	}
+	return ret, nil
+}
+
+// ParseCertificateDER parses a DER encoded certificate.
+func ParseCertificateDER(der []byte) (*Certificate, error) {
+	if len(der) == 0 {
+		return nil, errors.New("openssl: empty DER data")
+	}
+	p := unsafe.SliceData(der)
+	x := D2iX509(nil, &p, c.Long(len(der)))
+	if x == nil {
+		return nil, NewError("d2i_X509")
+	}
+	return newCertificate(x), nil
+}
+
+// FromX509Certificate converts a crypto/x509 certificate.
+func FromX509Certificate(cert *x509.Certificate) (*Certificate, error) {
+	return ParseCertificateDER(cert.Raw)
+}
+
+// X509Certificate converts cert to a crypto/x509 certificate.
+func (cert *Certificate) X509Certificate() (*x509.Certificate, error) {
+	der, err := cert.MarshalDER()
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// MarshalPEM returns cert as a "CERTIFICATE" PEM block.
+func (cert *Certificate) MarshalPEM() ([]byte, error) {
+	bio := BIONew(BIOSMem())
+	if bio == nil {
+		return nil, NewError("BIO_new")
+	}
+	defer bio.Free()
+	defer runtime.KeepAlive(cert)
+	if PEMWriteBioX509(bio, cert.x) != 1 {
+		return nil, NewError("PEM_write_bio_X509")
+	}
+	return bioMemBytes(bio), nil
+}
+
+// MarshalDER returns the DER encoding of cert.
+func (cert *Certificate) MarshalDER() ([]byte, error) {
+	defer runtime.KeepAlive(cert)
+	der := x509DER(cert.x)
+	if der == nil {
+		return nil, NewError("i2d_X509")
+	}
+	return der, nil
+}
+
+// Version returns the certificate version: 1, 2 or 3.
+func (cert *Certificate) Version() int {
+	defer runtime.KeepAlive(cert)
+	return int(cert.x.GetVersion()) + 1
+}
+
+// SerialNumber returns the certificate serial number.
+func (cert *Certificate) SerialNumber() *big.Int {
+	defer runtime.KeepAlive(cert)
+	bn := cert.x.Get0SerialNumber().ToBN(nil)
+	if bn == nil {
+		return nil
+	}
+	defer bn.Free()
+	buf := make([]byte, bn.NumBytes())
+	bn.Bn2bin(bytesData(buf))
+	ret := new(big.Int).SetBytes(buf)
+	if bn.IsNegative() != 0 {
+		ret.Neg(ret)
+	}
+	return ret
+}
+
+// Subject returns the subject name. Entries that cannot be decoded are
+// skipped.
+func (cert *Certificate) Subject() pkix.Name {
+	defer runtime.KeepAlive(cert)
+	return x509NameToPKIX(cert.x.GetSubjectName())
+}
+
+// Issuer returns the issuer name. Entries that cannot be decoded are
+// skipped.
+func (cert *Certificate) Issuer() pkix.Name {
+	defer runtime.KeepAlive(cert)
+	return x509NameToPKIX(cert.x.GetIssuerName())
+}
+
+// NotBefore returns the start of the validity period.
+func (cert *Certificate) NotBefore() time.Time {
+	defer runtime.KeepAlive(cert)
+	return asn1TimeToTime(cert.x.Get0NotBefore())
+}
+
+// NotAfter returns the end of the validity period.
+func (cert *Certificate) NotAfter() time.Time {
+	defer runtime.KeepAlive(cert)
+	return asn1TimeToTime(cert.x.Get0NotAfter())
+}
+
+// DNSNames returns the DNS names of the subject alternative name extension.
+func (cert *Certificate) DNSNames() []string {
+	return cert.altNames().dnsNames
+}
+
+// EmailAddresses returns the email addresses of the subject alternative
+// name extension.
+func (cert *Certificate) EmailAddresses() []string {
+	return cert.altNames().emailAddresses
+}
+
+// IPAddresses returns the IP addresses of the subject alternative name
+// extension.
+func (cert *Certificate) IPAddresses() []net.IP {
+	return cert.altNames().ipAddresses
+}
+
+// URIs returns the URIs of the subject alternative name extension.
+func (cert *Certificate) URIs() []*url.URL {
+	return cert.altNames().uris
+}
+
+func (cert *Certificate) altNames() (ret altNames) {
+	defer runtime.KeepAlive(cert)
+	if names := (*OPENSSL_STACK)(cert.x.GetExtD2i(NID_subject_alt_name, nil, nil)); names != nil {
+		ret.parse(names)
+		GENERAL_NAMESFree(names)
+	}
+	return
+}
+
+// PublicKey returns the certificate's public key.
+func (cert *Certificate) PublicKey() (*PublicKey, error) {
+	defer runtime.KeepAlive(cert)
+	pkey := cert.x.GetPubkey()
+	if pkey == nil {
+		return nil, NewError("X509_get_pubkey")
+	}
+	return newPublicKey(pkey), nil
+}
+
+// CheckSignatureFrom verifies that the signature on cert was made by the
+// key of parent. It does not check the validity period or constraints.
+func (cert *Certificate) CheckSignatureFrom(parent *Certificate) error {
+	defer runtime.KeepAlive(cert)
+	defer runtime.KeepAlive(parent)
+	if cert.x.Verify(parent.x.Get0Pubkey()) != 1 {
+		ERRClearError()
+		return errVerification
+	}
+	return nil
+}
+
+// VerifyHostname checks that cert is valid for host, which may be a DNS
+// name or an IP address.
+func (cert *Certificate) VerifyHostname(host string) error {
+	defer runtime.KeepAlive(cert)
+	var ret c.Int
+	if ip := net.ParseIP(host); ip != nil {
+		ret = cert.x.CheckIPAsc(c.AllocaCStr(host), 0)
+	} else {
+		ret = cert.x.CheckHost(c.AllocaCStr(host), uintptr(len(host)), X509_CHECK_FLAG_NO_PARTIAL_WILDCARDS, nil)
+	}
+	if ret != 1 {
+		ERRClearError()
+		return fmt.Errorf("openssl: certificate is not valid for %s", host)
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+// CertificateRequest is a PKCS #10 certificate signing request held by
+// OpenSSL.
+type CertificateRequest struct {
+	req *X509_REQ
+}
+
+func newCertificateRequest(req *X509_REQ) *CertificateRequest {
+	csr := &CertificateRequest{req: req}
+	runtime.SetFinalizer(csr, (*CertificateRequest).free)
+	return csr
+}
+
+func (csr *CertificateRequest) free() {
+	csr.req.Free()
+}
+
+// ParseCertificateRequestPEM parses a "CERTIFICATE REQUEST" PEM block.
+func ParseCertificateRequestPEM(data []byte) (*CertificateRequest, error) {
+	if len(data) == 0 {
+		return nil, errors.New("openssl: empty PEM data")
+	}
+	bio := BIONewMemBuf(unsafe.Pointer(unsafe.SliceData(data)), c.Int(len(data)))
+	if bio == nil {
+		return nil, NewError("BIO_new_mem_buf")
+	}
+	defer bio.Free()
+	req := PEMReadBioX509_REQ(bio, nil, nil, nil)
+	if req == nil {
+		return nil, NewError("PEM_read_bio_X509_REQ")
+	}
+	return newCertificateRequest(req), nil
+}
+
+// ParseCertificateRequestDER parses a DER encoded certificate request.
+func ParseCertificateRequestDER(der []byte) (*CertificateRequest, error) {
+	if len(der) == 0 {
+		return nil, errors.New("openssl: empty DER data")
+	}
+	p := unsafe.SliceData(der)
+	req := D2iX509_REQ(nil, &p, c.Long(len(der)))
+	if req == nil {
+		return nil, NewError("d2i_X509_REQ")
+	}
+	return newCertificateRequest(req), nil
+}
+
+// X509CertificateRequest converts csr to a crypto/x509 certificate request.
+func (csr *CertificateRequest) X509CertificateRequest() (*x509.CertificateRequest, error) {
+	der, err := csr.MarshalDER()
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificateRequest(der)
+}
+
+// MarshalPEM returns csr as a "CERTIFICATE REQUEST" PEM block.
+func (csr *CertificateRequest) MarshalPEM() ([]byte, error) {
+	bio := BIONew(BIOSMem())
+	if bio == nil {
+		return nil, NewError("BIO_new")
+	}
+	defer bio.Free()
+	defer runtime.KeepAlive(csr)
+	if PEMWriteBioX509_REQ(bio, csr.req) != 1 {
+		return nil, NewError("PEM_write_bio_X509_REQ")
+	}
+	return bioMemBytes(bio), nil
+}
+
+// MarshalDER returns the DER encoding of csr.
+func (csr *CertificateRequest) MarshalDER() ([]byte, error) {
+	defer runtime.KeepAlive(csr)
+	n := I2dX509_REQ(csr.req, nil)
+	if n <= 0 {
+		return nil, NewError("i2d_X509_REQ")
+	}
+	der := make([]byte, n)
+	p := unsafe.SliceData(der)
+	if I2dX509_REQ(csr.req, &p) != n {
+		return nil, NewError("i2d_X509_REQ")
+	}
+	return der, nil
+}
+
+// Subject returns the subject name. Entries that cannot be decoded are
+// skipped.
+func (csr *CertificateRequest) Subject() pkix.Name {
+	defer runtime.KeepAlive(csr)
+	return x509NameToPKIX(csr.req.GetSubjectName())
+}
+
+// DNSNames returns the DNS names of the requested subject alternative
+// name extension.
+func (csr *CertificateRequest) DNSNames() []string {
+	return csr.altNames().dnsNames
+}
+
+// EmailAddresses returns the email addresses of the requested subject
+// alternative name extension.
+func (csr *CertificateRequest) EmailAddresses() []string {
+	return csr.altNames().emailAddresses
+}
+
+// IPAddresses returns the IP addresses of the requested subject
+// alternative name extension.
+func (csr *CertificateRequest) IPAddresses() []net.IP {
+	return csr.altNames().ipAddresses
+}
+
+func (csr *CertificateRequest) altNames() (ret altNames) {
+	defer runtime.KeepAlive(csr)
+	exts := csr.req.GetExtensions()
+	if exts == nil {
+		ERRClearError()
+		return
+	}
+	for i, n := c.Int(0), exts.Num(); i < n; i++ {
+		ext := (*X509_EXTENSION)(exts.Value(i))
+		if OBJObj2nid(ext.GetObject()) != NID_subject_alt_name {
+			continue
+		}
+		if names := (*OPENSSL_STACK)(ext.D2i()); names != nil {
+			ret.parse(names)
+			GENERAL_NAMESFree(names)
+		}
+	}
+	freeExtensions(exts)
+	return
+}
+
+// PublicKey returns the requested public key.
+func (csr *CertificateRequest) PublicKey() (*PublicKey, error) {
+	defer runtime.KeepAlive(csr)
+	pkey := csr.req.GetPubkey()
+	if pkey == nil {
+		return nil, NewError("X509_REQ_get_pubkey")
+	}
+	return newPublicKey(pkey), nil
+}
+
+// CheckSignature verifies that the request is signed by its own key.
+func (csr *CertificateRequest) CheckSignature() error {
+	pub, err := csr.PublicKey()
+	if err != nil {
+		return err
+	}
+	defer runtime.KeepAlive(csr)
+	defer runtime.KeepAlive(pub)
+	if csr.req.Verify(pub.pkey) != 1 {
+		ERRClearError()
+		return errVerification
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+// CreateCertificate creates a certificate from template, signed by priv.
+// The certificate is self-signed if parent is nil; otherwise its issuer is
+// parent's subject and priv must be parent's key. pub is the key being
+// certified.
+//
+// The fields of template used are SerialNumber, Subject, NotBefore,
+// NotAfter, SignatureAlgorithm, KeyUsage, ExtKeyUsage,
+// BasicConstraintsValid, IsCA, MaxPathLen, MaxPathLenZero, DNSNames,
+// EmailAddresses, IPAddresses and URIs.
+func CreateCertificate(template *x509.Certificate, parent *Certificate, pub *PublicKey, priv *PrivateKey) (*Certificate, error) {
+	if template.SerialNumber == nil || template.SerialNumber.Sign() < 0 {
+		return nil, errors.New("openssl: certificate serial number must be non-negative")
+	}
+	md, err := signatureMD(priv, template.SignatureAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	exts, err := certExtensions(template)
+	if err != nil {
+		return nil, err
+	}
+
+	x := X509New()
+	if x == nil {
+		return nil, NewError("X509_new")
+	}
+	cert := newCertificate(x)
+	if x.SetVersion(X509_VERSION_3) != 1 {
+		return nil, NewError("X509_set_version")
+	}
+	if err = setSerialNumber(x, template.SerialNumber); err != nil {
+		return nil, err
+	}
+	subject, err := pkixToX509Name(template.Subject)
+	if err != nil {
+		return nil, err
+	}
+	defer subject.Free()
+	if x.SetSubjectName(subject) != 1 {
+		return nil, NewError("X509_set_subject_name")
+	}
+	issuer := subject
+	if parent != nil {
+		issuer = parent.x.GetSubjectName()
+	}
+	if x.SetIssuerName(issuer) != 1 {
+		return nil, NewError("X509_set_issuer_name")
+	}
+	runtime.KeepAlive(parent)
+	if x.GetmNotBefore().Set(ctime.TimeT(template.NotBefore.Unix())) == nil ||
+		x.GetmNotAfter().Set(ctime.TimeT(template.NotAfter.Unix())) == nil {
+		return nil, NewError("ASN1_TIME_set")
+	}
+	if x.SetPubkey(pub.pkey) != 1 {
+		return nil, NewError("X509_set_pubkey")
+	}
+	runtime.KeepAlive(pub)
+	for _, e := range exts {
+		ext := X509V3EXTNconfNID(nil, nil, e.nid, c.AllocaCStr(e.value))
+		if ext == nil {
+			return nil, fmt.Errorf("openssl: invalid %s extension %q", c.GoString(OBJNid2sn(e.nid)), e.value)
+		}
+		ret := x.AddExt(ext, -1)
+		ext.Free()
+		if ret != 1 {
+			return nil, NewError("X509_add_ext")
+		}
+	}
+	if x.Sign(priv.pkey, md) <= 0 {
+		return nil, NewError("X509_sign")
+	}
+	runtime.KeepAlive(priv)
+	return cert, nil
+}
+
+// CreateCertificateRequest creates a certificate signing request for the
+// public half of priv, signed by priv.
+//
+// The fields of template used are Subject, SignatureAlgorithm, DNSNames,
+// EmailAddresses, IPAddresses and URIs.
+func CreateCertificateRequest(template *x509.CertificateRequest, priv *PrivateKey) (*CertificateRequest, error) {
+	md, err := signatureMD(priv, template.SignatureAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	san, err := subjectAltName(template.DNSNames, template.EmailAddresses, template.IPAddresses, template.URIs)
+	if err != nil {
+		return nil, err
+	}
+
+	req := X509_REQNew()
+	if req == nil {
+		return nil, NewError("X509_REQ_new")
+	}
+	csr := newCertificateRequest(req)
+	if req.SetVersion(X509_REQ_VERSION_1) != 1 {
+		return nil, NewError("X509_REQ_set_version")
+	}
+	subject, err := pkixToX509Name(template.Subject)
+	if err != nil {
+		return nil, err
+	}
+	defer subject.Free()
+	if req.SetSubjectName(subject) != 1 {
+		return nil, NewError("X509_REQ_set_subject_name")
+	}
+	defer runtime.KeepAlive(priv)
+	if req.SetPubkey(priv.pkey) != 1 {
+		return nil, NewError("X509_REQ_set_pubkey")
+	}
+	if san != "" {
+		ext := X509V3EXTNconfNID(nil, nil, NID_subject_alt_name, c.AllocaCStr(san))
+		if ext == nil {
+			return nil, fmt.Errorf("openssl: invalid subjectAltName extension %q", san)
+		}
+		exts := OPENSSLSkNewNull()
+		exts.Push(unsafe.Pointer(ext))
+		ret := req.AddExtensions(exts)
+		freeExtensions(exts)
+		if ret != 1 {
+			return nil, NewError("X509_REQ_add_extensions")
+		}
+	}
+	if req.Sign(priv.pkey, md) <= 0 {
+		return nil, NewError("X509_REQ_sign")
+	}
+	return csr, nil
+}
+
+// signatureMD returns the digest used by priv for alg. Ed25519 keys sign
+// without a separate digest.
+func signatureMD(priv *PrivateKey, alg x509.SignatureAlgorithm) (*EVP_MD, error) {
+	defer runtime.KeepAlive(priv)
+	if priv.pkey.BaseID() == EVP_PKEY_ED25519 {
+		if alg != x509.UnknownSignatureAlgorithm && alg != x509.PureEd25519 {
+			return nil, errors.New("openssl: signature algorithm does not match an Ed25519 key")
+		}
+		return nil, nil
+	}
+	switch alg {
+	case x509.UnknownSignatureAlgorithm, x509.SHA256WithRSA, x509.ECDSAWithSHA256:
+		return EVP_sha256(), nil
+	case x509.SHA384WithRSA, x509.ECDSAWithSHA384:
+		return EVP_sha384(), nil
+	case x509.SHA512WithRSA, x509.ECDSAWithSHA512:
+		return EVP_sha512(), nil
+	}
+	return nil, errors.New("openssl: unsupported signature algorithm " + alg.String())
+}
+
+func setSerialNumber(x *X509, serial *big.Int) error {
+	buf := serial.Bytes()
+	bn := BNBin2bn(bytesData(buf), c.Int(len(buf)), nil)
+	if bn == nil {
+		return NewError("BN_bin2bn")
+	}
+	defer bn.Free()
+	ai := BNToASN1Integer(bn, nil)
+	if ai == nil {
+		return NewError("BN_to_ASN1_INTEGER")
+	}
+	defer ai.Free()
+	if x.SetSerialNumber(ai) != 1 {
+		return NewError("X509_set_serialNumber")
+	}
+	return nil
+}
+
+// x509Extension is an extension in OpenSSL configuration syntax, e.g.
+// basicConstraints "critical,CA:TRUE".
+type x509Extension struct {
+	nid   c.Int
+	value string
+}
+
+var keyUsageNames = [...]string{
+	"digitalSignature", "nonRepudiation", "keyEncipherment", "dataEncipherment",
+	"keyAgreement", "keyCertSign", "cRLSign", "encipherOnly", "decipherOnly",
+}
+
+var extKeyUsageNames = map[x509.ExtKeyUsage]string{
+	x509.ExtKeyUsageAny:             "anyExtendedKeyUsage",
+	x509.ExtKeyUsageServerAuth:      "serverAuth",
+	x509.ExtKeyUsageClientAuth:      "clientAuth",
+	x509.ExtKeyUsageCodeSigning:     "codeSigning",
+	x509.ExtKeyUsageEmailProtection: "emailProtection",
+	x509.ExtKeyUsageTimeStamping:    "timeStamping",
+	x509.ExtKeyUsageOCSPSigning:     "OCSPSigning",
+}
+
+func certExtensions(template *x509.Certificate) ([]x509Extension, error) {
+	var exts []x509Extension
+	if template.BasicConstraintsValid {
+		value := "critical,CA:FALSE"
+		if template.IsCA {
+			value = "critical,CA:TRUE"
+			if template.MaxPathLen > 0 || template.MaxPathLenZero {
+				value += ",pathlen:" + strconv.Itoa(template.MaxPathLen)
+			}
+		}
+		exts = append(exts, x509Extension{NID_basic_constraints, value})
+	}
+	if template.KeyUsage != 0 {
+		var usages []string
+		for i, name := range keyUsageNames {
+			if template.KeyUsage&(1<<i) != 0 {
+				usages = append(usages, name)
+			}
+		}
+		exts = append(exts, x509Extension{NID_key_usage, "critical," + strings.Join(usages, ",")})
+	}
+	if len(template.ExtKeyUsage) > 0 {
+		usages := make([]string, len(template.ExtKeyUsage))
+		for i, u := range template.ExtKeyUsage {
+			name, ok := extKeyUsageNames[u]
+			if !ok {
+				return nil, fmt.Errorf("openssl: unsupported extended key usage %d", u)
+			}
+			usages[i] = name
+		}
+		exts = append(exts, x509Extension{NID_ext_key_usage, strings.Join(usages, ",")})
+	}
+	san, err := subjectAltName(template.DNSNames, template.EmailAddresses, template.IPAddresses, template.URIs)
+	if err != nil {
+		return nil, err
+	}
+	if san != "" {
+		exts = append(exts, x509Extension{NID_subject_alt_name, san})
+	}
+	return exts, nil
+}
+
+// subjectAltName formats a subjectAltName extension value.
+func subjectAltName(dnsNames, emails []string, ips []net.IP, uris []*url.URL) (string, error) {
+	var parts []string
+	add := func(typ, value string) error {
+		if strings.ContainsAny(value, ",\n") {
+			return fmt.Errorf("openssl: unsupported subject alternative name %q", value)
+		}
+		parts = append(parts, typ+":"+value)
+		return nil
+	}
+	for _, name := range dnsNames {
+		if err := add("DNS", name); err != nil {
+			return "", err
+		}
+	}
+	for _, email := range emails {
+		if err := add("email", email); err != nil {
+			return "", err
+		}
+	}
+	for _, ip := range ips {
+		if err := add("IP", ip.String()); err != nil {
+			return "", err
+		}
+	}
+	for _, uri := range uris {
+		if err := add("URI", uri.String()); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(parts, ","), nil
+}
+
+func freeExtensions(exts *OPENSSL_STACK) {
+	for i, n := c.Int(0), exts.Num(); i < n; i++ {
+		(*X509_EXTENSION)(exts.Value(i)).Free()
+	}
+	exts.Free()
+}
+
+// -----------------------------------------------------------------------------
+
+// altNames holds the decoded entries of a subject alternative name
+// extension.
+type altNames struct {
+	dnsNames       []string
+	emailAddresses []string
+	ipAddresses    []net.IP
+	uris           []*url.URL
+}
+
+func (an *altNames) parse(names *OPENSSL_STACK) {
+	for i, n := c.Int(0), names.Num(); i < n; i++ {
+		gen := (*GENERAL_NAME)(names.Value(i))
+		if gen.Type != GEN_DNS && gen.Type != GEN_EMAIL && gen.Type != GEN_URI && gen.Type != GEN_IPADD {
+			continue
+		}
+		data := asn1StringBytes((*ASN1_STRING)(gen.D))
+		switch gen.Type {
+		case GEN_DNS:
+			an.dnsNames = append(an.dnsNames, string(data))
+		case GEN_EMAIL:
+			an.emailAddresses = append(an.emailAddresses, string(data))
+		case GEN_URI:
+			if u, err := url.Parse(string(data)); err == nil {
+				an.uris = append(an.uris, u)
+			}
+		case GEN_IPADD:
+			if len(data) == net.IPv4len || len(data) == net.IPv6len {
+				an.ipAddresses = append(an.ipAddresses, net.IP(data))
+			}
+		}
+	}
+}
+
+// asn1StringBytes returns a copy of the contents of s.
+func asn1StringBytes(s *ASN1_STRING) []byte {
+	n := s.Length()
+	if n <= 0 {
+		return nil
+	}
+	return append([]byte(nil), unsafe.Slice(s.Get0Data(), n)...)
+}
+
+func asn1TimeToTime(t *ASN1_TIME) time.Time {
+	var tm ctime.Tm
+	if t == nil || t.ToTm(&tm) != 1 {
+		return time.Time{}
+	}
+	return time.Date(int(tm.Year)+1900, time.Month(tm.Mon+1), int(tm.Mday),
+		int(tm.Hour), int(tm.Min), int(tm.Sec), 0, time.UTC)
+}
+
+func x509NameToPKIX(name *X509_NAME) pkix.Name {
+	var rdns pkix.RDNSequence
+	var buf [128]c.Char
+	last := c.Int(-1)
+	for i, n := c.Int(0), name.EntryCount(); i < n; i++ {
+		entry := name.GetEntry(i)
+		l := OBJObj2txt(&buf[0], c.Int(len(buf)), entry.GetObject(), 1)
+		if l <= 0 || int(l) >= len(buf) {
+			continue
+		}
+		oid, err := parseOID(c.GoString(&buf[0]))
+		if err != nil {
+			continue
+		}
+		var out *byte
+		l = ASN1StringToUTF8(&out, entry.GetData())
+		if l < 0 {
+			ERRClearError()
+			continue
+		}
+		value := string(unsafe.Slice(out, l))
+		Free(unsafe.Pointer(out))
+
+		atv := pkix.AttributeTypeAndValue{Type: oid, Value: value}
+		if set := entry.Set(); set == last {
+			rdns[len(rdns)-1] = append(rdns[len(rdns)-1], atv)
+		} else {
+			rdns = append(rdns, pkix.RelativeDistinguishedNameSET{atv})
+			last = set
+		}
+	}
+	var ret pkix.Name
+	ret.FillFromRDNSequence(&rdns)
+	return ret
+}
+
+func pkixToX509Name(name pkix.Name) (*X509_NAME, error) {
+	ret := X509_NAMENew()
+	if ret == nil {
+		return nil, NewError("X509_NAME_new")
+	}
+	for _, rdn := range name.ToRDNSequence() {
+		for i, atv := range rdn {
+			value, ok := atv.Value.(string)
+			if !ok {
+				ret.Free()
+				return nil, fmt.Errorf("openssl: unsupported value for name attribute %v", atv.Type)
+			}
+			set := c.Int(0)
+			if i > 0 {
+				set = -1 // add to the previous RDN
+			}
+			data := []byte(value)
+			if ret.AddEntryByTxt(c.AllocaCStr(atv.Type.String()), MBSTRING_UTF8,
+				bytesData(data), c.Int(len(data)), -1, set) != 1 {
+				ret.Free()
+				return nil, fmt.Errorf("openssl: invalid name attribute %v=%q", atv.Type, value)
+			}
+		}
+	}
+	return ret, nil
+}
+
+func parseOID(s string) (asn1.ObjectIdentifier, error) {
+	parts := strings.Split(s, ".")
+	oid := make(asn1.ObjectIdentifier, len(parts))
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		oid[i] = v
+	}
+	return oid, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// CertPool is a set of trusted certificates backed by an X509_STORE.
+type CertPool struct {
+	store *X509_STORE
+}
+
+func newCertPool(store *X509_STORE) *CertPool {
+	p := &CertPool{store: store}
+	runtime.SetFinalizer(p, (*CertPool).free)
+	return p
+}
+
+func (p *CertPool) free() {
+	p.store.Free()
+}
+
+// NewCertPool returns an empty CertPool.
+func NewCertPool() *CertPool {
+	return newCertPool(X509_STORENew())
+}
+
+// SystemCertPool returns a CertPool loaded from OpenSSL's default
+// certificate file and directory.
+func SystemCertPool() (*CertPool, error) {
+	p := NewCertPool()
+	if p.store.SetDefaultPaths() != 1 {
+		return nil, NewError("X509_STORE_set_default_paths")
+	}
+	return p, nil
+}
+
+// AddCert adds cert to the pool.
+func (p *CertPool) AddCert(cert *Certificate) error {
+	defer runtime.KeepAlive(p)
+	defer runtime.KeepAlive(cert)
+	if p.store.AddCert(cert.x) != 1 {
+		return NewError("X509_STORE_add_cert")
+	}
+	return nil
+}
+
+// AppendCertsFromPEM adds every certificate in pemCerts to the pool. It
+// reports whether any certificate was added.
+func (p *CertPool) AppendCertsFromPEM(pemCerts []byte) bool {
+	certs, err := ParseCertificatesPEM(pemCerts)
+	if err != nil {
+		return false
+	}
+	ok := false
+	for _, cert := range certs {
+		if p.AddCert(cert) == nil {
+			ok = true
+		}
+	}
+	return ok
+}
+
+// LoadFile adds the PEM certificates in the named file to the pool.
+func (p *CertPool) LoadFile(name string) error {
+	defer runtime.KeepAlive(p)
+	if p.store.LoadFile(c.AllocaCStr(name)) != 1 {
+		return NewError("X509_STORE_load_file")
+	}
+	return nil
+}
+
+// VerifyOptions configures Certificate.Verify.
+type VerifyOptions struct {
+	// Roots is the set of trusted certificates. If nil, the system pool
+	// is used.
+	Roots *CertPool
+
+	// Intermediates are untrusted certificates that may be used to build
+	// the chain.
+	Intermediates []*Certificate
+
+	// DNSName, if set, is checked against the leaf certificate. It may
+	// also be an IP address.
+	DNSName string
+
+	// CurrentTime is used to check validity periods. If zero, the current
+	// time is used.
+	CurrentTime time.Time
+}
+
+// VerifyError reports why chain verification failed.
+type VerifyError struct {
+	Code   int          // X509_V_ERR_* code
+	Depth  int          // depth of the failing certificate; 0 is the leaf
+	Cert   *Certificate // failing certificate, may be nil
+	Reason string       // OpenSSL's description of Code
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("openssl: certificate verify failed at depth %d: %s", e.Depth, e.Reason)
+}
+
+// Verify builds and verifies a chain from cert to one of opts.Roots. On
+// success it returns the chain, leaf first; on failure the error is a
+// *VerifyError when OpenSSL reported a verification error.
+func (cert *Certificate) Verify(opts VerifyOptions) ([]*Certificate, error) {
+	roots := opts.Roots
+	if roots == nil {
+		var err error
+		if roots, err = SystemCertPool(); err != nil {
+			return nil, err
+		}
+	}
+	ctx := X509_STORE_CTXNew()
+	if ctx == nil {
+		return nil, NewError("X509_STORE_CTX_new")
+	}
+	defer ctx.Free()
+	untrusted := OPENSSLSkNewNull()
+	if untrusted == nil {
+		return nil, NewError("OPENSSL_sk_new_null")
+	}
+	defer untrusted.Free()
+	for _, inter := range opts.Intermediates {
+		untrusted.Push(unsafe.Pointer(inter.x))
+	}
+	defer runtime.KeepAlive(opts.Intermediates)
+	defer runtime.KeepAlive(roots)
+	defer runtime.KeepAlive(cert)
+
+	if ctx.Init(roots.store, cert.x, untrusted) != 1 {
+		return nil, NewError("X509_STORE_CTX_init")
+	}
+	param := ctx.Get0Param()
+	if name := opts.DNSName; name != "" {
+		var ret c.Int
+		if net.ParseIP(name) != nil {
+			ret = param.Set1IPAsc(c.AllocaCStr(name))
+		} else {
+			param.SetHostflags(X509_CHECK_FLAG_NO_PARTIAL_WILDCARDS)
+			ret = param.Set1Host(c.AllocaCStr(name), uintptr(len(name)))
+		}
+		if ret != 1 {
+			return nil, errors.New("openssl: invalid DNSName " + name)
+		}
+	}
+	if !opts.CurrentTime.IsZero() {
+		param.SetTime(ctime.TimeT(opts.CurrentTime.Unix()))
+	}
+
+	if ctx.VerifyCert() != 1 {
+		code := ctx.GetError()
+		if code == X509_V_OK {
+			return nil, NewError("X509_verify_cert")
+		}
+		ERRClearError()
+		verr := &VerifyError{
+			Code:   int(code),
+			Depth:  int(ctx.GetErrorDepth()),
+			Reason: c.GoString(X509VerifyCertErrorString(c.Long(code))),
+		}
+		if x := ctx.GetCurrentCert(); x != nil {
+			x.UpRef()
+			verr.Cert = newCertificate(x)
+		}
+		return nil, verr
+	}
+	stack := ctx.Get1Chain()
+	if stack == nil {
+		return nil, NewError("X509_STORE_CTX_get1_chain")
+	}
+	defer stack.Free()
+	chain := make([]*Certificate, stack.Num())
+	for i := range chain {
+		chain[i] = newCertificate((*X509)(stack.Value(c.Int(i))))
+	}
+	return chain, nil
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,253 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openssl
+
+import (
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+// -----------------------------------------------------------------------------
+
+const (
+	EVP_MAX_KEY_LENGTH   = 64
+	EVP_MAX_IV_LENGTH    = 16
+	EVP_MAX_BLOCK_LENGTH = 32
+
+	EVP_CTRL_AEAD_SET_IVLEN = 0x9
+	EVP_CTRL_AEAD_GET_TAG   = 0x10
+	EVP_CTRL_AEAD_SET_TAG   = 0x11
+
+	EVP_CTRL_GCM_SET_IVLEN = EVP_CTRL_AEAD_SET_IVLEN
+	EVP_CTRL_GCM_GET_TAG   = EVP_CTRL_AEAD_GET_TAG
+	EVP_CTRL_GCM_SET_TAG   = EVP_CTRL_AEAD_SET_TAG
+
+	EVP_GCM_TLS_TAG_LEN        = 16
+	EVP_CHACHAPOLY_TLS_TAG_LEN = 16
+)
+
+// -----------------------------------------------------------------------------
+
+type EVP_CIPHER struct {
+	Unused [0]byte
+}
+
+// const EVP_CIPHER *EVP_aes_128_ecb(void);
+//
+//go:linkname EVP_aes_128_ecb C.EVP_aes_128_ecb
+func EVP_aes_128_ecb() *EVP_CIPHER
+
+// const EVP_CIPHER *EVP_aes_192_ecb(void);
+//
+//go:linkname EVP_aes_192_ecb C.EVP_aes_192_ecb
+func EVP_aes_192_ecb() *EVP_CIPHER
+
+// const EVP_CIPHER *EVP_aes_256_ecb(void);
+//
+//go:linkname EVP_aes_256_ecb C.EVP_aes_256_ecb
+func EVP_aes_256_ecb() *EVP_CIPHER
+
+// const EVP_CIPHER *EVP_aes_128_cbc(void);
+//
+//go:linkname EVP_aes_128_cbc C.EVP_aes_128_cbc
+func EVP_aes_128_cbc() *EVP_CIPHER
+
+// const EVP_CIPHER *EVP_aes_192_cbc(void);
+//
+//go:linkname EVP_aes_192_cbc C.EVP_aes_192_cbc
+func EVP_aes_192_cbc() *EVP_CIPHER
+
+// const EVP_CIPHER *EVP_aes_256_cbc(void);
+//
+//go:linkname EVP_aes_256_cbc C.EVP_aes_256_cbc
+func EVP_aes_256_cbc() *EVP_CIPHER
+
+// const EVP_CIPHER *EVP_aes_128_ctr(void);
+//
+//go:linkname EVP_aes_128_ctr C.EVP_aes_128_ctr
+func EVP_aes_128_ctr() *EVP_CIPHER
+
+// const EVP_CIPHER *EVP_aes_192_ctr(void);
+//
+//go:linkname EVP_aes_192_ctr C.EVP_aes_192_ctr
+func EVP_aes_192_ctr() *EVP_CIPHER
+
+// const EVP_CIPHER *EVP_aes_256_ctr(void);
+//
+//go:linkname EVP_aes_256_ctr C.EVP_aes_256_ctr
+func EVP_aes_256_ctr() *EVP_CIPHER
+
+// const EVP_CIPHER *EVP_aes_128_gcm(void);
+//
+//go:linkname EVP_aes_128_gcm C.EVP_aes_128_gcm
+func EVP_aes_128_gcm() *EVP_CIPHER
+
+// const EVP_CIPHER *EVP_aes_192_gcm(void);
+//
+//go:linkname EVP_aes_192_gcm C.EVP_aes_192_gcm
+func EVP_aes_192_gcm() *EVP_CIPHER
+
+// const EVP_CIPHER *EVP_aes_256_gcm(void);
+//
+//go:linkname EVP_aes_256_gcm C.EVP_aes_256_gcm
+func EVP_aes_256_gcm() *EVP_CIPHER
+
+// const EVP_CIPHER *EVP_chacha20(void);
+//
+//go:linkname EVP_chacha20 C.EVP_chacha20
+func EVP_chacha20() *EVP_CIPHER
+
+// const EVP_CIPHER *EVP_chacha20_poly1305(void);
+//
+//go:linkname EVP_chacha20_poly1305 C.EVP_chacha20_poly1305
+func EVP_chacha20_poly1305() *EVP_CIPHER
+
+// int EVP_CIPHER_get_nid(const EVP_CIPHER *cipher);
+//
+// llgo:link (*EVP_CIPHER).Nid C.EVP_CIPHER_get_nid
+func (*EVP_CIPHER) Nid() c.Int { return 0 }
+
+// int EVP_CIPHER_get_block_size(const EVP_CIPHER *cipher);
+//
+// llgo:link (*EVP_CIPHER).BlockSize C.EVP_CIPHER_get_block_size
+func (*EVP_CIPHER) BlockSize() c.Int { return 0 }
+
+// int EVP_CIPHER_get_key_length(const EVP_CIPHER *cipher);
+//
+// llgo:link (*EVP_CIPHER).KeyLength C.EVP_CIPHER_get_key_length
+func (*EVP_CIPHER) KeyLength() c.Int { return 0 }
+
+// int EVP_CIPHER_get_iv_length(const EVP_CIPHER *cipher);
+//
+// llgo:link (*EVP_CIPHER).IVLength C.EVP_CIPHER_get_iv_length
+func (*EVP_CIPHER) IVLength() c.Int { return 0 }
+
+// -----------------------------------------------------------------------------
+
+type EVP_CIPHER_CTX struct {
+	Unused [0]byte
+}
+
+// EVP_CIPHER_CTX *EVP_CIPHER_CTX_new(void);
+//
+//go:linkname NewEVP_CIPHER_CTX C.EVP_CIPHER_CTX_new
+func NewEVP_CIPHER_CTX() *EVP_CIPHER_CTX
+
+// void EVP_CIPHER_CTX_free(EVP_CIPHER_CTX *ctx);
+//
+// llgo:link (*EVP_CIPHER_CTX).Free C.EVP_CIPHER_CTX_free
+func (*EVP_CIPHER_CTX) Free() {}
+
+// int EVP_CIPHER_CTX_reset(EVP_CIPHER_CTX *ctx);
+//
+// llgo:link (*EVP_CIPHER_CTX).Reset C.EVP_CIPHER_CTX_reset
+func (*EVP_CIPHER_CTX) Reset() c.Int { return 0 }
+
+// int EVP_CIPHER_CTX_copy(EVP_CIPHER_CTX *out, const EVP_CIPHER_CTX *in);
+//
+// llgo:link (*EVP_CIPHER_CTX).Copy C.EVP_CIPHER_CTX_copy
+func (*EVP_CIPHER_CTX) Copy(in *EVP_CIPHER_CTX) c.Int { return 0 }
+
+// int EVP_CIPHER_CTX_ctrl(EVP_CIPHER_CTX *ctx, int type, int arg, void *ptr);
+//
+// llgo:link (*EVP_CIPHER_CTX).Ctrl C.EVP_CIPHER_CTX_ctrl
+func (*EVP_CIPHER_CTX) Ctrl(typ, arg c.Int, ptr unsafe.Pointer) c.Int { return 0 }
+
+// int EVP_CIPHER_CTX_set_padding(EVP_CIPHER_CTX *c, int pad);
+//
+// llgo:link (*EVP_CIPHER_CTX).SetPadding C.EVP_CIPHER_CTX_set_padding
+func (*EVP_CIPHER_CTX) SetPadding(pad c.Int) c.Int { return 0 }
+
+// int EVP_CIPHER_CTX_set_key_length(EVP_CIPHER_CTX *x, int keylen);
+//
+// llgo:link (*EVP_CIPHER_CTX).SetKeyLength C.EVP_CIPHER_CTX_set_key_length
+func (*EVP_CIPHER_CTX) SetKeyLength(keylen c.Int) c.Int { return 0 }
+
+// int EVP_CIPHER_CTX_get_block_size(const EVP_CIPHER_CTX *ctx);
+//
+// llgo:link (*EVP_CIPHER_CTX).BlockSize C.EVP_CIPHER_CTX_get_block_size
+func (*EVP_CIPHER_CTX) BlockSize() c.Int { return 0 }
+
+// int EVP_CIPHER_CTX_get_key_length(const EVP_CIPHER_CTX *ctx);
+//
+// llgo:link (*EVP_CIPHER_CTX).KeyLength C.EVP_CIPHER_CTX_get_key_length
+func (*EVP_CIPHER_CTX) KeyLength() c.Int { return 0 }
+
+// int EVP_CIPHER_CTX_get_iv_length(const EVP_CIPHER_CTX *ctx);
+//
+// llgo:link (*EVP_CIPHER_CTX).IVLength C.EVP_CIPHER_CTX_get_iv_length
+func (*EVP_CIPHER_CTX) IVLength() c.Int { return 0 }
+
+// int EVP_CipherInit_ex(EVP_CIPHER_CTX *ctx, const EVP_CIPHER *type,
+// ENGINE *impl, const unsigned char *key, const unsigned char *iv, int enc);
+//
+// llgo:link (*EVP_CIPHER_CTX).CipherInitEx C.EVP_CipherInit_ex
+func (*EVP_CIPHER_CTX) CipherInitEx(typ *EVP_CIPHER, impl unsafe.Pointer, key, iv *byte, enc c.Int) c.Int {
+	return 0
+}
+
+// int EVP_CipherUpdate(EVP_CIPHER_CTX *ctx, unsigned char *out,
+// int *outl, const unsigned char *in, int inl);
+//
+// llgo:link (*EVP_CIPHER_CTX).CipherUpdate C.EVP_CipherUpdate
+func (*EVP_CIPHER_CTX) CipherUpdate(out *byte, outl *c.Int, in *byte, inl c.Int) c.Int { return 0 }
+
+// int EVP_CipherFinal_ex(EVP_CIPHER_CTX *ctx, unsigned char *outm, int *outl);
+//
+// llgo:link (*EVP_CIPHER_CTX).CipherFinalEx C.EVP_CipherFinal_ex
+func (*EVP_CIPHER_CTX) CipherFinalEx(outm *byte, outl *c.Int) c.Int { return 0 }
+
+// int EVP_EncryptInit_ex(EVP_CIPHER_CTX *ctx, const EVP_CIPHER *type,
+// ENGINE *impl, const unsigned char *key, const unsigned char *iv);
+//
+// llgo:link (*EVP_CIPHER_CTX).EncryptInitEx C.EVP_EncryptInit_ex
+func (*EVP_CIPHER_CTX) EncryptInitEx(typ *EVP_CIPHER, impl unsafe.Pointer, key, iv *byte) c.Int {
+	return 0
+}
+
+// int EVP_EncryptUpdate(EVP_CIPHER_CTX *ctx, unsigned char *out,
+// int *outl, const unsigned char *in, int inl);
+//
+// llgo:link (*EVP_CIPHER_CTX).EncryptUpdate C.EVP_EncryptUpdate
+func (*EVP_CIPHER_CTX) EncryptUpdate(out *byte, outl *c.Int, in *byte, inl c.Int) c.Int { return 0 }
+
+// int EVP_EncryptFinal_ex(EVP_CIPHER_CTX *ctx, unsigned char *out, int *outl);
+//
+// llgo:link (*EVP_CIPHER_CTX).EncryptFinalEx C.EVP_EncryptFinal_ex
+func (*EVP_CIPHER_CTX) EncryptFinalEx(out *byte, outl *c.Int) c.Int { return 0 }
+
+// int EVP_DecryptInit_ex(EVP_CIPHER_CTX *ctx, const EVP_CIPHER *type,
+// ENGINE *impl, const unsigned char *key, const unsigned char *iv);
+//
+// llgo:link (*EVP_CIPHER_CTX).DecryptInitEx C.EVP_DecryptInit_ex
+func (*EVP_CIPHER_CTX) DecryptInitEx(typ *EVP_CIPHER, impl unsafe.Pointer, key, iv *byte) c.Int {
+	return 0
+}
+
+// int EVP_DecryptUpdate(EVP_CIPHER_CTX *ctx, unsigned char *out,
+// int *outl, const unsigned char *in, int inl);
+//
+// llgo:link (*EVP_CIPHER_CTX).DecryptUpdate C.EVP_DecryptUpdate
+func (*EVP_CIPHER_CTX) DecryptUpdate(out *byte, outl *c.Int, in *byte, inl c.Int) c.Int { return 0 }
+
+// int EVP_DecryptFinal_ex(EVP_CIPHER_CTX *ctx, unsigned char *outm, int *outl);
+//
+// llgo:link (*EVP_CIPHER_CTX).DecryptFinalEx C.EVP_DecryptFinal_ex
+func (*EVP_CIPHER_CTX) DecryptFinalEx(outm *byte, outl *c.Int) c.Int { return 0 }
+
+// -----------------------------------------------------------------------------
@@ -17,6 +17,8 @@
 package openssl
 
 import (
+	"fmt"
+	"strings"
 	"unsafe"
 
 	"github.com/goplus/lib/c"
@@ -154,3 +156,152 @@ func ERRPrintErrorsCb(cb func(str *c.Char, len uintptr, u unsafe.Pointer) c.Int,
 func ERRPrintErrorsFp(fp c.FilePtr)
 
 // -----------------------------------------------------------------------------
+
+const (
+	ERR_TXT_MALLOCED = 0x01
+	ERR_TXT_STRING   = 0x02
+
+	ERR_SYSTEM_FLAG = 1 << 31
+	ERR_LIB_OFFSET  = 23
+	ERR_LIB_MASK    = 0xFF
+	ERR_REASON_MASK = 0x7FFFFF
+)
+
+// #define ERR_SYSTEM_ERROR(errcode) (((errcode) & ERR_SYSTEM_FLAG) != 0)
+func (e Errno) IsSystemError() bool {
+	return e&ERR_SYSTEM_FLAG != 0
+}
+
+// ERR_GET_LIB returns the library code of e, or ERR_LIB_SYS (2) for system
+// errors.
+func (e Errno) Lib() c.Int {
+	if e.IsSystemError() {
+		return 2 // ERR_LIB_SYS
+	}
+	return c.Int(e>>ERR_LIB_OFFSET) & ERR_LIB_MASK
+}
+
+// ERR_GET_REASON returns the reason code of e, or the errno value for
+// system errors.
+func (e Errno) Reason() c.Int {
+	if e.IsSystemError() {
+		return c.Int(e &^ ERR_SYSTEM_FLAG)
+	}
+	return c.Int(e & ERR_REASON_MASK)
+}
+
+// -----------------------------------------------------------------------------
+
+// ErrorEntry is one entry of the OpenSSL error queue.
+type ErrorEntry struct {
+	Code    Errno
+	Library string // library name, e.g. "SSL routines"
+	Reason  string // reason string, e.g. "wrong version number"
+	File    string // source file that raised the error
+	Line    int
+	Func    string // function that raised the error
+	Data    string // additional text, if any
+}
+
+func (ent *ErrorEntry) String() string {
+	var b strings.Builder
+	b.WriteString(ent.Library)
+	b.WriteString(": ")
+	b.WriteString(ent.Reason)
+	if ent.Func != "" {
+		fmt.Fprintf(&b, " (%s", ent.Func)
+		if ent.File != "" {
+			fmt.Fprintf(&b, " at %s:%d", ent.File, ent.Line)
+		}
+		b.WriteByte(')')
+	}
+	if ent.Data != "" {
+		b.WriteString(": ")
+		b.WriteString(ent.Data)
+	}
+	return b.String()
+}
+
+// Error is returned when an OpenSSL call fails. It carries the entries that
+// were queued on the thread's error queue, oldest first.
+type Error struct {
+	Op      string // OpenSSL function that failed, e.g. "EVP_DigestSign"
+	Entries []ErrorEntry
+}
+
+func (e *Error) Error() string {
+	if len(e.Entries) == 0 {
+		return "openssl: " + e.Op + " failed"
+	}
+	var b strings.Builder
+	b.WriteString("openssl: ")
+	b.WriteString(e.Op)
+	b.WriteString(" failed: ")
+	for i := range e.Entries {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(e.Entries[i].String())
+	}
+	return b.String()
+}
+
+// Code returns the code of the most recent queued entry, or 0 if the queue
+// was empty.
+func (e *Error) Code() Errno {
+	if len(e.Entries) == 0 {
+		return 0
+	}
+	return e.Entries[len(e.Entries)-1].Code
+}
+
+// NewError returns an Error for the failed call op and drains the calling
+// thread's error queue into it, so that stale entries do not leak into
+// later errors.
+func NewError(op string) *Error {
+	err := &Error{Op: op}
+	for {
+		var file, function, data *c.Char
+		var line, flags c.Int
+		code := ERRGetErrorAll(&file, &line, &function, &data, &flags)
+		if code == 0 {
+			break
+		}
+		ent := ErrorEntry{
+			Code: code,
+			File: cString(file),
+			Line: int(line),
+			Func: cString(function),
+		}
+		if flags&ERR_TXT_STRING != 0 {
+			ent.Data = cString(data)
+		}
+		if ent.Library = cString(ERRLibErrorString(code)); ent.Library == "" {
+			ent.Library = fmt.Sprintf("lib(%d)", code.Lib())
+		}
+		if ent.Reason = cString(ERRReasonErrorString(code)); ent.Reason == "" {
+			ent.Reason = fmt.Sprintf("reason(%d)", code.Reason())
+		}
+		err.Entries = append(err.Entries, ent)
+	}
+	return err
+}
+
+// Check converts the status ret returned by op into an error. Positive
+// values mean success and yield nil; otherwise the error queue is drained
+// into an *Error.
+func Check(op string, ret c.Int) error {
+	if ret > 0 {
+		return nil
+	}
+	return NewError(op)
+}
+
+func cString(s *c.Char) string {
+	if s == nil {
+		return ""
+	}
+	return c.GoString(s)
+}
+
+// -----------------------------------------------------------------------------
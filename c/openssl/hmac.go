@@ -51,6 +51,16 @@ func EVP_sha384() *EVP_MD
 //go:linkname EVP_sha512 C.EVP_sha512
 func EVP_sha512() *EVP_MD
 
+// const char *EVP_MD_get0_name(const EVP_MD *md);
+//
+// llgo:link (*EVP_MD).Name C.EVP_MD_get0_name
+func (*EVP_MD) Name() *c.Char { return nil }
+
+// int EVP_MD_get_size(const EVP_MD *md);
+//
+// llgo:link (*EVP_MD).Size C.EVP_MD_get_size
+func (*EVP_MD) Size() c.Int { return 0 }
+
 // -----------------------------------------------------------------------------
 
 type HMAC_CTX struct {
@@ -84,12 +94,12 @@ func (ctx *HMAC_CTX) Reset() c.Int { return 0 }
 // llgo:link (*HMAC_CTX).Init C.HMAC_Init
 func (ctx *HMAC_CTX) Init(key unsafe.Pointer, len c.Int, md *EVP_MD) c.Int { return 0 }
 
-func (ctx *HMAC_CTX) InitBytes(key []byte, md *EVP_MD) c.Int {
-	return ctx.Init(unsafe.Pointer(unsafe.SliceData(key)), c.Int(len(key)), md)
+func (ctx *HMAC_CTX) InitBytes(key []byte, md *EVP_MD) error {
+	return Check("HMAC_Init", ctx.Init(unsafe.Pointer(unsafe.SliceData(key)), c.Int(len(key)), md))
 }
 
-func (ctx *HMAC_CTX) InitString(key string, md *EVP_MD) c.Int {
-	return ctx.Init(unsafe.Pointer(unsafe.StringData(key)), c.Int(len(key)), md)
+func (ctx *HMAC_CTX) InitString(key string, md *EVP_MD) error {
+	return Check("HMAC_Init", ctx.Init(unsafe.Pointer(unsafe.StringData(key)), c.Int(len(key)), md))
 }
 
 // OSSL_DEPRECATEDIN_3_0 int HMAC_Init_ex(HMAC_CTX *ctx, const void *key, int len,
@@ -106,12 +116,12 @@ func (ctx *HMAC_CTX) InitEx(key unsafe.Pointer, len c.Int, md *EVP_MD, impl unsa
 // llgo:link (*HMAC_CTX).Update C.HMAC_Update
 func (ctx *HMAC_CTX) Update(data unsafe.Pointer, len uintptr) c.Int { return 0 }
 
-func (ctx *HMAC_CTX) UpdateBytes(data []byte) c.Int {
-	return ctx.Update(unsafe.Pointer(unsafe.SliceData(data)), uintptr(len(data)))
+func (ctx *HMAC_CTX) UpdateBytes(data []byte) error {
+	return Check("HMAC_Update", ctx.Update(unsafe.Pointer(unsafe.SliceData(data)), uintptr(len(data))))
 }
 
-func (ctx *HMAC_CTX) UpdateString(data string) c.Int {
-	return ctx.Update(unsafe.Pointer(unsafe.StringData(data)), uintptr(len(data)))
+func (ctx *HMAC_CTX) UpdateString(data string) error {
+	return Check("HMAC_Update", ctx.Update(unsafe.Pointer(unsafe.StringData(data)), uintptr(len(data))))
 }
 
 // OSSL_DEPRECATEDIN_3_0 int HMAC_Final(HMAC_CTX *ctx, unsigned char *md,
@@ -120,6 +130,16 @@ func (ctx *HMAC_CTX) UpdateString(data string) c.Int {
 // llgo:link (*HMAC_CTX).Final C.HMAC_Final
 func (ctx *HMAC_CTX) Final(md *byte, len *c.Uint) c.Int { return 0 }
 
+// FinalBytes finishes the MAC and returns it.
+func (ctx *HMAC_CTX) FinalBytes() ([]byte, error) {
+	var md [EVP_MAX_MD_SIZE]byte
+	var n c.Uint
+	if err := Check("HMAC_Final", ctx.Final(&md[0], &n)); err != nil {
+		return nil, err
+	}
+	return md[:n:n], nil
+}
+
 // OSSL_DEPRECATEDIN_3_0 __owur int HMAC_CTX_copy(HMAC_CTX *dctx, HMAC_CTX *sctx);
 //
 // llgo:link (*HMAC_CTX).Copy C.HMAC_CTX_copy
@@ -0,0 +1,221 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openssl
+
+import (
+	"crypto"
+	"errors"
+	"math"
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+// -----------------------------------------------------------------------------
+
+type OSSL_LIB_CTX struct {
+	Unused [0]byte
+}
+
+type EVP_KDF struct {
+	Unused [0]byte
+}
+
+// EVP_KDF *EVP_KDF_fetch(OSSL_LIB_CTX *libctx, const char *algorithm,
+// const char *properties);
+//
+//go:linkname EVP_KDFFetch C.EVP_KDF_fetch
+func EVP_KDFFetch(libctx *OSSL_LIB_CTX, algorithm, properties *c.Char) *EVP_KDF
+
+// void EVP_KDF_free(EVP_KDF *kdf);
+//
+// llgo:link (*EVP_KDF).Free C.EVP_KDF_free
+func (*EVP_KDF) Free() {}
+
+// const char *EVP_KDF_get0_name(const EVP_KDF *kdf);
+//
+// llgo:link (*EVP_KDF).Name C.EVP_KDF_get0_name
+func (*EVP_KDF) Name() *c.Char { return nil }
+
+type EVP_KDF_CTX struct {
+	Unused [0]byte
+}
+
+// EVP_KDF_CTX *EVP_KDF_CTX_new(EVP_KDF *kdf);
+//
+//go:linkname EVP_KDF_CTXNew C.EVP_KDF_CTX_new
+func EVP_KDF_CTXNew(kdf *EVP_KDF) *EVP_KDF_CTX
+
+// void EVP_KDF_CTX_free(EVP_KDF_CTX *ctx);
+//
+// llgo:link (*EVP_KDF_CTX).Free C.EVP_KDF_CTX_free
+func (*EVP_KDF_CTX) Free() {}
+
+// void EVP_KDF_CTX_reset(EVP_KDF_CTX *ctx);
+//
+// llgo:link (*EVP_KDF_CTX).Reset C.EVP_KDF_CTX_reset
+func (*EVP_KDF_CTX) Reset() {}
+
+// size_t EVP_KDF_CTX_get_kdf_size(EVP_KDF_CTX *ctx);
+//
+// llgo:link (*EVP_KDF_CTX).GetKDFSize C.EVP_KDF_CTX_get_kdf_size
+func (*EVP_KDF_CTX) GetKDFSize() uintptr { return 0 }
+
+// int EVP_KDF_CTX_set_params(EVP_KDF_CTX *ctx, const OSSL_PARAM params[]);
+//
+// llgo:link (*EVP_KDF_CTX).SetParams C.EVP_KDF_CTX_set_params
+func (*EVP_KDF_CTX) SetParams(params *OSSL_PARAM) c.Int { return 0 }
+
+// int EVP_KDF_derive(EVP_KDF_CTX *ctx, unsigned char *key, size_t keylen,
+// const OSSL_PARAM params[]);
+//
+// llgo:link (*EVP_KDF_CTX).Derive C.EVP_KDF_derive
+func (*EVP_KDF_CTX) Derive(key *byte, keylen uintptr, params *OSSL_PARAM) c.Int { return 0 }
+
+// -----------------------------------------------------------------------------
+
+// deriveKey runs the KDF named alg with the parameters pushed by build.
+func deriveKey(alg *c.Char, keyLen int, build func(bld *OSSL_PARAM_BLD) bool) ([]byte, error) {
+	if keyLen <= 0 {
+		return nil, errors.New("openssl: invalid derived key length")
+	}
+	kdf := EVP_KDFFetch(nil, alg, nil)
+	if kdf == nil {
+		return nil, NewError("EVP_KDF_fetch " + c.GoString(alg))
+	}
+	defer kdf.Free()
+	ctx := EVP_KDF_CTXNew(kdf)
+	if ctx == nil {
+		return nil, NewError("EVP_KDF_CTX_new")
+	}
+	defer ctx.Free()
+
+	bld := OSSL_PARAM_BLDNew()
+	if bld == nil {
+		return nil, NewError("OSSL_PARAM_BLD_new")
+	}
+	defer bld.Free()
+	if !build(bld) {
+		return nil, NewError("OSSL_PARAM_BLD_push")
+	}
+	params := bld.ToParam()
+	if params == nil {
+		return nil, NewError("OSSL_PARAM_BLD_to_param")
+	}
+	defer params.Free()
+
+	key := make([]byte, keyLen)
+	if ctx.Derive(unsafe.SliceData(key), uintptr(keyLen), params) != 1 {
+		return nil, NewError("EVP_KDF_derive")
+	}
+	return key, nil
+}
+
+// digestName returns the OpenSSL name of h.
+func digestName(h crypto.Hash) (*c.Char, error) {
+	md, err := hashToMD(h)
+	if err != nil {
+		return nil, err
+	}
+	if md == nil {
+		return nil, errors.New("openssl: KDF requires a hash function")
+	}
+	return md.Name(), nil
+}
+
+// HKDF derives a keyLen byte key from secret with HKDF (RFC 5869), running
+// both the extract and expand steps. salt and info may be nil.
+func HKDF(h crypto.Hash, secret, salt, info []byte, keyLen int) ([]byte, error) {
+	digest, err := digestName(h)
+	if err != nil {
+		return nil, err
+	}
+	return deriveKey(c.Str("HKDF"), keyLen, func(bld *OSSL_PARAM_BLD) bool {
+		return bld.PushUTF8String(c.Str("digest"), digest, 0) == 1 &&
+			bld.PushBytes(c.Str("key"), secret) == 1 &&
+			bld.PushBytes(c.Str("salt"), salt) == 1 &&
+			bld.PushBytes(c.Str("info"), info) == 1
+	})
+}
+
+// PBKDF2 derives a keyLen byte key from password with PBKDF2 (RFC 8018)
+// using HMAC with h. The argument order follows
+// golang.org/x/crypto/pbkdf2.Key.
+func PBKDF2(password, salt []byte, iter, keyLen int, h crypto.Hash) ([]byte, error) {
+	digest, err := digestName(h)
+	if err != nil {
+		return nil, err
+	}
+	if iter <= 0 {
+		return nil, errors.New("openssl: PBKDF2 iteration count must be positive")
+	}
+	return deriveKey(c.Str("PBKDF2"), keyLen, func(bld *OSSL_PARAM_BLD) bool {
+		return bld.PushUTF8String(c.Str("digest"), digest, 0) == 1 &&
+			bld.PushBytes(c.Str("pass"), password) == 1 &&
+			bld.PushBytes(c.Str("salt"), salt) == 1 &&
+			bld.PushUint64(c.Str("iter"), uint64(iter)) == 1 &&
+			// Lift the SP 800-132 lower bounds, as the Go implementation does.
+			bld.PushInt(c.Str("pkcs5"), 1) == 1
+	})
+}
+
+// Scrypt derives a keyLen byte key from password with scrypt (RFC 7914).
+// N must be a power of two greater than one. The argument order follows
+// golang.org/x/crypto/scrypt.Key.
+func Scrypt(password, salt []byte, N, r, p, keyLen int) ([]byte, error) {
+	if N <= 1 || N&(N-1) != 0 {
+		return nil, errors.New("openssl: scrypt N must be a power of two greater than one")
+	}
+	if r <= 0 || p <= 0 || uint64(r)*uint64(p) >= 1<<30 {
+		return nil, errors.New("openssl: scrypt parameters are too large")
+	}
+	return deriveKey(c.Str("SCRYPT"), keyLen, func(bld *OSSL_PARAM_BLD) bool {
+		return bld.PushBytes(c.Str("pass"), password) == 1 &&
+			bld.PushBytes(c.Str("salt"), salt) == 1 &&
+			bld.PushUint64(c.Str("n"), uint64(N)) == 1 &&
+			bld.PushUint32(c.Str("r"), uint32(r)) == 1 &&
+			bld.PushUint32(c.Str("p"), uint32(p)) == 1 &&
+			// Memory use is bounded by N, r and p, which the caller chooses.
+			bld.PushUint64(c.Str("maxmem_bytes"), math.MaxUint64) == 1
+	})
+}
+
+// Argon2id derives a keyLen byte key from password with Argon2id
+// (RFC 9106). time is the number of passes, memory the memory size in KiB
+// and threads the degree of parallelism (lanes). The argument order follows
+// golang.org/x/crypto/argon2.IDKey. Argon2 requires OpenSSL 3.2 or later.
+func Argon2id(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) ([]byte, error) {
+	if time < 1 {
+		return nil, errors.New("openssl: Argon2 time must be at least 1")
+	}
+	if threads < 1 {
+		return nil, errors.New("openssl: Argon2 threads must be at least 1")
+	}
+	return deriveKey(c.Str("ARGON2ID"), int(keyLen), func(bld *OSSL_PARAM_BLD) bool {
+		// The lanes are computed on the calling thread: the output depends
+		// on the number of lanes only, and more threads would require
+		// OSSL_set_max_threads.
+		return bld.PushBytes(c.Str("pass"), password) == 1 &&
+			bld.PushBytes(c.Str("salt"), salt) == 1 &&
+			bld.PushUint32(c.Str("iter"), time) == 1 &&
+			bld.PushUint32(c.Str("memcost"), memory) == 1 &&
+			bld.PushUint32(c.Str("lanes"), uint32(threads)) == 1 &&
+			bld.PushUint32(c.Str("threads"), 1) == 1
+	})
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,571 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openssl
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"io"
+	"runtime"
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+// -----------------------------------------------------------------------------
+
+var errVerification = errors.New("openssl: verification error")
+
+// hashToMD returns the EVP_MD for h, or nil if h is 0 (raw digest).
+func hashToMD(h crypto.Hash) (*EVP_MD, error) {
+	switch h {
+	case 0:
+		return nil, nil
+	case crypto.SHA1:
+		return EVP_sha1(), nil
+	case crypto.SHA224:
+		return EVP_sha224(), nil
+	case crypto.SHA256:
+		return EVP_sha256(), nil
+	case crypto.SHA384:
+		return EVP_sha384(), nil
+	case crypto.SHA512:
+		return EVP_sha512(), nil
+	case crypto.SHA512_224:
+		return EVP_sha512_224(), nil
+	case crypto.SHA512_256:
+		return EVP_sha512_256(), nil
+	}
+	return nil, errors.New("openssl: unsupported hash function")
+}
+
+func curveNID(curve elliptic.Curve) (c.Int, error) {
+	switch curve {
+	case elliptic.P224():
+		return NID_secp224r1, nil
+	case elliptic.P256():
+		return NID_X9_62_prime256v1, nil
+	case elliptic.P384():
+		return NID_secp384r1, nil
+	case elliptic.P521():
+		return NID_secp521r1, nil
+	}
+	return 0, errors.New("openssl: unsupported elliptic curve")
+}
+
+// pssSaltLength maps an rsa.PSSOptions salt length to its OpenSSL value.
+func pssSaltLength(saltLength int, sign bool) c.Int {
+	switch saltLength {
+	case rsa.PSSSaltLengthAuto:
+		if sign {
+			return RSA_PSS_SALTLEN_MAX
+		}
+		return RSA_PSS_SALTLEN_AUTO
+	case rsa.PSSSaltLengthEqualsHash:
+		return RSA_PSS_SALTLEN_DIGEST
+	}
+	return c.Int(saltLength)
+}
+
+// setupRSASign configures ctx for an RSA signature operation.
+func setupRSASign(ctx *EVP_PKEY_CTX, md *EVP_MD, opts crypto.SignerOpts, sign bool) error {
+	pss, ok := opts.(*rsa.PSSOptions)
+	if !ok {
+		if ctx.SetRSAPadding(RSA_PKCS1_PADDING) != 1 {
+			return NewError("EVP_PKEY_CTX_set_rsa_padding")
+		}
+		return nil
+	}
+	if ctx.SetRSAPadding(RSA_PKCS1_PSS_PADDING) != 1 {
+		return NewError("EVP_PKEY_CTX_set_rsa_padding")
+	}
+	if ctx.SetRSAPSSSaltlen(pssSaltLength(pss.SaltLength, sign)) != 1 {
+		return NewError("EVP_PKEY_CTX_set_rsa_pss_saltlen")
+	}
+	if ctx.SetRSAMgf1MD(md) != 1 {
+		return NewError("EVP_PKEY_CTX_set_rsa_mgf1_md")
+	}
+	return nil
+}
+
+// setupRSACrypt configures ctx for an RSA encryption or decryption.
+func setupRSACrypt(ctx *EVP_PKEY_CTX, opts crypto.DecrypterOpts) error {
+	switch o := opts.(type) {
+	case nil, *rsa.PKCS1v15DecryptOptions:
+		if ctx.SetRSAPadding(RSA_PKCS1_PADDING) != 1 {
+			return NewError("EVP_PKEY_CTX_set_rsa_padding")
+		}
+		return nil
+	case *rsa.OAEPOptions:
+		md, err := hashToMD(o.Hash)
+		if err != nil {
+			return err
+		}
+		mgf1 := md
+		if o.MGFHash != 0 {
+			if mgf1, err = hashToMD(o.MGFHash); err != nil {
+				return err
+			}
+		}
+		if ctx.SetRSAPadding(RSA_PKCS1_OAEP_PADDING) != 1 {
+			return NewError("EVP_PKEY_CTX_set_rsa_padding")
+		}
+		if ctx.SetRSAOAEPMD(md) != 1 {
+			return NewError("EVP_PKEY_CTX_set_rsa_oaep_md")
+		}
+		if ctx.SetRSAMgf1MD(mgf1) != 1 {
+			return NewError("EVP_PKEY_CTX_set_rsa_mgf1_md")
+		}
+		if len(o.Label) > 0 {
+			// OpenSSL takes ownership of the label.
+			label := Malloc(uintptr(len(o.Label)))
+			c.Memcpy(label, unsafe.Pointer(unsafe.SliceData(o.Label)), uintptr(len(o.Label)))
+			if ctx.Set0RSAOAEPLabel(label, c.Int(len(o.Label))) != 1 {
+				Free(label)
+				return NewError("EVP_PKEY_CTX_set0_rsa_oaep_label")
+			}
+		}
+		return nil
+	}
+	return errors.New("openssl: unsupported decrypter options")
+}
+
+// -----------------------------------------------------------------------------
+
+// PrivateKey is a private key held by OpenSSL. It implements crypto.Signer
+// and crypto.Decrypter.
+type PrivateKey struct {
+	pkey *EVP_PKEY
+}
+
+func newPrivateKey(pkey *EVP_PKEY) *PrivateKey {
+	k := &PrivateKey{pkey: pkey}
+	runtime.SetFinalizer(k, (*PrivateKey).free)
+	return k
+}
+
+func (k *PrivateKey) free() {
+	k.pkey.Free()
+}
+
+func generateKey(id c.Int, setup func(ctx *EVP_PKEY_CTX) error) (*PrivateKey, error) {
+	ctx := EVP_PKEY_CTXNewID(id, nil)
+	if ctx == nil {
+		return nil, NewError("EVP_PKEY_CTX_new_id")
+	}
+	defer ctx.Free()
+	if ctx.KeygenInit() != 1 {
+		return nil, NewError("EVP_PKEY_keygen_init")
+	}
+	if setup != nil {
+		if err := setup(ctx); err != nil {
+			return nil, err
+		}
+	}
+	var pkey *EVP_PKEY
+	if ctx.Keygen(&pkey) != 1 {
+		return nil, NewError("EVP_PKEY_keygen")
+	}
+	return newPrivateKey(pkey), nil
+}
+
+// GenerateRSAKey generates an RSA key pair of the given bit size.
+func GenerateRSAKey(bits int) (*PrivateKey, error) {
+	return generateKey(EVP_PKEY_RSA, func(ctx *EVP_PKEY_CTX) error {
+		if ctx.SetRSAKeygenBits(c.Int(bits)) != 1 {
+			return NewError("EVP_PKEY_CTX_set_rsa_keygen_bits")
+		}
+		return nil
+	})
+}
+
+// GenerateECDSAKey generates an ECDSA key pair on one of the NIST curves.
+func GenerateECDSAKey(curve elliptic.Curve) (*PrivateKey, error) {
+	nid, err := curveNID(curve)
+	if err != nil {
+		return nil, err
+	}
+	return generateKey(EVP_PKEY_EC, func(ctx *EVP_PKEY_CTX) error {
+		if ctx.SetECParamgenCurveNID(nid) != 1 {
+			return NewError("EVP_PKEY_CTX_set_ec_paramgen_curve_nid")
+		}
+		return nil
+	})
+}
+
+// GenerateEd25519Key generates an Ed25519 key pair.
+func GenerateEd25519Key() (*PrivateKey, error) {
+	return generateKey(EVP_PKEY_ED25519, nil)
+}
+
+// ParsePrivateKeyPEM parses a PEM encoded private key in PKCS #8 or a
+// traditional format ("RSA PRIVATE KEY", "EC PRIVATE KEY").
+func ParsePrivateKeyPEM(data []byte) (*PrivateKey, error) {
+	if len(data) == 0 {
+		return nil, errors.New("openssl: empty PEM data")
+	}
+	bio := BIONewMemBuf(unsafe.Pointer(unsafe.SliceData(data)), c.Int(len(data)))
+	if bio == nil {
+		return nil, NewError("BIO_new_mem_buf")
+	}
+	defer bio.Free()
+	pkey := PEMReadBioPrivateKey(bio, nil, nil, nil)
+	if pkey == nil {
+		return nil, NewError("PEM_read_bio_PrivateKey")
+	}
+	return newPrivateKey(pkey), nil
+}
+
+// ParsePrivateKeyDER parses a DER encoded private key in PKCS #8 or a
+// traditional format.
+func ParsePrivateKeyDER(der []byte) (*PrivateKey, error) {
+	if len(der) == 0 {
+		return nil, errors.New("openssl: empty DER data")
+	}
+	p := unsafe.SliceData(der)
+	pkey := D2iAutoPrivateKey(nil, &p, c.Long(len(der)))
+	if pkey == nil {
+		return nil, NewError("d2i_AutoPrivateKey")
+	}
+	return newPrivateKey(pkey), nil
+}
+
+// MarshalPEM returns the key as an unencrypted PKCS #8 "PRIVATE KEY" PEM block.
+func (k *PrivateKey) MarshalPEM() ([]byte, error) {
+	bio := BIONew(BIOSMem())
+	if bio == nil {
+		return nil, NewError("BIO_new")
+	}
+	defer bio.Free()
+	if PEMWriteBioPrivateKey(bio, k.pkey, nil, nil, 0, nil, nil) != 1 {
+		return nil, NewError("PEM_write_bio_PrivateKey")
+	}
+	return bioMemBytes(bio), nil
+}
+
+// MarshalDER returns the key in unencrypted PKCS #8 DER form, as accepted
+// by x509.ParsePKCS8PrivateKey.
+func (k *PrivateKey) MarshalDER() ([]byte, error) {
+	bio := BIONew(BIOSMem())
+	if bio == nil {
+		return nil, NewError("BIO_new")
+	}
+	defer bio.Free()
+	if I2dPKCS8PrivateKeyBio(bio, k.pkey, nil, nil, 0, nil, nil) != 1 {
+		return nil, NewError("i2d_PKCS8PrivateKey_bio")
+	}
+	return bioMemBytes(bio), nil
+}
+
+// PublicKey returns the OpenSSL-backed public half of k.
+func (k *PrivateKey) PublicKey() *PublicKey {
+	k.pkey.UpRef()
+	return newPublicKey(k.pkey)
+}
+
+// Public returns the public half of k as a Go crypto key: *rsa.PublicKey,
+// *ecdsa.PublicKey or ed25519.PublicKey. It returns nil if the key cannot
+// be represented that way.
+func (k *PrivateKey) Public() crypto.PublicKey {
+	der, err := k.PublicKey().MarshalDER()
+	if err != nil {
+		return nil
+	}
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil
+	}
+	return pub
+}
+
+// Sign signs digest with k. For RSA and ECDSA keys, digest must be the
+// result of hashing the message with opts.HashFunc(); a *rsa.PSSOptions
+// selects RSA-PSS, otherwise PKCS #1 v1.5 is used. ECDSA signatures are
+// ASN.1 encoded. For Ed25519 keys, digest is the unhashed message and
+// opts.HashFunc() must be zero. rand is ignored: OpenSSL uses its own
+// random source.
+func (k *PrivateKey) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	var hash crypto.Hash
+	if opts != nil {
+		hash = opts.HashFunc()
+	}
+	defer runtime.KeepAlive(k)
+	if k.pkey.BaseID() == EVP_PKEY_ED25519 {
+		if hash != 0 {
+			return nil, errors.New("openssl: Ed25519 requires an unhashed message")
+		}
+		return digestSign(k.pkey, digest)
+	}
+	if hash != 0 && len(digest) != hash.Size() {
+		return nil, errors.New("openssl: input must be hashed message")
+	}
+	md, err := hashToMD(hash)
+	if err != nil {
+		return nil, err
+	}
+	ctx := EVP_PKEY_CTXNew(k.pkey, nil)
+	if ctx == nil {
+		return nil, NewError("EVP_PKEY_CTX_new")
+	}
+	defer ctx.Free()
+	if ctx.SignInit() != 1 {
+		return nil, NewError("EVP_PKEY_sign_init")
+	}
+	if k.pkey.BaseID() == EVP_PKEY_RSA {
+		if err := setupRSASign(ctx, md, opts, true); err != nil {
+			return nil, err
+		}
+	}
+	if md != nil && ctx.SetSignatureMD(md) != 1 {
+		return nil, NewError("EVP_PKEY_CTX_set_signature_md")
+	}
+	var siglen uintptr
+	if ctx.Sign(nil, &siglen, bytesData(digest), uintptr(len(digest))) != 1 {
+		return nil, NewError("EVP_PKEY_sign")
+	}
+	sig := make([]byte, siglen)
+	if ctx.Sign(unsafe.SliceData(sig), &siglen, bytesData(digest), uintptr(len(digest))) != 1 {
+		return nil, NewError("EVP_PKEY_sign")
+	}
+	return sig[:siglen], nil
+}
+
+// Decrypt decrypts msg with an RSA key. opts may be nil or a
+// *rsa.PKCS1v15DecryptOptions for PKCS #1 v1.5, or a *rsa.OAEPOptions for
+// OAEP. rand is ignored.
+func (k *PrivateKey) Decrypt(rand io.Reader, msg []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	defer runtime.KeepAlive(k)
+	ctx := EVP_PKEY_CTXNew(k.pkey, nil)
+	if ctx == nil {
+		return nil, NewError("EVP_PKEY_CTX_new")
+	}
+	defer ctx.Free()
+	if ctx.DecryptInit() != 1 {
+		return nil, NewError("EVP_PKEY_decrypt_init")
+	}
+	if err := setupRSACrypt(ctx, opts); err != nil {
+		return nil, err
+	}
+	var outlen uintptr
+	if ctx.Decrypt(nil, &outlen, bytesData(msg), uintptr(len(msg))) != 1 {
+		return nil, NewError("EVP_PKEY_decrypt")
+	}
+	out := make([]byte, outlen)
+	if ctx.Decrypt(bytesData(out), &outlen, bytesData(msg), uintptr(len(msg))) != 1 {
+		return nil, NewError("EVP_PKEY_decrypt")
+	}
+	return out[:outlen], nil
+}
+
+// digestSign signs an unhashed message, as used by Ed25519.
+func digestSign(pkey *EVP_PKEY, msg []byte) ([]byte, error) {
+	ctx := NewEVP_MD_CTX()
+	if ctx == nil {
+		return nil, NewError("EVP_MD_CTX_new")
+	}
+	defer ctx.Free()
+	if ctx.DigestSignInit(nil, nil, nil, pkey) != 1 {
+		return nil, NewError("EVP_DigestSignInit")
+	}
+	var siglen uintptr
+	if ctx.DigestSign(nil, &siglen, bytesData(msg), uintptr(len(msg))) != 1 {
+		return nil, NewError("EVP_DigestSign")
+	}
+	sig := make([]byte, siglen)
+	if ctx.DigestSign(unsafe.SliceData(sig), &siglen, bytesData(msg), uintptr(len(msg))) != 1 {
+		return nil, NewError("EVP_DigestSign")
+	}
+	return sig[:siglen], nil
+}
+
+// -----------------------------------------------------------------------------
+
+// PublicKey is a public key held by OpenSSL. It verifies signatures made by
+// a PrivateKey and encrypts messages for it.
+type PublicKey struct {
+	pkey *EVP_PKEY
+}
+
+func newPublicKey(pkey *EVP_PKEY) *PublicKey {
+	k := &PublicKey{pkey: pkey}
+	runtime.SetFinalizer(k, (*PublicKey).free)
+	return k
+}
+
+func (k *PublicKey) free() {
+	k.pkey.Free()
+}
+
+// ParsePublicKeyPEM parses a PEM encoded "PUBLIC KEY" (PKIX) block.
+func ParsePublicKeyPEM(data []byte) (*PublicKey, error) {
+	if len(data) == 0 {
+		return nil, errors.New("openssl: empty PEM data")
+	}
+	bio := BIONewMemBuf(unsafe.Pointer(unsafe.SliceData(data)), c.Int(len(data)))
+	if bio == nil {
+		return nil, NewError("BIO_new_mem_buf")
+	}
+	defer bio.Free()
+	pkey := PEMReadBioPUBKEY(bio, nil, nil, nil)
+	if pkey == nil {
+		return nil, NewError("PEM_read_bio_PUBKEY")
+	}
+	return newPublicKey(pkey), nil
+}
+
+// ParsePublicKeyDER parses a DER encoded PKIX public key, as produced by
+// x509.MarshalPKIXPublicKey.
+func ParsePublicKeyDER(der []byte) (*PublicKey, error) {
+	if len(der) == 0 {
+		return nil, errors.New("openssl: empty DER data")
+	}
+	p := unsafe.SliceData(der)
+	pkey := D2iPUBKEY(nil, &p, c.Long(len(der)))
+	if pkey == nil {
+		return nil, NewError("d2i_PUBKEY")
+	}
+	return newPublicKey(pkey), nil
+}
+
+// MarshalPEM returns the key as a PKIX "PUBLIC KEY" PEM block.
+func (k *PublicKey) MarshalPEM() ([]byte, error) {
+	bio := BIONew(BIOSMem())
+	if bio == nil {
+		return nil, NewError("BIO_new")
+	}
+	defer bio.Free()
+	if PEMWriteBioPUBKEY(bio, k.pkey) != 1 {
+		return nil, NewError("PEM_write_bio_PUBKEY")
+	}
+	return bioMemBytes(bio), nil
+}
+
+// MarshalDER returns the key in PKIX DER form.
+func (k *PublicKey) MarshalDER() ([]byte, error) {
+	defer runtime.KeepAlive(k)
+	n := I2dPUBKEY(k.pkey, nil)
+	if n <= 0 {
+		return nil, NewError("i2d_PUBKEY")
+	}
+	der := make([]byte, n)
+	p := unsafe.SliceData(der)
+	if I2dPUBKEY(k.pkey, &p) != n {
+		return nil, NewError("i2d_PUBKEY")
+	}
+	return der, nil
+}
+
+// Verify checks sig against digest. The arguments follow the conventions
+// of PrivateKey.Sign: digest is the hashed message for RSA and ECDSA, and
+// the unhashed message for Ed25519.
+func (k *PublicKey) Verify(digest, sig []byte, opts crypto.SignerOpts) error {
+	var hash crypto.Hash
+	if opts != nil {
+		hash = opts.HashFunc()
+	}
+	defer runtime.KeepAlive(k)
+	if k.pkey.BaseID() == EVP_PKEY_ED25519 {
+		if hash != 0 {
+			return errors.New("openssl: Ed25519 requires an unhashed message")
+		}
+		return digestVerify(k.pkey, digest, sig)
+	}
+	md, err := hashToMD(hash)
+	if err != nil {
+		return err
+	}
+	ctx := EVP_PKEY_CTXNew(k.pkey, nil)
+	if ctx == nil {
+		return NewError("EVP_PKEY_CTX_new")
+	}
+	defer ctx.Free()
+	if ctx.VerifyInit() != 1 {
+		return NewError("EVP_PKEY_verify_init")
+	}
+	if k.pkey.BaseID() == EVP_PKEY_RSA {
+		if err := setupRSASign(ctx, md, opts, false); err != nil {
+			return err
+		}
+	}
+	if md != nil && ctx.SetSignatureMD(md) != 1 {
+		return NewError("EVP_PKEY_CTX_set_signature_md")
+	}
+	if ctx.Verify(bytesData(sig), uintptr(len(sig)), bytesData(digest), uintptr(len(digest))) != 1 {
+		// Drop the reasons, such as bad padding, as for any bad signature.
+		ERRClearError()
+		return errVerification
+	}
+	return nil
+}
+
+// EncryptPKCS1v15 encrypts msg with RSA and PKCS #1 v1.5 padding.
+func (k *PublicKey) EncryptPKCS1v15(msg []byte) ([]byte, error) {
+	return k.encrypt(msg, nil)
+}
+
+// EncryptOAEP encrypts msg with RSA-OAEP, using hash for both OAEP and
+// MGF1.
+func (k *PublicKey) EncryptOAEP(hash crypto.Hash, msg, label []byte) ([]byte, error) {
+	return k.encrypt(msg, &rsa.OAEPOptions{Hash: hash, Label: label})
+}
+
+func (k *PublicKey) encrypt(msg []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	defer runtime.KeepAlive(k)
+	ctx := EVP_PKEY_CTXNew(k.pkey, nil)
+	if ctx == nil {
+		return nil, NewError("EVP_PKEY_CTX_new")
+	}
+	defer ctx.Free()
+	if ctx.EncryptInit() != 1 {
+		return nil, NewError("EVP_PKEY_encrypt_init")
+	}
+	if err := setupRSACrypt(ctx, opts); err != nil {
+		return nil, err
+	}
+	var outlen uintptr
+	if ctx.Encrypt(nil, &outlen, bytesData(msg), uintptr(len(msg))) != 1 {
+		return nil, NewError("EVP_PKEY_encrypt")
+	}
+	out := make([]byte, outlen)
+	if ctx.Encrypt(bytesData(out), &outlen, bytesData(msg), uintptr(len(msg))) != 1 {
+		return nil, NewError("EVP_PKEY_encrypt")
+	}
+	return out[:outlen], nil
+}
+
+// digestVerify verifies a signature over an unhashed message, as used by
+// Ed25519.
+func digestVerify(pkey *EVP_PKEY, msg, sig []byte) error {
+	ctx := NewEVP_MD_CTX()
+	if ctx == nil {
+		return NewError("EVP_MD_CTX_new")
+	}
+	defer ctx.Free()
+	if ctx.DigestVerifyInit(nil, nil, nil, pkey) != 1 {
+		return NewError("EVP_DigestVerifyInit")
+	}
+	if ctx.DigestVerify(bytesData(sig), uintptr(len(sig)), bytesData(msg), uintptr(len(msg))) != 1 {
+		ERRClearError()
+		return errVerification
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
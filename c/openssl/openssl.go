@@ -29,10 +29,33 @@ const (
 	LLGoPackage = "link: $(pkg-config --libs openssl); -lssl -lcrypto"
 )
 
+//go:linkname Malloc C.opensslMalloc
+func Malloc(num uintptr) unsafe.Pointer
+
 //go:linkname Free C.opensslFree
 func Free(ptr unsafe.Pointer)
 
 //go:linkname FreeCStr C.opensslFree
 func FreeCStr(ptr *c.Char)
 
+// int CRYPTO_memcmp(const void *a, const void *b, size_t len);
+//
+// CRYPTOMemcmp compares len bytes in constant time. It returns 0 if they
+// are equal and non-zero otherwise; it does not order its inputs.
+//
+//go:linkname CRYPTOMemcmp C.CRYPTO_memcmp
+func CRYPTOMemcmp(a, b unsafe.Pointer, len uintptr) c.Int
+
+// ConstantTimeEqual reports whether a and b are equal. The time taken
+// depends on the lengths of the slices, not on their contents.
+func ConstantTimeEqual(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	if len(a) == 0 {
+		return true
+	}
+	return CRYPTOMemcmp(unsafe.Pointer(unsafe.SliceData(a)), unsafe.Pointer(unsafe.SliceData(b)), uintptr(len(a))) == 0
+}
+
 // -----------------------------------------------------------------------------
@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openssl
+
+import (
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+// -----------------------------------------------------------------------------
+
+const (
+	OSSL_PARAM_INTEGER          = 1
+	OSSL_PARAM_UNSIGNED_INTEGER = 2
+	OSSL_PARAM_REAL             = 3
+	OSSL_PARAM_UTF8_STRING      = 4
+	OSSL_PARAM_OCTET_STRING     = 5
+	OSSL_PARAM_UTF8_PTR         = 6
+	OSSL_PARAM_OCTET_PTR        = 7
+)
+
+// OSSL_PARAM mirrors struct ossl_param_st. Parameter arrays end with an
+// element whose Key is nil.
+type OSSL_PARAM struct {
+	Key        *c.Char
+	DataType   c.Uint
+	Data       unsafe.Pointer
+	DataSize   uintptr
+	ReturnSize uintptr
+}
+
+// void OSSL_PARAM_free(OSSL_PARAM *p);
+//
+// llgo:link (*OSSL_PARAM).Free C.OSSL_PARAM_free
+func (*OSSL_PARAM) Free() {}
+
+// -----------------------------------------------------------------------------
+
+// OSSL_PARAM_BLD builds an OSSL_PARAM array. Keys are stored by reference
+// and must outlive the built array, so pass static strings such as c.Str
+// literals. Data is copied by ToParam.
+type OSSL_PARAM_BLD struct {
+	Unused [0]byte
+}
+
+// OSSL_PARAM_BLD *OSSL_PARAM_BLD_new(void);
+//
+//go:linkname OSSL_PARAM_BLDNew C.OSSL_PARAM_BLD_new
+func OSSL_PARAM_BLDNew() *OSSL_PARAM_BLD
+
+// void OSSL_PARAM_BLD_free(OSSL_PARAM_BLD *bld);
+//
+// llgo:link (*OSSL_PARAM_BLD).Free C.OSSL_PARAM_BLD_free
+func (*OSSL_PARAM_BLD) Free() {}
+
+// OSSL_PARAM *OSSL_PARAM_BLD_to_param(OSSL_PARAM_BLD *bld);
+//
+// llgo:link (*OSSL_PARAM_BLD).ToParam C.OSSL_PARAM_BLD_to_param
+func (*OSSL_PARAM_BLD) ToParam() *OSSL_PARAM { return nil }
+
+// int OSSL_PARAM_BLD_push_int(OSSL_PARAM_BLD *bld, const char *key, int val);
+//
+// llgo:link (*OSSL_PARAM_BLD).PushInt C.OSSL_PARAM_BLD_push_int
+func (*OSSL_PARAM_BLD) PushInt(key *c.Char, val c.Int) c.Int { return 0 }
+
+// int OSSL_PARAM_BLD_push_uint(OSSL_PARAM_BLD *bld, const char *key, unsigned int val);
+//
+// llgo:link (*OSSL_PARAM_BLD).PushUint C.OSSL_PARAM_BLD_push_uint
+func (*OSSL_PARAM_BLD) PushUint(key *c.Char, val c.Uint) c.Int { return 0 }
+
+// int OSSL_PARAM_BLD_push_int64(OSSL_PARAM_BLD *bld, const char *key, int64_t val);
+//
+// llgo:link (*OSSL_PARAM_BLD).PushInt64 C.OSSL_PARAM_BLD_push_int64
+func (*OSSL_PARAM_BLD) PushInt64(key *c.Char, val int64) c.Int { return 0 }
+
+// int OSSL_PARAM_BLD_push_uint32(OSSL_PARAM_BLD *bld, const char *key, uint32_t val);
+//
+// llgo:link (*OSSL_PARAM_BLD).PushUint32 C.OSSL_PARAM_BLD_push_uint32
+func (*OSSL_PARAM_BLD) PushUint32(key *c.Char, val uint32) c.Int { return 0 }
+
+// int OSSL_PARAM_BLD_push_uint64(OSSL_PARAM_BLD *bld, const char *key, uint64_t val);
+//
+// llgo:link (*OSSL_PARAM_BLD).PushUint64 C.OSSL_PARAM_BLD_push_uint64
+func (*OSSL_PARAM_BLD) PushUint64(key *c.Char, val uint64) c.Int { return 0 }
+
+// int OSSL_PARAM_BLD_push_size_t(OSSL_PARAM_BLD *bld, const char *key, size_t val);
+//
+// llgo:link (*OSSL_PARAM_BLD).PushSizeT C.OSSL_PARAM_BLD_push_size_t
+func (*OSSL_PARAM_BLD) PushSizeT(key *c.Char, val uintptr) c.Int { return 0 }
+
+// int OSSL_PARAM_BLD_push_BN(OSSL_PARAM_BLD *bld, const char *key, const BIGNUM *bn);
+//
+// llgo:link (*OSSL_PARAM_BLD).PushBN C.OSSL_PARAM_BLD_push_BN
+func (*OSSL_PARAM_BLD) PushBN(key *c.Char, bn *BIGNUM) c.Int { return 0 }
+
+// int OSSL_PARAM_BLD_push_utf8_string(OSSL_PARAM_BLD *bld, const char *key,
+// const char *buf, size_t bsize);
+//
+// llgo:link (*OSSL_PARAM_BLD).PushUTF8String C.OSSL_PARAM_BLD_push_utf8_string
+func (*OSSL_PARAM_BLD) PushUTF8String(key *c.Char, buf *c.Char, bsize uintptr) c.Int { return 0 }
+
+// int OSSL_PARAM_BLD_push_octet_string(OSSL_PARAM_BLD *bld, const char *key,
+// const void *buf, size_t bsize);
+//
+// llgo:link (*OSSL_PARAM_BLD).PushOctetString C.OSSL_PARAM_BLD_push_octet_string
+func (*OSSL_PARAM_BLD) PushOctetString(key *c.Char, buf unsafe.Pointer, bsize uintptr) c.Int {
+	return 0
+}
+
+func (bld *OSSL_PARAM_BLD) PushBytes(key *c.Char, buf []byte) c.Int {
+	return bld.PushOctetString(key, unsafe.Pointer(unsafe.SliceData(buf)), uintptr(len(buf)))
+}
+
+// -----------------------------------------------------------------------------
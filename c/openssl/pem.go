@@ -34,4 +34,26 @@ type PemPasswordCb func(buf *c.Char, size, rwflag c.Int, userdata unsafe.Pointer
 //go:linkname PEMReadBioRSAPrivateKey C.PEM_read_bio_RSAPrivateKey
 func PEMReadBioRSAPrivateKey(bp *BIO, x **RSA, cb PemPasswordCb, u unsafe.Pointer) *RSA
 
+// EVP_PKEY *PEM_read_bio_PrivateKey(BIO *bp, EVP_PKEY **x, pem_password_cb *cb, void *u);
+//
+//go:linkname PEMReadBioPrivateKey C.PEM_read_bio_PrivateKey
+func PEMReadBioPrivateKey(bp *BIO, x **EVP_PKEY, cb PemPasswordCb, u unsafe.Pointer) *EVP_PKEY
+
+// EVP_PKEY *PEM_read_bio_PUBKEY(BIO *bp, EVP_PKEY **x, pem_password_cb *cb, void *u);
+//
+//go:linkname PEMReadBioPUBKEY C.PEM_read_bio_PUBKEY
+func PEMReadBioPUBKEY(bp *BIO, x **EVP_PKEY, cb PemPasswordCb, u unsafe.Pointer) *EVP_PKEY
+
+// int PEM_write_bio_PrivateKey(BIO *bp, const EVP_PKEY *x, const EVP_CIPHER *enc,
+// const unsigned char *kstr, int klen, pem_password_cb *cb, void *u);
+//
+//go:linkname PEMWriteBioPrivateKey C.PEM_write_bio_PrivateKey
+func PEMWriteBioPrivateKey(
+	bp *BIO, x *EVP_PKEY, enc *EVP_CIPHER, kstr *byte, klen c.Int, cb PemPasswordCb, u unsafe.Pointer) c.Int
+
+// int PEM_write_bio_PUBKEY(BIO *bp, const EVP_PKEY *x);
+//
+//go:linkname PEMWriteBioPUBKEY C.PEM_write_bio_PUBKEY
+func PEMWriteBioPUBKEY(bp *BIO, x *EVP_PKEY) c.Int
+
 // -----------------------------------------------------------------------------
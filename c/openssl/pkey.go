@@ -0,0 +1,323 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openssl
+
+import (
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+// -----------------------------------------------------------------------------
+
+const (
+	EVP_PKEY_NONE    = 0
+	EVP_PKEY_RSA     = 6
+	EVP_PKEY_EC      = 408
+	EVP_PKEY_RSA_PSS = 912
+	EVP_PKEY_X25519  = 1034
+	EVP_PKEY_ED25519 = 1087
+	EVP_PKEY_X448    = 1035
+	EVP_PKEY_ED448   = 1088
+)
+
+const (
+	NID_secp224r1        = 713
+	NID_X9_62_prime256v1 = 415
+	NID_secp384r1        = 715
+	NID_secp521r1        = 716
+)
+
+const (
+	RSA_PKCS1_PADDING      = 1
+	RSA_NO_PADDING         = 3
+	RSA_PKCS1_OAEP_PADDING = 4
+	RSA_X931_PADDING       = 5
+	RSA_PKCS1_PSS_PADDING  = 6
+
+	RSA_PSS_SALTLEN_DIGEST = -1
+	RSA_PSS_SALTLEN_AUTO   = -2
+	RSA_PSS_SALTLEN_MAX    = -3
+)
+
+// -----------------------------------------------------------------------------
+
+type EVP_PKEY struct {
+	Unused [0]byte
+}
+
+// EVP_PKEY *EVP_PKEY_new(void);
+//
+//go:linkname EVP_PKEYNew C.EVP_PKEY_new
+func EVP_PKEYNew() *EVP_PKEY
+
+// void EVP_PKEY_free(EVP_PKEY *pkey);
+//
+// llgo:link (*EVP_PKEY).Free C.EVP_PKEY_free
+func (*EVP_PKEY) Free() {}
+
+// int EVP_PKEY_up_ref(EVP_PKEY *key);
+//
+// llgo:link (*EVP_PKEY).UpRef C.EVP_PKEY_up_ref
+func (*EVP_PKEY) UpRef() c.Int { return 0 }
+
+// int EVP_PKEY_get_id(const EVP_PKEY *pkey);
+//
+// llgo:link (*EVP_PKEY).ID C.EVP_PKEY_get_id
+func (*EVP_PKEY) ID() c.Int { return 0 }
+
+// int EVP_PKEY_get_base_id(const EVP_PKEY *pkey);
+//
+// llgo:link (*EVP_PKEY).BaseID C.EVP_PKEY_get_base_id
+func (*EVP_PKEY) BaseID() c.Int { return 0 }
+
+// int EVP_PKEY_get_bits(const EVP_PKEY *pkey);
+//
+// llgo:link (*EVP_PKEY).Bits C.EVP_PKEY_get_bits
+func (*EVP_PKEY) Bits() c.Int { return 0 }
+
+// int EVP_PKEY_get_security_bits(const EVP_PKEY *pkey);
+//
+// llgo:link (*EVP_PKEY).SecurityBits C.EVP_PKEY_get_security_bits
+func (*EVP_PKEY) SecurityBits() c.Int { return 0 }
+
+// int EVP_PKEY_get_size(const EVP_PKEY *pkey);
+//
+// llgo:link (*EVP_PKEY).Size C.EVP_PKEY_get_size
+func (*EVP_PKEY) Size() c.Int { return 0 }
+
+// int EVP_PKEY_eq(const EVP_PKEY *a, const EVP_PKEY *b);
+//
+// llgo:link (*EVP_PKEY).Eq C.EVP_PKEY_eq
+func (*EVP_PKEY) Eq(b *EVP_PKEY) c.Int { return 0 }
+
+// EVP_PKEY *d2i_AutoPrivateKey(EVP_PKEY **a, const unsigned char **pp, long length);
+//
+//go:linkname D2iAutoPrivateKey C.d2i_AutoPrivateKey
+func D2iAutoPrivateKey(a **EVP_PKEY, pp **byte, length c.Long) *EVP_PKEY
+
+// EVP_PKEY *d2i_PUBKEY(EVP_PKEY **a, const unsigned char **pp, long length);
+//
+//go:linkname D2iPUBKEY C.d2i_PUBKEY
+func D2iPUBKEY(a **EVP_PKEY, pp **byte, length c.Long) *EVP_PKEY
+
+// int i2d_PrivateKey(const EVP_PKEY *a, unsigned char **pp);
+//
+//go:linkname I2dPrivateKey C.i2d_PrivateKey
+func I2dPrivateKey(a *EVP_PKEY, pp **byte) c.Int
+
+// int i2d_PUBKEY(const EVP_PKEY *a, unsigned char **pp);
+//
+//go:linkname I2dPUBKEY C.i2d_PUBKEY
+func I2dPUBKEY(a *EVP_PKEY, pp **byte) c.Int
+
+// int i2d_PKCS8PrivateKey_bio(BIO *bp, const EVP_PKEY *x, const EVP_CIPHER *enc,
+// const char *kstr, int klen, pem_password_cb *cb, void *u);
+//
+//go:linkname I2dPKCS8PrivateKeyBio C.i2d_PKCS8PrivateKey_bio
+func I2dPKCS8PrivateKeyBio(
+	bp *BIO, x *EVP_PKEY, enc *EVP_CIPHER, kstr *c.Char, klen c.Int, cb PemPasswordCb, u unsafe.Pointer) c.Int
+
+// -----------------------------------------------------------------------------
+
+type EVP_PKEY_CTX struct {
+	Unused [0]byte
+}
+
+// EVP_PKEY_CTX *EVP_PKEY_CTX_new(EVP_PKEY *pkey, ENGINE *e);
+//
+//go:linkname EVP_PKEY_CTXNew C.EVP_PKEY_CTX_new
+func EVP_PKEY_CTXNew(pkey *EVP_PKEY, e unsafe.Pointer) *EVP_PKEY_CTX
+
+// EVP_PKEY_CTX *EVP_PKEY_CTX_new_id(int id, ENGINE *e);
+//
+//go:linkname EVP_PKEY_CTXNewID C.EVP_PKEY_CTX_new_id
+func EVP_PKEY_CTXNewID(id c.Int, e unsafe.Pointer) *EVP_PKEY_CTX
+
+// void EVP_PKEY_CTX_free(EVP_PKEY_CTX *ctx);
+//
+// llgo:link (*EVP_PKEY_CTX).Free C.EVP_PKEY_CTX_free
+func (*EVP_PKEY_CTX) Free() {}
+
+// int EVP_PKEY_keygen_init(EVP_PKEY_CTX *ctx);
+//
+// llgo:link (*EVP_PKEY_CTX).KeygenInit C.EVP_PKEY_keygen_init
+func (*EVP_PKEY_CTX) KeygenInit() c.Int { return 0 }
+
+// int EVP_PKEY_keygen(EVP_PKEY_CTX *ctx, EVP_PKEY **ppkey);
+//
+// llgo:link (*EVP_PKEY_CTX).Keygen C.EVP_PKEY_keygen
+func (*EVP_PKEY_CTX) Keygen(ppkey **EVP_PKEY) c.Int { return 0 }
+
+// int EVP_PKEY_CTX_set_rsa_keygen_bits(EVP_PKEY_CTX *ctx, int bits);
+//
+// llgo:link (*EVP_PKEY_CTX).SetRSAKeygenBits C.EVP_PKEY_CTX_set_rsa_keygen_bits
+func (*EVP_PKEY_CTX) SetRSAKeygenBits(bits c.Int) c.Int { return 0 }
+
+// int EVP_PKEY_CTX_set_ec_paramgen_curve_nid(EVP_PKEY_CTX *ctx, int nid);
+//
+// llgo:link (*EVP_PKEY_CTX).SetECParamgenCurveNID C.EVP_PKEY_CTX_set_ec_paramgen_curve_nid
+func (*EVP_PKEY_CTX) SetECParamgenCurveNID(nid c.Int) c.Int { return 0 }
+
+// int EVP_PKEY_CTX_set_signature_md(EVP_PKEY_CTX *ctx, const EVP_MD *md);
+//
+// llgo:link (*EVP_PKEY_CTX).SetSignatureMD C.EVP_PKEY_CTX_set_signature_md
+func (*EVP_PKEY_CTX) SetSignatureMD(md *EVP_MD) c.Int { return 0 }
+
+// int EVP_PKEY_CTX_set_rsa_padding(EVP_PKEY_CTX *ctx, int pad);
+//
+// llgo:link (*EVP_PKEY_CTX).SetRSAPadding C.EVP_PKEY_CTX_set_rsa_padding
+func (*EVP_PKEY_CTX) SetRSAPadding(pad c.Int) c.Int { return 0 }
+
+// int EVP_PKEY_CTX_set_rsa_pss_saltlen(EVP_PKEY_CTX *ctx, int saltlen);
+//
+// llgo:link (*EVP_PKEY_CTX).SetRSAPSSSaltlen C.EVP_PKEY_CTX_set_rsa_pss_saltlen
+func (*EVP_PKEY_CTX) SetRSAPSSSaltlen(saltlen c.Int) c.Int { return 0 }
+
+// int EVP_PKEY_CTX_set_rsa_mgf1_md(EVP_PKEY_CTX *ctx, const EVP_MD *md);
+//
+// llgo:link (*EVP_PKEY_CTX).SetRSAMgf1MD C.EVP_PKEY_CTX_set_rsa_mgf1_md
+func (*EVP_PKEY_CTX) SetRSAMgf1MD(md *EVP_MD) c.Int { return 0 }
+
+// int EVP_PKEY_CTX_set_rsa_oaep_md(EVP_PKEY_CTX *ctx, const EVP_MD *md);
+//
+// llgo:link (*EVP_PKEY_CTX).SetRSAOAEPMD C.EVP_PKEY_CTX_set_rsa_oaep_md
+func (*EVP_PKEY_CTX) SetRSAOAEPMD(md *EVP_MD) c.Int { return 0 }
+
+// int EVP_PKEY_CTX_set0_rsa_oaep_label(EVP_PKEY_CTX *ctx, void *label, int llen);
+//
+// llgo:link (*EVP_PKEY_CTX).Set0RSAOAEPLabel C.EVP_PKEY_CTX_set0_rsa_oaep_label
+func (*EVP_PKEY_CTX) Set0RSAOAEPLabel(label unsafe.Pointer, llen c.Int) c.Int { return 0 }
+
+// int EVP_PKEY_sign_init(EVP_PKEY_CTX *ctx);
+//
+// llgo:link (*EVP_PKEY_CTX).SignInit C.EVP_PKEY_sign_init
+func (*EVP_PKEY_CTX) SignInit() c.Int { return 0 }
+
+// int EVP_PKEY_sign(EVP_PKEY_CTX *ctx, unsigned char *sig, size_t *siglen,
+// const unsigned char *tbs, size_t tbslen);
+//
+// llgo:link (*EVP_PKEY_CTX).Sign C.EVP_PKEY_sign
+func (*EVP_PKEY_CTX) Sign(sig *byte, siglen *uintptr, tbs *byte, tbslen uintptr) c.Int { return 0 }
+
+// int EVP_PKEY_verify_init(EVP_PKEY_CTX *ctx);
+//
+// llgo:link (*EVP_PKEY_CTX).VerifyInit C.EVP_PKEY_verify_init
+func (*EVP_PKEY_CTX) VerifyInit() c.Int { return 0 }
+
+// int EVP_PKEY_verify(EVP_PKEY_CTX *ctx, const unsigned char *sig, size_t siglen,
+// const unsigned char *tbs, size_t tbslen);
+//
+// llgo:link (*EVP_PKEY_CTX).Verify C.EVP_PKEY_verify
+func (*EVP_PKEY_CTX) Verify(sig *byte, siglen uintptr, tbs *byte, tbslen uintptr) c.Int { return 0 }
+
+// int EVP_PKEY_encrypt_init(EVP_PKEY_CTX *ctx);
+//
+// llgo:link (*EVP_PKEY_CTX).EncryptInit C.EVP_PKEY_encrypt_init
+func (*EVP_PKEY_CTX) EncryptInit() c.Int { return 0 }
+
+// int EVP_PKEY_encrypt(EVP_PKEY_CTX *ctx, unsigned char *out, size_t *outlen,
+// const unsigned char *in, size_t inlen);
+//
+// llgo:link (*EVP_PKEY_CTX).Encrypt C.EVP_PKEY_encrypt
+func (*EVP_PKEY_CTX) Encrypt(out *byte, outlen *uintptr, in *byte, inlen uintptr) c.Int { return 0 }
+
+// int EVP_PKEY_decrypt_init(EVP_PKEY_CTX *ctx);
+//
+// llgo:link (*EVP_PKEY_CTX).DecryptInit C.EVP_PKEY_decrypt_init
+func (*EVP_PKEY_CTX) DecryptInit() c.Int { return 0 }
+
+// int EVP_PKEY_decrypt(EVP_PKEY_CTX *ctx, unsigned char *out, size_t *outlen,
+// const unsigned char *in, size_t inlen);
+//
+// llgo:link (*EVP_PKEY_CTX).Decrypt C.EVP_PKEY_decrypt
+func (*EVP_PKEY_CTX) Decrypt(out *byte, outlen *uintptr, in *byte, inlen uintptr) c.Int { return 0 }
+
+// -----------------------------------------------------------------------------
+
+type EVP_MD_CTX struct {
+	Unused [0]byte
+}
+
+// EVP_MD_CTX *EVP_MD_CTX_new(void);
+//
+//go:linkname NewEVP_MD_CTX C.EVP_MD_CTX_new
+func NewEVP_MD_CTX() *EVP_MD_CTX
+
+// void EVP_MD_CTX_free(EVP_MD_CTX *ctx);
+//
+// llgo:link (*EVP_MD_CTX).Free C.EVP_MD_CTX_free
+func (*EVP_MD_CTX) Free() {}
+
+// int EVP_MD_CTX_reset(EVP_MD_CTX *ctx);
+//
+// llgo:link (*EVP_MD_CTX).Reset C.EVP_MD_CTX_reset
+func (*EVP_MD_CTX) Reset() c.Int { return 0 }
+
+// int EVP_DigestSignInit(EVP_MD_CTX *ctx, EVP_PKEY_CTX **pctx,
+// const EVP_MD *type, ENGINE *e, EVP_PKEY *pkey);
+//
+// llgo:link (*EVP_MD_CTX).DigestSignInit C.EVP_DigestSignInit
+func (*EVP_MD_CTX) DigestSignInit(pctx **EVP_PKEY_CTX, typ *EVP_MD, e unsafe.Pointer, pkey *EVP_PKEY) c.Int {
+	return 0
+}
+
+// int EVP_DigestSignUpdate(EVP_MD_CTX *ctx, const void *d, size_t cnt);
+//
+// llgo:link (*EVP_MD_CTX).DigestSignUpdate C.EVP_DigestSignUpdate
+func (*EVP_MD_CTX) DigestSignUpdate(d unsafe.Pointer, cnt uintptr) c.Int { return 0 }
+
+// int EVP_DigestSignFinal(EVP_MD_CTX *ctx, unsigned char *sig, size_t *siglen);
+//
+// llgo:link (*EVP_MD_CTX).DigestSignFinal C.EVP_DigestSignFinal
+func (*EVP_MD_CTX) DigestSignFinal(sig *byte, siglen *uintptr) c.Int { return 0 }
+
+// int EVP_DigestSign(EVP_MD_CTX *ctx, unsigned char *sigret, size_t *siglen,
+// const unsigned char *tbs, size_t tbslen);
+//
+// llgo:link (*EVP_MD_CTX).DigestSign C.EVP_DigestSign
+func (*EVP_MD_CTX) DigestSign(sigret *byte, siglen *uintptr, tbs *byte, tbslen uintptr) c.Int {
+	return 0
+}
+
+// int EVP_DigestVerifyInit(EVP_MD_CTX *ctx, EVP_PKEY_CTX **pctx,
+// const EVP_MD *type, ENGINE *e, EVP_PKEY *pkey);
+//
+// llgo:link (*EVP_MD_CTX).DigestVerifyInit C.EVP_DigestVerifyInit
+func (*EVP_MD_CTX) DigestVerifyInit(pctx **EVP_PKEY_CTX, typ *EVP_MD, e unsafe.Pointer, pkey *EVP_PKEY) c.Int {
+	return 0
+}
+
+// int EVP_DigestVerifyUpdate(EVP_MD_CTX *ctx, const void *d, size_t cnt);
+//
+// llgo:link (*EVP_MD_CTX).DigestVerifyUpdate C.EVP_DigestVerifyUpdate
+func (*EVP_MD_CTX) DigestVerifyUpdate(d unsafe.Pointer, cnt uintptr) c.Int { return 0 }
+
+// int EVP_DigestVerifyFinal(EVP_MD_CTX *ctx, const unsigned char *sig, size_t siglen);
+//
+// llgo:link (*EVP_MD_CTX).DigestVerifyFinal C.EVP_DigestVerifyFinal
+func (*EVP_MD_CTX) DigestVerifyFinal(sig *byte, siglen uintptr) c.Int { return 0 }
+
+// int EVP_DigestVerify(EVP_MD_CTX *ctx, const unsigned char *sigret, size_t siglen,
+// const unsigned char *tbs, size_t tbslen);
+//
+// llgo:link (*EVP_MD_CTX).DigestVerify C.EVP_DigestVerify
+func (*EVP_MD_CTX) DigestVerify(sigret *byte, siglen uintptr, tbs *byte, tbslen uintptr) c.Int {
+	return 0
+}
+
+// -----------------------------------------------------------------------------
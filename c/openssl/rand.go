@@ -29,8 +29,9 @@ import (
 //go:linkname RANDBufferWithLen C.RAND_bytes
 func RANDBufferWithLen(buf *byte, num c.Int) c.Int
 
-func RANDBytes(buf []byte) c.Int {
-	return RANDBufferWithLen(unsafe.SliceData(buf), c.Int(len(buf)))
+// RANDBytes fills buf with cryptographically secure random bytes.
+func RANDBytes(buf []byte) error {
+	return Check("RAND_bytes", RANDBufferWithLen(unsafe.SliceData(buf), c.Int(len(buf))))
 }
 
 // int RAND_priv_bytes(unsigned char *buf, int num);
@@ -38,8 +39,10 @@ func RANDBytes(buf []byte) c.Int {
 //go:linkname RANDPrivBufferWithLen C.RAND_priv_bytes
 func RANDPrivBufferWithLen(buf *byte, num c.Int) c.Int
 
-func RANDPrivBytes(buf []byte) c.Int {
-	return RANDPrivBufferWithLen(unsafe.SliceData(buf), c.Int(len(buf)))
+// RANDPrivBytes is like RANDBytes, but draws from the generator reserved
+// for private values such as keys.
+func RANDPrivBytes(buf []byte) error {
+	return Check("RAND_priv_bytes", RANDPrivBufferWithLen(unsafe.SliceData(buf), c.Int(len(buf))))
 }
 
 // void RAND_seed(const void *buf, int num);
@@ -0,0 +1,422 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openssl
+
+import (
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+// -----------------------------------------------------------------------------
+
+const (
+	SSL_ERROR_NONE             = 0
+	SSL_ERROR_SSL              = 1
+	SSL_ERROR_WANT_READ        = 2
+	SSL_ERROR_WANT_WRITE       = 3
+	SSL_ERROR_WANT_X509_LOOKUP = 4
+	SSL_ERROR_SYSCALL          = 5
+	SSL_ERROR_ZERO_RETURN      = 6
+	SSL_ERROR_WANT_CONNECT     = 7
+	SSL_ERROR_WANT_ACCEPT      = 8
+)
+
+const (
+	SSL_VERIFY_NONE                 = 0x00
+	SSL_VERIFY_PEER                 = 0x01
+	SSL_VERIFY_FAIL_IF_NO_PEER_CERT = 0x02
+	SSL_VERIFY_CLIENT_ONCE          = 0x04
+)
+
+const (
+	SSL_FILETYPE_PEM  = 1
+	SSL_FILETYPE_ASN1 = 2
+)
+
+const (
+	TLS1_VERSION   = 0x0301
+	TLS1_1_VERSION = 0x0302
+	TLS1_2_VERSION = 0x0303
+	TLS1_3_VERSION = 0x0304
+)
+
+const (
+	SSL_SESS_CACHE_OFF    = 0x0000
+	SSL_SESS_CACHE_CLIENT = 0x0001
+	SSL_SESS_CACHE_SERVER = 0x0002
+	SSL_SESS_CACHE_BOTH   = SSL_SESS_CACHE_CLIENT | SSL_SESS_CACHE_SERVER
+)
+
+const (
+	SSL_MODE_ENABLE_PARTIAL_WRITE       = 0x00000001
+	SSL_MODE_ACCEPT_MOVING_WRITE_BUFFER = 0x00000002
+	SSL_MODE_AUTO_RETRY                 = 0x00000004
+	SSL_MODE_RELEASE_BUFFERS            = 0x00000010
+)
+
+const (
+	SSL_CTRL_SET_SESS_CACHE_MODE   = 44
+	SSL_CTRL_MODE                  = 33
+	SSL_CTRL_SET_TLSEXT_HOSTNAME   = 55
+	SSL_CTRL_CHAIN_CERT            = 89
+	SSL_CTRL_SET_MIN_PROTO_VERSION = 123
+	SSL_CTRL_SET_MAX_PROTO_VERSION = 124
+	TLSEXT_NAMETYPE_host_name      = 0
+	SSL_TLSEXT_ERR_OK              = 0
+	SSL_TLSEXT_ERR_ALERT_FATAL     = 2
+	SSL_TLSEXT_ERR_NOACK           = 3
+	OPENSSL_NPN_NEGOTIATED         = 1
+	OPENSSL_NPN_NO_OVERLAP         = 2
+)
+
+// -----------------------------------------------------------------------------
+
+type SSL_METHOD struct {
+	Unused [0]byte
+}
+
+// const SSL_METHOD *TLS_method(void);
+//
+//go:linkname TLSMethod C.TLS_method
+func TLSMethod() *SSL_METHOD
+
+// const SSL_METHOD *TLS_client_method(void);
+//
+//go:linkname TLSClientMethod C.TLS_client_method
+func TLSClientMethod() *SSL_METHOD
+
+// const SSL_METHOD *TLS_server_method(void);
+//
+//go:linkname TLSServerMethod C.TLS_server_method
+func TLSServerMethod() *SSL_METHOD
+
+// -----------------------------------------------------------------------------
+
+type SSL_SESSION struct {
+	Unused [0]byte
+}
+
+// void SSL_SESSION_free(SSL_SESSION *session);
+//
+// llgo:link (*SSL_SESSION).Free C.SSL_SESSION_free
+func (*SSL_SESSION) Free() {}
+
+// int SSL_SESSION_up_ref(SSL_SESSION *ses);
+//
+// llgo:link (*SSL_SESSION).UpRef C.SSL_SESSION_up_ref
+func (*SSL_SESSION) UpRef() c.Int { return 0 }
+
+// int SSL_SESSION_is_resumable(const SSL_SESSION *s);
+//
+// llgo:link (*SSL_SESSION).IsResumable C.SSL_SESSION_is_resumable
+func (*SSL_SESSION) IsResumable() c.Int { return 0 }
+
+// -----------------------------------------------------------------------------
+
+// typedef int (*SSL_verify_cb)(int preverify_ok, X509_STORE_CTX *x509_ctx);
+//
+// llgo:type C
+type SSLVerifyCb func(preverifyOk c.Int, x509Ctx *X509_STORE_CTX) c.Int
+
+// int (*cb) (SSL *ssl, const unsigned char **out, unsigned char *outlen,
+// const unsigned char *in, unsigned int inlen, void *arg);
+//
+// llgo:type C
+type SSLALPNSelectCb func(ssl *SSL, out **byte, outlen *byte, in *byte, inlen c.Uint, arg unsafe.Pointer) c.Int
+
+// int (*new_session_cb) (struct ssl_st *ssl, SSL_SESSION *sess);
+//
+// llgo:type C
+type SSLNewSessionCb func(ssl *SSL, sess *SSL_SESSION) c.Int
+
+type SSL_CTX struct {
+	Unused [0]byte
+}
+
+// SSL_CTX *SSL_CTX_new(const SSL_METHOD *method);
+//
+//go:linkname SSL_CTXNew C.SSL_CTX_new
+func SSL_CTXNew(method *SSL_METHOD) *SSL_CTX
+
+// void SSL_CTX_free(SSL_CTX *ctx);
+//
+// llgo:link (*SSL_CTX).Free C.SSL_CTX_free
+func (*SSL_CTX) Free() {}
+
+// int SSL_CTX_up_ref(SSL_CTX *ctx);
+//
+// llgo:link (*SSL_CTX).UpRef C.SSL_CTX_up_ref
+func (*SSL_CTX) UpRef() c.Int { return 0 }
+
+// long SSL_CTX_ctrl(SSL_CTX *ctx, int cmd, long larg, void *parg);
+//
+// llgo:link (*SSL_CTX).Ctrl C.SSL_CTX_ctrl
+func (*SSL_CTX) Ctrl(cmd c.Int, larg c.Long, parg unsafe.Pointer) c.Long { return 0 }
+
+// uint64_t SSL_CTX_set_options(SSL_CTX *ctx, uint64_t options);
+//
+// llgo:link (*SSL_CTX).SetOptions C.SSL_CTX_set_options
+func (*SSL_CTX) SetOptions(options uint64) uint64 { return 0 }
+
+// int SSL_CTX_set_min_proto_version(SSL_CTX *ctx, int version);
+func (ctx *SSL_CTX) SetMinProtoVersion(version c.Int) c.Int {
+	return c.Int(ctx.Ctrl(SSL_CTRL_SET_MIN_PROTO_VERSION, c.Long(version), nil))
+}
+
+// int SSL_CTX_set_max_proto_version(SSL_CTX *ctx, int version);
+func (ctx *SSL_CTX) SetMaxProtoVersion(version c.Int) c.Int {
+	return c.Int(ctx.Ctrl(SSL_CTRL_SET_MAX_PROTO_VERSION, c.Long(version), nil))
+}
+
+// long SSL_CTX_set_session_cache_mode(SSL_CTX *ctx, long mode);
+func (ctx *SSL_CTX) SetSessionCacheMode(mode c.Long) c.Long {
+	return ctx.Ctrl(SSL_CTRL_SET_SESS_CACHE_MODE, mode, nil)
+}
+
+// long SSL_CTX_set_mode(SSL_CTX *ctx, long mode);
+func (ctx *SSL_CTX) SetMode(mode c.Long) c.Long {
+	return ctx.Ctrl(SSL_CTRL_MODE, mode, nil)
+}
+
+// int SSL_CTX_add1_chain_cert(SSL_CTX *ctx, X509 *x509);
+func (ctx *SSL_CTX) Add1ChainCert(x *X509) c.Int {
+	return c.Int(ctx.Ctrl(SSL_CTRL_CHAIN_CERT, 1, unsafe.Pointer(x)))
+}
+
+// int SSL_CTX_use_certificate(SSL_CTX *ctx, X509 *x);
+//
+// llgo:link (*SSL_CTX).UseCertificate C.SSL_CTX_use_certificate
+func (*SSL_CTX) UseCertificate(x *X509) c.Int { return 0 }
+
+// int SSL_CTX_use_certificate_chain_file(SSL_CTX *ctx, const char *file);
+//
+// llgo:link (*SSL_CTX).UseCertificateChainFile C.SSL_CTX_use_certificate_chain_file
+func (*SSL_CTX) UseCertificateChainFile(file *c.Char) c.Int { return 0 }
+
+// int SSL_CTX_use_PrivateKey(SSL_CTX *ctx, EVP_PKEY *pkey);
+//
+// llgo:link (*SSL_CTX).UsePrivateKey C.SSL_CTX_use_PrivateKey
+func (*SSL_CTX) UsePrivateKey(pkey *EVP_PKEY) c.Int { return 0 }
+
+// int SSL_CTX_use_PrivateKey_file(SSL_CTX *ctx, const char *file, int type);
+//
+// llgo:link (*SSL_CTX).UsePrivateKeyFile C.SSL_CTX_use_PrivateKey_file
+func (*SSL_CTX) UsePrivateKeyFile(file *c.Char, typ c.Int) c.Int { return 0 }
+
+// int SSL_CTX_check_private_key(const SSL_CTX *ctx);
+//
+// llgo:link (*SSL_CTX).CheckPrivateKey C.SSL_CTX_check_private_key
+func (*SSL_CTX) CheckPrivateKey() c.Int { return 0 }
+
+// int SSL_CTX_load_verify_locations(SSL_CTX *ctx, const char *CAfile, const char *CApath);
+//
+// llgo:link (*SSL_CTX).LoadVerifyLocations C.SSL_CTX_load_verify_locations
+func (*SSL_CTX) LoadVerifyLocations(caFile, caPath *c.Char) c.Int { return 0 }
+
+// int SSL_CTX_set_default_verify_paths(SSL_CTX *ctx);
+//
+// llgo:link (*SSL_CTX).SetDefaultVerifyPaths C.SSL_CTX_set_default_verify_paths
+func (*SSL_CTX) SetDefaultVerifyPaths() c.Int { return 0 }
+
+// X509_STORE *SSL_CTX_get_cert_store(const SSL_CTX *ctx);
+//
+// llgo:link (*SSL_CTX).GetCertStore C.SSL_CTX_get_cert_store
+func (*SSL_CTX) GetCertStore() *X509_STORE { return nil }
+
+// void SSL_CTX_set_verify(SSL_CTX *ctx, int mode, SSL_verify_cb verify_callback);
+//
+// llgo:link (*SSL_CTX).SetVerify C.SSL_CTX_set_verify
+func (*SSL_CTX) SetVerify(mode c.Int, cb SSLVerifyCb) {}
+
+// void SSL_CTX_set_verify_depth(SSL_CTX *ctx, int depth);
+//
+// llgo:link (*SSL_CTX).SetVerifyDepth C.SSL_CTX_set_verify_depth
+func (*SSL_CTX) SetVerifyDepth(depth c.Int) {}
+
+// int SSL_CTX_set_alpn_protos(SSL_CTX *ctx, const unsigned char *protos, unsigned int protos_len);
+//
+// Note: returns 0 on success.
+//
+// llgo:link (*SSL_CTX).SetALPNProtos C.SSL_CTX_set_alpn_protos
+func (*SSL_CTX) SetALPNProtos(protos *byte, protosLen c.Uint) c.Int { return 0 }
+
+// void SSL_CTX_set_alpn_select_cb(SSL_CTX *ctx, SSL_CTX_alpn_select_cb_func cb, void *arg);
+//
+// llgo:link (*SSL_CTX).SetALPNSelectCb C.SSL_CTX_set_alpn_select_cb
+func (*SSL_CTX) SetALPNSelectCb(cb SSLALPNSelectCb, arg unsafe.Pointer) {}
+
+// void SSL_CTX_sess_set_new_cb(SSL_CTX *ctx, int (*new_session_cb)(SSL *, SSL_SESSION *));
+//
+// llgo:link (*SSL_CTX).SessSetNewCb C.SSL_CTX_sess_set_new_cb
+func (*SSL_CTX) SessSetNewCb(cb SSLNewSessionCb) {}
+
+// int SSL_CTX_set_session_id_context(SSL_CTX *ctx, const unsigned char *sid_ctx,
+// unsigned int sid_ctx_len);
+//
+// llgo:link (*SSL_CTX).SetSessionIDContext C.SSL_CTX_set_session_id_context
+func (*SSL_CTX) SetSessionIDContext(sidCtx *byte, sidCtxLen c.Uint) c.Int { return 0 }
+
+// int SSL_select_next_proto(unsigned char **out, unsigned char *outlen,
+// const unsigned char *server, unsigned int server_len,
+// const unsigned char *client, unsigned int client_len);
+//
+//go:linkname SSLSelectNextProto C.SSL_select_next_proto
+func SSLSelectNextProto(out **byte, outlen *byte, server *byte, serverLen c.Uint, client *byte, clientLen c.Uint) c.Int
+
+// -----------------------------------------------------------------------------
+
+type SSL struct {
+	Unused [0]byte
+}
+
+// SSL *SSL_new(SSL_CTX *ctx);
+//
+//go:linkname SSLNew C.SSL_new
+func SSLNew(ctx *SSL_CTX) *SSL
+
+// void SSL_free(SSL *ssl);
+//
+// llgo:link (*SSL).Free C.SSL_free
+func (*SSL) Free() {}
+
+// long SSL_ctrl(SSL *ssl, int cmd, long larg, void *parg);
+//
+// llgo:link (*SSL).Ctrl C.SSL_ctrl
+func (*SSL) Ctrl(cmd c.Int, larg c.Long, parg unsafe.Pointer) c.Long { return 0 }
+
+// void SSL_set_bio(SSL *s, BIO *rbio, BIO *wbio);
+//
+// llgo:link (*SSL).SetBio C.SSL_set_bio
+func (*SSL) SetBio(rbio, wbio *BIO) {}
+
+// void SSL_set_connect_state(SSL *ssl);
+//
+// llgo:link (*SSL).SetConnectState C.SSL_set_connect_state
+func (*SSL) SetConnectState() {}
+
+// void SSL_set_accept_state(SSL *ssl);
+//
+// llgo:link (*SSL).SetAcceptState C.SSL_set_accept_state
+func (*SSL) SetAcceptState() {}
+
+// int SSL_do_handshake(SSL *ssl);
+//
+// llgo:link (*SSL).DoHandshake C.SSL_do_handshake
+func (*SSL) DoHandshake() c.Int { return 0 }
+
+// int SSL_is_init_finished(const SSL *s);
+//
+// llgo:link (*SSL).IsInitFinished C.SSL_is_init_finished
+func (*SSL) IsInitFinished() c.Int { return 0 }
+
+// int SSL_read(SSL *ssl, void *buf, int num);
+//
+// llgo:link (*SSL).Read C.SSL_read
+func (*SSL) Read(buf unsafe.Pointer, num c.Int) c.Int { return 0 }
+
+// int SSL_write(SSL *ssl, const void *buf, int num);
+//
+// llgo:link (*SSL).Write C.SSL_write
+func (*SSL) Write(buf unsafe.Pointer, num c.Int) c.Int { return 0 }
+
+// int SSL_pending(const SSL *ssl);
+//
+// llgo:link (*SSL).Pending C.SSL_pending
+func (*SSL) Pending() c.Int { return 0 }
+
+// int SSL_shutdown(SSL *ssl);
+//
+// llgo:link (*SSL).Shutdown C.SSL_shutdown
+func (*SSL) Shutdown() c.Int { return 0 }
+
+// int SSL_get_error(const SSL *ssl, int ret);
+//
+// llgo:link (*SSL).GetError C.SSL_get_error
+func (*SSL) GetError(ret c.Int) c.Int { return 0 }
+
+// int SSL_set_ex_data(SSL *ssl, int idx, void *data);
+//
+// llgo:link (*SSL).SetExData C.SSL_set_ex_data
+func (*SSL) SetExData(idx c.Int, data unsafe.Pointer) c.Int { return 0 }
+
+// void *SSL_get_ex_data(const SSL *ssl, int idx);
+//
+// llgo:link (*SSL).GetExData C.SSL_get_ex_data
+func (*SSL) GetExData(idx c.Int) unsafe.Pointer { return nil }
+
+// long SSL_set_tlsext_host_name(SSL *s, const char *name);
+func (s *SSL) SetTLSExtHostName(name *c.Char) c.Long {
+	return s.Ctrl(SSL_CTRL_SET_TLSEXT_HOSTNAME, TLSEXT_NAMETYPE_host_name, unsafe.Pointer(name))
+}
+
+// const char *SSL_get_servername(const SSL *s, const int type);
+//
+// llgo:link (*SSL).GetServername C.SSL_get_servername
+func (*SSL) GetServername(typ c.Int) *c.Char { return nil }
+
+// int SSL_set1_host(SSL *s, const char *hostname);
+//
+// llgo:link (*SSL).Set1Host C.SSL_set1_host
+func (*SSL) Set1Host(hostname *c.Char) c.Int { return 0 }
+
+// X509_VERIFY_PARAM *SSL_get0_param(SSL *ssl);
+//
+// llgo:link (*SSL).Get0Param C.SSL_get0_param
+func (*SSL) Get0Param() *X509_VERIFY_PARAM { return nil }
+
+// void SSL_get0_alpn_selected(const SSL *ssl, const unsigned char **data, unsigned int *len);
+//
+// llgo:link (*SSL).Get0ALPNSelected C.SSL_get0_alpn_selected
+func (*SSL) Get0ALPNSelected(data **byte, len *c.Uint) {}
+
+// long SSL_get_verify_result(const SSL *ssl);
+//
+// llgo:link (*SSL).GetVerifyResult C.SSL_get_verify_result
+func (*SSL) GetVerifyResult() c.Long { return 0 }
+
+// int SSL_version(const SSL *s);
+//
+// llgo:link (*SSL).Version C.SSL_version
+func (*SSL) Version() c.Int { return 0 }
+
+// const char *SSL_get_version(const SSL *ssl);
+//
+// llgo:link (*SSL).GetVersion C.SSL_get_version
+func (*SSL) GetVersion() *c.Char { return nil }
+
+// X509 *SSL_get1_peer_certificate(const SSL *s);
+//
+// llgo:link (*SSL).Get1PeerCertificate C.SSL_get1_peer_certificate
+func (*SSL) Get1PeerCertificate() *X509 { return nil }
+
+// SSL_SESSION *SSL_get1_session(SSL *ssl);
+//
+// llgo:link (*SSL).Get1Session C.SSL_get1_session
+func (*SSL) Get1Session() *SSL_SESSION { return nil }
+
+// int SSL_set_session(SSL *ssl, SSL_SESSION *session);
+//
+// llgo:link (*SSL).SetSession C.SSL_set_session
+func (*SSL) SetSession(session *SSL_SESSION) c.Int { return 0 }
+
+// int SSL_session_reused(const SSL *s);
+//
+// llgo:link (*SSL).SessionReused C.SSL_session_reused
+func (*SSL) SessionReused() c.Int { return 0 }
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openssl
+
+import (
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+// -----------------------------------------------------------------------------
+
+// OPENSSL_STACK is the untyped stack behind every STACK_OF(TYPE). The typed
+// sk_TYPE_* helpers are macros, so elements are passed as unsafe.Pointer.
+type OPENSSL_STACK struct {
+	Unused [0]byte
+}
+
+// OPENSSL_STACK *OPENSSL_sk_new_null(void);
+//
+//go:linkname OPENSSLSkNewNull C.OPENSSL_sk_new_null
+func OPENSSLSkNewNull() *OPENSSL_STACK
+
+// void OPENSSL_sk_free(OPENSSL_STACK *st);
+//
+// llgo:link (*OPENSSL_STACK).Free C.OPENSSL_sk_free
+func (*OPENSSL_STACK) Free() {}
+
+// int OPENSSL_sk_num(const OPENSSL_STACK *st);
+//
+// llgo:link (*OPENSSL_STACK).Num C.OPENSSL_sk_num
+func (*OPENSSL_STACK) Num() c.Int { return 0 }
+
+// void *OPENSSL_sk_value(const OPENSSL_STACK *st, int i);
+//
+// llgo:link (*OPENSSL_STACK).Value C.OPENSSL_sk_value
+func (*OPENSSL_STACK) Value(i c.Int) unsafe.Pointer { return nil }
+
+// int OPENSSL_sk_push(OPENSSL_STACK *st, const void *data);
+//
+// llgo:link (*OPENSSL_STACK).Push C.OPENSSL_sk_push
+func (*OPENSSL_STACK) Push(data unsafe.Pointer) c.Int { return 0 }
+
+// void *OPENSSL_sk_pop(OPENSSL_STACK *st);
+//
+// llgo:link (*OPENSSL_STACK).Pop C.OPENSSL_sk_pop
+func (*OPENSSL_STACK) Pop() unsafe.Pointer { return nil }
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,751 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openssl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"runtime"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+// -----------------------------------------------------------------------------
+
+// Config configures a TLS client or server. A Config may be shared by many
+// connections, but must not be modified after it has been passed to Client
+// or Server.
+type Config struct {
+	// CertFile and KeyFile name PEM files holding the certificate chain
+	// (leaf first) and its private key.
+	CertFile, KeyFile string
+
+	// CertPEM and KeyPEM hold the certificate chain (leaf first) and its
+	// private key in PEM form. They are used when CertFile and KeyFile
+	// are empty.
+	CertPEM, KeyPEM []byte
+
+	// CAFile and CAPEM hold the PEM certificates trusted to verify the
+	// peer. If both are empty, clients use the system default trust store.
+	CAFile string
+	CAPEM  []byte
+
+	// ServerName is sent by clients as SNI and checked against the server
+	// certificate; an IP address is checked against its IP addresses
+	// instead. Clients need it unless InsecureSkipVerify is set.
+	ServerName string
+
+	// NextProtos lists the supported ALPN protocols, in order of preference.
+	NextProtos []string
+
+	// InsecureSkipVerify disables verification of the server certificate
+	// by clients.
+	InsecureSkipVerify bool
+
+	// ClientAuth makes servers request and require a verified client
+	// certificate.
+	ClientAuth bool
+
+	// MinVersion and MaxVersion bound the protocol version, e.g.
+	// TLS1_2_VERSION. Zero means the OpenSSL defaults.
+	MinVersion, MaxVersion uint16
+
+	// ResumeSessions makes clients cache sessions per server name and
+	// offer them on later connections.
+	ResumeSessions bool
+
+	once [2]sync.Once
+	sc   [2]*sslContext
+	err  [2]error
+}
+
+const (
+	roleClient = iota
+	roleServer
+)
+
+func (cfg *Config) context(role int) (*sslContext, error) {
+	cfg.once[role].Do(func() {
+		cfg.sc[role], cfg.err[role] = newSSLContext(cfg, role)
+	})
+	return cfg.sc[role], cfg.err[role]
+}
+
+// -----------------------------------------------------------------------------
+
+// sslContext owns an SSL_CTX built from a Config.
+type sslContext struct {
+	ctx  *SSL_CTX
+	alpn []byte
+
+	mu       sync.Mutex
+	sessions map[string]*SSL_SESSION
+}
+
+var sessionIDContext = []byte("github.com/goplus/lib/c/openssl")
+
+func newSSLContext(cfg *Config, role int) (*sslContext, error) {
+	method := TLSClientMethod()
+	if role == roleServer {
+		method = TLSServerMethod()
+	}
+	ctx := SSL_CTXNew(method)
+	if ctx == nil {
+		return nil, NewError("SSL_CTX_new")
+	}
+	sc := &sslContext{ctx: ctx}
+	runtime.SetFinalizer(sc, (*sslContext).free)
+
+	if cfg.MinVersion != 0 && ctx.SetMinProtoVersion(c.Int(cfg.MinVersion)) != 1 {
+		return nil, errors.New("openssl: invalid MinVersion")
+	}
+	if cfg.MaxVersion != 0 && ctx.SetMaxProtoVersion(c.Int(cfg.MaxVersion)) != 1 {
+		return nil, errors.New("openssl: invalid MaxVersion")
+	}
+	if err := sc.loadCertificate(cfg); err != nil {
+		return nil, err
+	}
+	if err := sc.loadVerify(cfg, role); err != nil {
+		return nil, err
+	}
+	if len(cfg.NextProtos) > 0 {
+		wire, err := alpnWire(cfg.NextProtos)
+		if err != nil {
+			return nil, err
+		}
+		sc.alpn = wire
+		if role == roleServer {
+			ctx.SetALPNSelectCb(alpnSelect, unsafe.Pointer(sc))
+		} else if ctx.SetALPNProtos(unsafe.SliceData(wire), c.Uint(len(wire))) != 0 {
+			return nil, NewError("SSL_CTX_set_alpn_protos")
+		}
+	}
+	if role == roleServer {
+		ctx.SetSessionIDContext(unsafe.SliceData(sessionIDContext), c.Uint(len(sessionIDContext)))
+	} else if cfg.ResumeSessions {
+		sc.sessions = make(map[string]*SSL_SESSION)
+		ctx.SetSessionCacheMode(SSL_SESS_CACHE_CLIENT)
+		ctx.SessSetNewCb(newSession)
+	}
+	return sc, nil
+}
+
+func (sc *sslContext) free() {
+	for _, sess := range sc.sessions {
+		sess.Free()
+	}
+	sc.ctx.Free()
+}
+
+func (sc *sslContext) loadCertificate(cfg *Config) error {
+	ctx := sc.ctx
+	switch {
+	case cfg.CertFile != "":
+		if ctx.UseCertificateChainFile(c.AllocaCStr(cfg.CertFile)) != 1 {
+			return NewError("SSL_CTX_use_certificate_chain_file")
+		}
+	case len(cfg.CertPEM) > 0:
+		certs, err := parseCertificatesPEM(cfg.CertPEM)
+		if err != nil {
+			return err
+		}
+		defer freeCertificates(certs)
+		if ctx.UseCertificate(certs[0]) != 1 {
+			return NewError("SSL_CTX_use_certificate")
+		}
+		for _, x := range certs[1:] {
+			if ctx.Add1ChainCert(x) != 1 {
+				return NewError("SSL_CTX_add1_chain_cert")
+			}
+		}
+	default:
+		return nil
+	}
+	switch {
+	case cfg.KeyFile != "":
+		if ctx.UsePrivateKeyFile(c.AllocaCStr(cfg.KeyFile), SSL_FILETYPE_PEM) != 1 {
+			return NewError("SSL_CTX_use_PrivateKey_file")
+		}
+	case len(cfg.KeyPEM) > 0:
+		key, err := ParsePrivateKeyPEM(cfg.KeyPEM)
+		if err != nil {
+			return err
+		}
+		ret := ctx.UsePrivateKey(key.pkey)
+		runtime.KeepAlive(key)
+		if ret != 1 {
+			return NewError("SSL_CTX_use_PrivateKey")
+		}
+	default:
+		return errors.New("openssl: certificate given without a private key")
+	}
+	if ctx.CheckPrivateKey() != 1 {
+		return errors.New("openssl: private key does not match the certificate")
+	}
+	return nil
+}
+
+func (sc *sslContext) loadVerify(cfg *Config, role int) error {
+	ctx := sc.ctx
+	switch {
+	case cfg.CAFile != "":
+		if ctx.LoadVerifyLocations(c.AllocaCStr(cfg.CAFile), nil) != 1 {
+			return NewError("SSL_CTX_load_verify_locations")
+		}
+	case len(cfg.CAPEM) > 0:
+		certs, err := parseCertificatesPEM(cfg.CAPEM)
+		if err != nil {
+			return err
+		}
+		defer freeCertificates(certs)
+		store := ctx.GetCertStore()
+		for _, x := range certs {
+			if store.AddCert(x) != 1 {
+				return NewError("X509_STORE_add_cert")
+			}
+		}
+	case role == roleClient:
+		if ctx.SetDefaultVerifyPaths() != 1 {
+			return NewError("SSL_CTX_set_default_verify_paths")
+		}
+	}
+	switch {
+	case role == roleClient && !cfg.InsecureSkipVerify:
+		ctx.SetVerify(SSL_VERIFY_PEER, nil)
+	case role == roleServer && cfg.ClientAuth:
+		ctx.SetVerify(SSL_VERIFY_PEER|SSL_VERIFY_FAIL_IF_NO_PEER_CERT, nil)
+	}
+	return nil
+}
+
+// session returns a new reference to the session cached for key, or nil.
+// The caller must free it.
+func (sc *sslContext) session(key string) *SSL_SESSION {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sess := sc.sessions[key]
+	if sess != nil {
+		sess.UpRef()
+	}
+	return sess
+}
+
+func (sc *sslContext) putSession(key string, sess *SSL_SESSION) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if old := sc.sessions[key]; old != nil {
+		old.Free()
+	}
+	sc.sessions[key] = sess
+}
+
+// parseCertificatesPEM parses every certificate in data.
+func parseCertificatesPEM(data []byte) ([]*X509, error) {
+	bio := BIONewMemBuf(unsafe.Pointer(unsafe.SliceData(data)), c.Int(len(data)))
+	if bio == nil {
+		return nil, NewError("BIO_new_mem_buf")
+	}
+	defer bio.Free()
+	var certs []*X509
+	for {
+		x := PEMReadBioX509(bio, nil, nil, nil)
+		if x == nil {
+			break
+		}
+		certs = append(certs, x)
+	}
+	// Reading past the last certificate leaves an error on the queue.
+	ERRClearError()
+	if len(certs) == 0 {
+		return nil, errors.New("openssl: no certificate found in PEM data")
+	}
+	return certs, nil
+}
+
+func freeCertificates(certs []*X509) {
+	for _, x := range certs {
+		x.Free()
+	}
+}
+
+// alpnWire encodes protos in the ALPN wire format.
+func alpnWire(protos []string) ([]byte, error) {
+	var wire []byte
+	for _, p := range protos {
+		if len(p) == 0 || len(p) > 255 {
+			return nil, errors.New("openssl: invalid ALPN protocol " + p)
+		}
+		wire = append(wire, byte(len(p)))
+		wire = append(wire, p...)
+	}
+	return wire, nil
+}
+
+func alpnSelect(ssl *SSL, out **byte, outlen *byte, in *byte, inlen c.Uint, arg unsafe.Pointer) c.Int {
+	sc := (*sslContext)(arg)
+	ret := SSLSelectNextProto(out, outlen, unsafe.SliceData(sc.alpn), c.Uint(len(sc.alpn)), in, inlen)
+	if ret != OPENSSL_NPN_NEGOTIATED {
+		return SSL_TLSEXT_ERR_NOACK
+	}
+	return SSL_TLSEXT_ERR_OK
+}
+
+func newSession(ssl *SSL, sess *SSL_SESSION) c.Int {
+	conn := (*Conn)(ssl.GetExData(0))
+	if conn == nil || conn.sc.sessions == nil {
+		return 0
+	}
+	conn.sc.putSession(conn.sessionKey, sess)
+	return 1
+}
+
+// -----------------------------------------------------------------------------
+
+// maxWriteChunk bounds the plaintext passed to a single SSL_write, which
+// keeps the memory BIO from buffering a whole large write.
+const maxWriteChunk = 64 << 10
+
+// Conn is a TLS connection over an arbitrary net.Conn transport. OpenSSL
+// exchanges records with the transport through a pair of memory BIOs.
+type Conn struct {
+	conn       net.Conn
+	sc         *sslContext
+	isClient   bool
+	serverName string
+	sessionKey string
+
+	handshakeMu   sync.Mutex
+	handshakeErr  error
+	handshakeDone bool
+
+	mu   sync.Mutex // guards ssl and its BIOs
+	ssl  *SSL
+	rbio *BIO // transport -> ssl
+	wbio *BIO // ssl -> transport
+
+	rmu   sync.Mutex // serializes Read
+	wmu   sync.Mutex // serializes Write
+	netmu sync.Mutex // keeps transport writes in record order
+	buf   []byte
+}
+
+// Client returns a new TLS client side connection using conn as the
+// transport. The handshake runs on the first Read or Write, or on an
+// explicit call to Handshake.
+func Client(conn net.Conn, config *Config) (*Conn, error) {
+	return newConn(conn, config, roleClient)
+}
+
+// Server returns a new TLS server side connection using conn as the
+// transport. config must provide a certificate and private key.
+func Server(conn net.Conn, config *Config) (*Conn, error) {
+	return newConn(conn, config, roleServer)
+}
+
+func newConn(conn net.Conn, config *Config, role int) (*Conn, error) {
+	if role == roleClient && config.ServerName == "" && !config.InsecureSkipVerify {
+		// Without a name, any valid certificate would be accepted.
+		return nil, errors.New("openssl: either ServerName or InsecureSkipVerify must be specified in the Config")
+	}
+	sc, err := config.context(role)
+	if err != nil {
+		return nil, err
+	}
+	ssl := SSLNew(sc.ctx)
+	if ssl == nil {
+		return nil, NewError("SSL_new")
+	}
+	rbio, wbio := BIONew(BIOSMem()), BIONew(BIOSMem())
+	if rbio == nil || wbio == nil {
+		ssl.Free()
+		return nil, NewError("BIO_new")
+	}
+	ssl.SetBio(rbio, wbio)
+
+	ret := &Conn{
+		conn:       conn,
+		sc:         sc,
+		isClient:   role == roleClient,
+		serverName: config.ServerName,
+		ssl:        ssl,
+		rbio:       rbio,
+		wbio:       wbio,
+	}
+	runtime.SetFinalizer(ret, (*Conn).free)
+	ssl.SetExData(0, unsafe.Pointer(ret))
+
+	if role == roleServer {
+		ssl.SetAcceptState()
+		return ret, nil
+	}
+	ssl.SetConnectState()
+	if name := config.ServerName; name != "" {
+		isIP := net.ParseIP(name) != nil
+		if !isIP && ssl.SetTLSExtHostName(c.AllocaCStr(name)) != 1 {
+			return nil, NewError("SSL_set_tlsext_host_name")
+		}
+		if !config.InsecureSkipVerify {
+			if isIP {
+				if ssl.Get0Param().Set1IPAsc(c.AllocaCStr(name)) != 1 {
+					return nil, NewError("X509_VERIFY_PARAM_set1_ip_asc")
+				}
+			} else if ssl.Set1Host(c.AllocaCStr(name)) != 1 {
+				return nil, NewError("SSL_set1_host")
+			}
+		}
+	}
+	if sc.sessions != nil {
+		ret.sessionKey = config.ServerName
+		if ret.sessionKey == "" {
+			ret.sessionKey = conn.RemoteAddr().String()
+		}
+		if sess := sc.session(ret.sessionKey); sess != nil {
+			ssl.SetSession(sess)
+			sess.Free()
+		}
+	}
+	return ret, nil
+}
+
+func (conn *Conn) free() {
+	if conn.ssl != nil {
+		conn.ssl.Free()
+		conn.ssl = nil
+	}
+}
+
+// errorLocked describes a failed SSL call. It must be called with conn.mu
+// held, right after the failing call.
+func (conn *Conn) errorLocked(fn string, code c.Int) error {
+	if v := conn.ssl.GetVerifyResult(); v != X509_V_OK {
+		ERRClearError()
+		return fmt.Errorf("openssl: %s: certificate verify failed: %s",
+			fn, c.GoString(X509VerifyCertErrorString(v)))
+	}
+	if ERRPeekError() != 0 {
+		return NewError(fn)
+	}
+	return fmt.Errorf("openssl: %s failed with SSL error %d", fn, code)
+}
+
+// unlockAndSend writes the records OpenSSL queued in wbio to the transport.
+// It must be called with conn.mu held and releases it before blocking.
+func (conn *Conn) unlockAndSend() error {
+	var out []byte
+	if conn.wbio != nil {
+		if n := conn.wbio.CtrlPending(); n > 0 {
+			out = make([]byte, n)
+			conn.wbio.Read(unsafe.Pointer(unsafe.SliceData(out)), c.Int(n))
+		}
+	}
+	conn.netmu.Lock()
+	conn.mu.Unlock()
+	defer conn.netmu.Unlock()
+	if len(out) == 0 {
+		return nil
+	}
+	_, err := conn.conn.Write(out)
+	return err
+}
+
+// fill reads records from the transport into rbio.
+func (conn *Conn) fill() error {
+	if conn.buf == nil {
+		conn.buf = make([]byte, 16<<10)
+	}
+	n, err := conn.conn.Read(conn.buf)
+	if n > 0 {
+		conn.mu.Lock()
+		defer conn.mu.Unlock()
+		if conn.ssl == nil {
+			return net.ErrClosed
+		}
+		conn.rbio.Write(unsafe.Pointer(unsafe.SliceData(conn.buf)), c.Int(n))
+		return nil
+	}
+	return err
+}
+
+// Handshake runs the TLS handshake if it has not yet been run. Most uses
+// need not call it explicitly: the first Read or Write does.
+func (conn *Conn) Handshake() error {
+	conn.handshakeMu.Lock()
+	defer conn.handshakeMu.Unlock()
+	if conn.handshakeDone || conn.handshakeErr != nil {
+		return conn.handshakeErr
+	}
+	conn.handshakeErr = conn.handshake()
+	conn.handshakeDone = conn.handshakeErr == nil
+	return conn.handshakeErr
+}
+
+func (conn *Conn) handshake() error {
+	for {
+		conn.mu.Lock()
+		if conn.ssl == nil {
+			conn.mu.Unlock()
+			return net.ErrClosed
+		}
+		ret := conn.ssl.DoHandshake()
+		var code c.Int
+		var err error
+		if ret != 1 {
+			code = conn.ssl.GetError(ret)
+			if code != SSL_ERROR_WANT_READ && code != SSL_ERROR_WANT_WRITE {
+				err = conn.errorLocked("SSL_do_handshake", code)
+			}
+		}
+		if serr := conn.unlockAndSend(); serr != nil {
+			return serr
+		}
+		if err != nil || ret == 1 {
+			return err
+		}
+		if code == SSL_ERROR_WANT_READ {
+			if err = conn.fill(); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return err
+			}
+		}
+	}
+}
+
+// Read reads application data from the connection.
+func (conn *Conn) Read(b []byte) (int, error) {
+	if err := conn.Handshake(); err != nil {
+		return 0, err
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	if len(b) > maxUpdateLen {
+		b = b[:maxUpdateLen]
+	}
+	conn.rmu.Lock()
+	defer conn.rmu.Unlock()
+	for {
+		conn.mu.Lock()
+		if conn.ssl == nil {
+			conn.mu.Unlock()
+			return 0, net.ErrClosed
+		}
+		n := conn.ssl.Read(unsafe.Pointer(unsafe.SliceData(b)), c.Int(len(b)))
+		var code c.Int
+		var err error
+		if n <= 0 {
+			switch code = conn.ssl.GetError(n); code {
+			case SSL_ERROR_WANT_READ, SSL_ERROR_WANT_WRITE:
+			case SSL_ERROR_ZERO_RETURN:
+				err = io.EOF
+			default:
+				err = conn.errorLocked("SSL_read", code)
+			}
+		}
+		serr := conn.unlockAndSend()
+		if n > 0 {
+			return int(n), nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if serr != nil {
+			return 0, serr
+		}
+		if code == SSL_ERROR_WANT_READ {
+			if err = conn.fill(); err != nil {
+				return 0, err
+			}
+		}
+	}
+}
+
+// Write writes application data to the connection.
+func (conn *Conn) Write(b []byte) (int, error) {
+	if err := conn.Handshake(); err != nil {
+		return 0, err
+	}
+	conn.wmu.Lock()
+	defer conn.wmu.Unlock()
+	// SSL_write may need records from the peer, e.g. during a renegotiation.
+	// Reading them takes rmu, and the write is retried once it is held as a
+	// concurrent Read may have processed them meanwhile.
+	reading := false
+	defer func() {
+		if reading {
+			conn.rmu.Unlock()
+		}
+	}()
+	total := 0
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > maxWriteChunk {
+			chunk = chunk[:maxWriteChunk]
+		}
+		conn.mu.Lock()
+		if conn.ssl == nil {
+			conn.mu.Unlock()
+			return total, net.ErrClosed
+		}
+		n := conn.ssl.Write(unsafe.Pointer(unsafe.SliceData(chunk)), c.Int(len(chunk)))
+		var code c.Int
+		var err error
+		if n <= 0 {
+			switch code = conn.ssl.GetError(n); code {
+			case SSL_ERROR_WANT_READ, SSL_ERROR_WANT_WRITE:
+			default:
+				err = conn.errorLocked("SSL_write", code)
+			}
+		}
+		if serr := conn.unlockAndSend(); serr != nil {
+			return total, serr
+		}
+		if err != nil {
+			return total, err
+		}
+		if n > 0 {
+			total += int(n)
+			b = b[n:]
+			if reading {
+				conn.rmu.Unlock()
+				reading = false
+			}
+			continue
+		}
+		if code == SSL_ERROR_WANT_READ {
+			if !reading {
+				conn.rmu.Lock()
+				reading = true
+				continue
+			}
+			if err = conn.fill(); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return total, err
+			}
+		}
+	}
+	return total, nil
+}
+
+// CloseWrite sends a close_notify alert. The connection can still be read
+// from afterwards.
+func (conn *Conn) CloseWrite() error {
+	conn.mu.Lock()
+	if conn.ssl == nil {
+		conn.mu.Unlock()
+		return net.ErrClosed
+	}
+	if conn.ssl.IsInitFinished() == 1 {
+		conn.ssl.Shutdown()
+	}
+	return conn.unlockAndSend()
+}
+
+// Close sends a close_notify alert if the handshake completed, then closes
+// the transport.
+func (conn *Conn) Close() error {
+	conn.mu.Lock()
+	if conn.ssl == nil {
+		conn.mu.Unlock()
+		return net.ErrClosed
+	}
+	if conn.ssl.IsInitFinished() == 1 {
+		conn.ssl.Shutdown()
+	}
+	conn.unlockAndSend()
+
+	conn.mu.Lock()
+	conn.free()
+	conn.rbio, conn.wbio = nil, nil
+	conn.mu.Unlock()
+	return conn.conn.Close()
+}
+
+// NetConn returns the underlying transport.
+func (conn *Conn) NetConn() net.Conn {
+	return conn.conn
+}
+
+func (conn *Conn) LocalAddr() net.Addr {
+	return conn.conn.LocalAddr()
+}
+
+func (conn *Conn) RemoteAddr() net.Addr {
+	return conn.conn.RemoteAddr()
+}
+
+func (conn *Conn) SetDeadline(t time.Time) error {
+	return conn.conn.SetDeadline(t)
+}
+
+func (conn *Conn) SetReadDeadline(t time.Time) error {
+	return conn.conn.SetReadDeadline(t)
+}
+
+func (conn *Conn) SetWriteDeadline(t time.Time) error {
+	return conn.conn.SetWriteDeadline(t)
+}
+
+// ConnectionState records basic TLS details about a connection.
+type ConnectionState struct {
+	Version            uint16 // TLS version, e.g. TLS1_3_VERSION
+	HandshakeComplete  bool   // TLS handshake is complete
+	DidResume          bool   // connection resumes a previous session
+	NegotiatedProtocol string // ALPN protocol, if any
+	ServerName         string // SNI server name
+	PeerCertificate    []byte // DER encoding of the peer's leaf certificate
+}
+
+// ConnectionState returns basic TLS details about the connection.
+func (conn *Conn) ConnectionState() ConnectionState {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	var state ConnectionState
+	ssl := conn.ssl
+	if ssl == nil {
+		return state
+	}
+	state.Version = uint16(ssl.Version())
+	state.HandshakeComplete = ssl.IsInitFinished() == 1
+	state.DidResume = ssl.SessionReused() == 1
+	var proto *byte
+	var protoLen c.Uint
+	ssl.Get0ALPNSelected(&proto, &protoLen)
+	if proto != nil {
+		state.NegotiatedProtocol = string(unsafe.Slice(proto, protoLen))
+	}
+	if conn.isClient {
+		state.ServerName = conn.serverName
+	} else if name := ssl.GetServername(TLSEXT_NAMETYPE_host_name); name != nil {
+		state.ServerName = c.GoString(name)
+	}
+	if x := ssl.Get1PeerCertificate(); x != nil {
+		state.PeerCertificate = x509DER(x)
+		x.Free()
+	}
+	return state
+}
+
+// -----------------------------------------------------------------------------
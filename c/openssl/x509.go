@@ -0,0 +1,613 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openssl
+
+import (
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+	ctime "github.com/goplus/lib/c/time"
+)
+
+// -----------------------------------------------------------------------------
+
+const (
+	X509_V_OK                                    = 0
+	X509_V_ERR_UNSPECIFIED                       = 1
+	X509_V_ERR_UNABLE_TO_GET_ISSUER_CERT         = 2
+	X509_V_ERR_CERT_SIGNATURE_FAILURE            = 7
+	X509_V_ERR_CERT_NOT_YET_VALID                = 9
+	X509_V_ERR_CERT_HAS_EXPIRED                  = 10
+	X509_V_ERR_DEPTH_ZERO_SELF_SIGNED_CERT       = 18
+	X509_V_ERR_SELF_SIGNED_CERT_IN_CHAIN         = 19
+	X509_V_ERR_UNABLE_TO_GET_ISSUER_CERT_LOCALLY = 20
+	X509_V_ERR_UNABLE_TO_VERIFY_LEAF_SIGNATURE   = 21
+	X509_V_ERR_CERT_CHAIN_TOO_LONG               = 22
+	X509_V_ERR_CERT_REVOKED                      = 23
+	X509_V_ERR_INVALID_CA                        = 24
+	X509_V_ERR_PATH_LENGTH_EXCEEDED              = 25
+	X509_V_ERR_INVALID_PURPOSE                   = 26
+	X509_V_ERR_CERT_UNTRUSTED                    = 27
+	X509_V_ERR_CERT_REJECTED                     = 28
+	X509_V_ERR_HOSTNAME_MISMATCH                 = 62
+	X509_V_ERR_EMAIL_MISMATCH                    = 63
+	X509_V_ERR_IP_ADDRESS_MISMATCH               = 64
+)
+
+const (
+	X509_VERSION_1 = 0
+	X509_VERSION_2 = 1
+	X509_VERSION_3 = 2
+
+	X509_REQ_VERSION_1 = 0
+)
+
+const (
+	X509_CHECK_FLAG_ALWAYS_CHECK_SUBJECT    = 0x1
+	X509_CHECK_FLAG_NO_WILDCARDS            = 0x2
+	X509_CHECK_FLAG_NO_PARTIAL_WILDCARDS    = 0x4
+	X509_CHECK_FLAG_MULTI_LABEL_WILDCARDS   = 0x8
+	X509_CHECK_FLAG_SINGLE_LABEL_SUBDOMAINS = 0x10
+	X509_CHECK_FLAG_NEVER_CHECK_SUBJECT     = 0x20
+)
+
+const (
+	NID_subject_key_identifier   = 82
+	NID_key_usage                = 83
+	NID_subject_alt_name         = 85
+	NID_basic_constraints        = 87
+	NID_authority_key_identifier = 90
+	NID_ext_key_usage            = 126
+)
+
+// Flags of X509_NAME_print_ex.
+const (
+	ASN1_STRFLGS_RFC2253 = 0x317
+
+	XN_FLAG_SEP_COMMA_PLUS      = 1 << 16
+	XN_FLAG_DN_REV              = 1 << 20
+	XN_FLAG_FN_SN               = 0
+	XN_FLAG_DUMP_UNKNOWN_FIELDS = 1 << 24
+	XN_FLAG_RFC2253             = ASN1_STRFLGS_RFC2253 | XN_FLAG_SEP_COMMA_PLUS | XN_FLAG_DN_REV |
+		XN_FLAG_FN_SN | XN_FLAG_DUMP_UNKNOWN_FIELDS
+)
+
+// const char *X509_verify_cert_error_string(long n);
+//
+//go:linkname X509VerifyCertErrorString C.X509_verify_cert_error_string
+func X509VerifyCertErrorString(n c.Long) *c.Char
+
+// -----------------------------------------------------------------------------
+
+type X509 struct {
+	Unused [0]byte
+}
+
+// X509 *X509_new(void);
+//
+//go:linkname X509New C.X509_new
+func X509New() *X509
+
+// void X509_free(X509 *a);
+//
+// llgo:link (*X509).Free C.X509_free
+func (*X509) Free() {}
+
+// int X509_up_ref(X509 *a);
+//
+// llgo:link (*X509).UpRef C.X509_up_ref
+func (*X509) UpRef() c.Int { return 0 }
+
+// X509 *X509_dup(const X509 *a);
+//
+// llgo:link (*X509).Dup C.X509_dup
+func (*X509) Dup() *X509 { return nil }
+
+// long X509_get_version(const X509 *x);
+//
+// llgo:link (*X509).GetVersion C.X509_get_version
+func (*X509) GetVersion() c.Long { return 0 }
+
+// int X509_set_version(X509 *x, long version);
+//
+// llgo:link (*X509).SetVersion C.X509_set_version
+func (*X509) SetVersion(version c.Long) c.Int { return 0 }
+
+// const ASN1_INTEGER *X509_get0_serialNumber(const X509 *x);
+//
+// llgo:link (*X509).Get0SerialNumber C.X509_get0_serialNumber
+func (*X509) Get0SerialNumber() *ASN1_INTEGER { return nil }
+
+// int X509_set_serialNumber(X509 *x, ASN1_INTEGER *serial);
+//
+// llgo:link (*X509).SetSerialNumber C.X509_set_serialNumber
+func (*X509) SetSerialNumber(serial *ASN1_INTEGER) c.Int { return 0 }
+
+// X509_NAME *X509_get_subject_name(const X509 *a);
+//
+// llgo:link (*X509).GetSubjectName C.X509_get_subject_name
+func (*X509) GetSubjectName() *X509_NAME { return nil }
+
+// int X509_set_subject_name(X509 *x, const X509_NAME *name);
+//
+// llgo:link (*X509).SetSubjectName C.X509_set_subject_name
+func (*X509) SetSubjectName(name *X509_NAME) c.Int { return 0 }
+
+// X509_NAME *X509_get_issuer_name(const X509 *a);
+//
+// llgo:link (*X509).GetIssuerName C.X509_get_issuer_name
+func (*X509) GetIssuerName() *X509_NAME { return nil }
+
+// int X509_set_issuer_name(X509 *x, const X509_NAME *name);
+//
+// llgo:link (*X509).SetIssuerName C.X509_set_issuer_name
+func (*X509) SetIssuerName(name *X509_NAME) c.Int { return 0 }
+
+// const ASN1_TIME *X509_get0_notBefore(const X509 *x);
+//
+// llgo:link (*X509).Get0NotBefore C.X509_get0_notBefore
+func (*X509) Get0NotBefore() *ASN1_TIME { return nil }
+
+// const ASN1_TIME *X509_get0_notAfter(const X509 *x);
+//
+// llgo:link (*X509).Get0NotAfter C.X509_get0_notAfter
+func (*X509) Get0NotAfter() *ASN1_TIME { return nil }
+
+// ASN1_TIME *X509_getm_notBefore(const X509 *x);
+//
+// llgo:link (*X509).GetmNotBefore C.X509_getm_notBefore
+func (*X509) GetmNotBefore() *ASN1_TIME { return nil }
+
+// ASN1_TIME *X509_getm_notAfter(const X509 *x);
+//
+// llgo:link (*X509).GetmNotAfter C.X509_getm_notAfter
+func (*X509) GetmNotAfter() *ASN1_TIME { return nil }
+
+// EVP_PKEY *X509_get_pubkey(X509 *x);
+//
+// llgo:link (*X509).GetPubkey C.X509_get_pubkey
+func (*X509) GetPubkey() *EVP_PKEY { return nil }
+
+// EVP_PKEY *X509_get0_pubkey(const X509 *x);
+//
+// llgo:link (*X509).Get0Pubkey C.X509_get0_pubkey
+func (*X509) Get0Pubkey() *EVP_PKEY { return nil }
+
+// int X509_set_pubkey(X509 *x, EVP_PKEY *pkey);
+//
+// llgo:link (*X509).SetPubkey C.X509_set_pubkey
+func (*X509) SetPubkey(pkey *EVP_PKEY) c.Int { return 0 }
+
+// void *X509_get_ext_d2i(const X509 *x, int nid, int *crit, int *idx);
+//
+// llgo:link (*X509).GetExtD2i C.X509_get_ext_d2i
+func (*X509) GetExtD2i(nid c.Int, crit, idx *c.Int) unsafe.Pointer { return nil }
+
+// int X509_add_ext(X509 *x, X509_EXTENSION *ex, int loc);
+//
+// llgo:link (*X509).AddExt C.X509_add_ext
+func (*X509) AddExt(ex *X509_EXTENSION, loc c.Int) c.Int { return 0 }
+
+// int X509_sign(X509 *x, EVP_PKEY *pkey, const EVP_MD *md);
+//
+// llgo:link (*X509).Sign C.X509_sign
+func (*X509) Sign(pkey *EVP_PKEY, md *EVP_MD) c.Int { return 0 }
+
+// int X509_verify(X509 *a, EVP_PKEY *r);
+//
+// llgo:link (*X509).Verify C.X509_verify
+func (*X509) Verify(r *EVP_PKEY) c.Int { return 0 }
+
+// int X509_check_host(X509 *x, const char *chk, size_t chklen,
+// unsigned int flags, char **peername);
+//
+// llgo:link (*X509).CheckHost C.X509_check_host
+func (*X509) CheckHost(chk *c.Char, chklen uintptr, flags c.Uint, peername **c.Char) c.Int {
+	return 0
+}
+
+// int X509_check_email(X509 *x, const char *chk, size_t chklen, unsigned int flags);
+//
+// llgo:link (*X509).CheckEmail C.X509_check_email
+func (*X509) CheckEmail(chk *c.Char, chklen uintptr, flags c.Uint) c.Int { return 0 }
+
+// int X509_check_ip_asc(X509 *x, const char *ipasc, unsigned int flags);
+//
+// llgo:link (*X509).CheckIPAsc C.X509_check_ip_asc
+func (*X509) CheckIPAsc(ipasc *c.Char, flags c.Uint) c.Int { return 0 }
+
+// int X509_check_issued(X509 *issuer, X509 *subject);
+//
+// llgo:link (*X509).CheckIssued C.X509_check_issued
+func (*X509) CheckIssued(subject *X509) c.Int { return 0 }
+
+// X509 *d2i_X509(X509 **a, const unsigned char **in, long len);
+//
+//go:linkname D2iX509 C.d2i_X509
+func D2iX509(a **X509, in **byte, len c.Long) *X509
+
+// int i2d_X509(const X509 *a, unsigned char **out);
+//
+//go:linkname I2dX509 C.i2d_X509
+func I2dX509(a *X509, out **byte) c.Int
+
+// X509 *PEM_read_bio_X509(BIO *bp, X509 **x, pem_password_cb *cb, void *u);
+//
+//go:linkname PEMReadBioX509 C.PEM_read_bio_X509
+func PEMReadBioX509(bp *BIO, x **X509, cb PemPasswordCb, u unsafe.Pointer) *X509
+
+// int PEM_write_bio_X509(BIO *bp, const X509 *x);
+//
+//go:linkname PEMWriteBioX509 C.PEM_write_bio_X509
+func PEMWriteBioX509(bp *BIO, x *X509) c.Int
+
+// -----------------------------------------------------------------------------
+
+type X509_NAME struct {
+	Unused [0]byte
+}
+
+// X509_NAME *X509_NAME_new(void);
+//
+//go:linkname X509_NAMENew C.X509_NAME_new
+func X509_NAMENew() *X509_NAME
+
+// void X509_NAME_free(X509_NAME *a);
+//
+// llgo:link (*X509_NAME).Free C.X509_NAME_free
+func (*X509_NAME) Free() {}
+
+// int X509_NAME_add_entry_by_txt(X509_NAME *name, const char *field, int type,
+// const unsigned char *bytes, int len, int loc, int set);
+//
+// llgo:link (*X509_NAME).AddEntryByTxt C.X509_NAME_add_entry_by_txt
+func (*X509_NAME) AddEntryByTxt(field *c.Char, typ c.Int, bytes *byte, len, loc, set c.Int) c.Int {
+	return 0
+}
+
+// int X509_NAME_entry_count(const X509_NAME *name);
+//
+// llgo:link (*X509_NAME).EntryCount C.X509_NAME_entry_count
+func (*X509_NAME) EntryCount() c.Int { return 0 }
+
+// X509_NAME_ENTRY *X509_NAME_get_entry(const X509_NAME *name, int loc);
+//
+// llgo:link (*X509_NAME).GetEntry C.X509_NAME_get_entry
+func (*X509_NAME) GetEntry(loc c.Int) *X509_NAME_ENTRY { return nil }
+
+// int X509_NAME_cmp(const X509_NAME *a, const X509_NAME *b);
+//
+// llgo:link (*X509_NAME).Cmp C.X509_NAME_cmp
+func (*X509_NAME) Cmp(b *X509_NAME) c.Int { return 0 }
+
+// int X509_NAME_print_ex(BIO *out, const X509_NAME *nm, int indent, unsigned long flags);
+//
+//go:linkname X509_NAMEPrintEx C.X509_NAME_print_ex
+func X509_NAMEPrintEx(out *BIO, nm *X509_NAME, indent c.Int, flags c.Ulong) c.Int
+
+type X509_NAME_ENTRY struct {
+	Unused [0]byte
+}
+
+// ASN1_OBJECT *X509_NAME_ENTRY_get_object(const X509_NAME_ENTRY *ne);
+//
+// llgo:link (*X509_NAME_ENTRY).GetObject C.X509_NAME_ENTRY_get_object
+func (*X509_NAME_ENTRY) GetObject() *ASN1_OBJECT { return nil }
+
+// ASN1_STRING *X509_NAME_ENTRY_get_data(const X509_NAME_ENTRY *ne);
+//
+// llgo:link (*X509_NAME_ENTRY).GetData C.X509_NAME_ENTRY_get_data
+func (*X509_NAME_ENTRY) GetData() *ASN1_STRING { return nil }
+
+// int X509_NAME_ENTRY_set(const X509_NAME_ENTRY *ne);
+//
+// Set returns the index of the RDN the entry belongs to.
+//
+// llgo:link (*X509_NAME_ENTRY).Set C.X509_NAME_ENTRY_set
+func (*X509_NAME_ENTRY) Set() c.Int { return 0 }
+
+// -----------------------------------------------------------------------------
+
+const (
+	GEN_OTHERNAME = 0
+	GEN_EMAIL     = 1
+	GEN_DNS       = 2
+	GEN_X400      = 3
+	GEN_DIRNAME   = 4
+	GEN_EDIPARTY  = 5
+	GEN_URI       = 6
+	GEN_IPADD     = 7
+	GEN_RID       = 8
+)
+
+// GENERAL_NAME mirrors struct GENERAL_NAME_st. For GEN_EMAIL, GEN_DNS,
+// GEN_URI and GEN_IPADD, D points to an ASN1_STRING.
+type GENERAL_NAME struct {
+	Type c.Int
+	D    unsafe.Pointer
+}
+
+// void GENERAL_NAMES_free(GENERAL_NAMES *a);
+//
+//go:linkname GENERAL_NAMESFree C.GENERAL_NAMES_free
+func GENERAL_NAMESFree(a *OPENSSL_STACK)
+
+type X509_EXTENSION struct {
+	Unused [0]byte
+}
+
+// void X509_EXTENSION_free(X509_EXTENSION *a);
+//
+// llgo:link (*X509_EXTENSION).Free C.X509_EXTENSION_free
+func (*X509_EXTENSION) Free() {}
+
+// ASN1_OBJECT *X509_EXTENSION_get_object(X509_EXTENSION *ex);
+//
+// llgo:link (*X509_EXTENSION).GetObject C.X509_EXTENSION_get_object
+func (*X509_EXTENSION) GetObject() *ASN1_OBJECT { return nil }
+
+// void *X509V3_EXT_d2i(X509_EXTENSION *ext);
+//
+// llgo:link (*X509_EXTENSION).D2i C.X509V3_EXT_d2i
+func (*X509_EXTENSION) D2i() unsafe.Pointer { return nil }
+
+type CONF struct {
+	Unused [0]byte
+}
+
+type X509V3_CTX struct {
+	Unused [0]byte
+}
+
+// X509_EXTENSION *X509V3_EXT_nconf_nid(CONF *conf, X509V3_CTX *ctx,
+// int ext_nid, const char *value);
+//
+// conf and ctx may be nil for extensions whose value does not refer to a
+// configuration section or to the issuer/subject (e.g. "keyid" or "hash").
+//
+//go:linkname X509V3EXTNconfNID C.X509V3_EXT_nconf_nid
+func X509V3EXTNconfNID(conf *CONF, ctx *X509V3_CTX, extNid c.Int, value *c.Char) *X509_EXTENSION
+
+// -----------------------------------------------------------------------------
+
+type X509_REQ struct {
+	Unused [0]byte
+}
+
+// X509_REQ *X509_REQ_new(void);
+//
+//go:linkname X509_REQNew C.X509_REQ_new
+func X509_REQNew() *X509_REQ
+
+// void X509_REQ_free(X509_REQ *a);
+//
+// llgo:link (*X509_REQ).Free C.X509_REQ_free
+func (*X509_REQ) Free() {}
+
+// int X509_REQ_set_version(X509_REQ *x, long version);
+//
+// llgo:link (*X509_REQ).SetVersion C.X509_REQ_set_version
+func (*X509_REQ) SetVersion(version c.Long) c.Int { return 0 }
+
+// X509_NAME *X509_REQ_get_subject_name(const X509_REQ *req);
+//
+// llgo:link (*X509_REQ).GetSubjectName C.X509_REQ_get_subject_name
+func (*X509_REQ) GetSubjectName() *X509_NAME { return nil }
+
+// int X509_REQ_set_subject_name(X509_REQ *req, const X509_NAME *name);
+//
+// llgo:link (*X509_REQ).SetSubjectName C.X509_REQ_set_subject_name
+func (*X509_REQ) SetSubjectName(name *X509_NAME) c.Int { return 0 }
+
+// EVP_PKEY *X509_REQ_get_pubkey(X509_REQ *req);
+//
+// llgo:link (*X509_REQ).GetPubkey C.X509_REQ_get_pubkey
+func (*X509_REQ) GetPubkey() *EVP_PKEY { return nil }
+
+// int X509_REQ_set_pubkey(X509_REQ *x, EVP_PKEY *pkey);
+//
+// llgo:link (*X509_REQ).SetPubkey C.X509_REQ_set_pubkey
+func (*X509_REQ) SetPubkey(pkey *EVP_PKEY) c.Int { return 0 }
+
+// int X509_REQ_add_extensions(X509_REQ *req, const STACK_OF(X509_EXTENSION) *exts);
+//
+// llgo:link (*X509_REQ).AddExtensions C.X509_REQ_add_extensions
+func (*X509_REQ) AddExtensions(exts *OPENSSL_STACK) c.Int { return 0 }
+
+// STACK_OF(X509_EXTENSION) *X509_REQ_get_extensions(X509_REQ *req);
+//
+// llgo:link (*X509_REQ).GetExtensions C.X509_REQ_get_extensions
+func (*X509_REQ) GetExtensions() *OPENSSL_STACK { return nil }
+
+// int X509_REQ_sign(X509_REQ *x, EVP_PKEY *pkey, const EVP_MD *md);
+//
+// llgo:link (*X509_REQ).Sign C.X509_REQ_sign
+func (*X509_REQ) Sign(pkey *EVP_PKEY, md *EVP_MD) c.Int { return 0 }
+
+// int X509_REQ_verify(X509_REQ *a, EVP_PKEY *r);
+//
+// llgo:link (*X509_REQ).Verify C.X509_REQ_verify
+func (*X509_REQ) Verify(r *EVP_PKEY) c.Int { return 0 }
+
+// X509_REQ *d2i_X509_REQ(X509_REQ **a, const unsigned char **in, long len);
+//
+//go:linkname D2iX509_REQ C.d2i_X509_REQ
+func D2iX509_REQ(a **X509_REQ, in **byte, len c.Long) *X509_REQ
+
+// int i2d_X509_REQ(const X509_REQ *a, unsigned char **out);
+//
+//go:linkname I2dX509_REQ C.i2d_X509_REQ
+func I2dX509_REQ(a *X509_REQ, out **byte) c.Int
+
+// X509_REQ *PEM_read_bio_X509_REQ(BIO *bp, X509_REQ **x, pem_password_cb *cb, void *u);
+//
+//go:linkname PEMReadBioX509_REQ C.PEM_read_bio_X509_REQ
+func PEMReadBioX509_REQ(bp *BIO, x **X509_REQ, cb PemPasswordCb, u unsafe.Pointer) *X509_REQ
+
+// int PEM_write_bio_X509_REQ(BIO *bp, const X509_REQ *x);
+//
+//go:linkname PEMWriteBioX509_REQ C.PEM_write_bio_X509_REQ
+func PEMWriteBioX509_REQ(bp *BIO, x *X509_REQ) c.Int
+
+// -----------------------------------------------------------------------------
+
+type X509_STORE struct {
+	Unused [0]byte
+}
+
+// X509_STORE *X509_STORE_new(void);
+//
+//go:linkname X509_STORENew C.X509_STORE_new
+func X509_STORENew() *X509_STORE
+
+// void X509_STORE_free(X509_STORE *xs);
+//
+// llgo:link (*X509_STORE).Free C.X509_STORE_free
+func (*X509_STORE) Free() {}
+
+// int X509_STORE_up_ref(X509_STORE *xs);
+//
+// llgo:link (*X509_STORE).UpRef C.X509_STORE_up_ref
+func (*X509_STORE) UpRef() c.Int { return 0 }
+
+// int X509_STORE_add_cert(X509_STORE *xs, X509 *x);
+//
+// llgo:link (*X509_STORE).AddCert C.X509_STORE_add_cert
+func (*X509_STORE) AddCert(x *X509) c.Int { return 0 }
+
+// int X509_STORE_set_default_paths(X509_STORE *xs);
+//
+// llgo:link (*X509_STORE).SetDefaultPaths C.X509_STORE_set_default_paths
+func (*X509_STORE) SetDefaultPaths() c.Int { return 0 }
+
+// int X509_STORE_load_file(X509_STORE *xs, const char *file);
+//
+// llgo:link (*X509_STORE).LoadFile C.X509_STORE_load_file
+func (*X509_STORE) LoadFile(file *c.Char) c.Int { return 0 }
+
+// int X509_STORE_load_path(X509_STORE *xs, const char *dir);
+//
+// llgo:link (*X509_STORE).LoadPath C.X509_STORE_load_path
+func (*X509_STORE) LoadPath(dir *c.Char) c.Int { return 0 }
+
+// -----------------------------------------------------------------------------
+
+type X509_STORE_CTX struct {
+	Unused [0]byte
+}
+
+// X509_STORE_CTX *X509_STORE_CTX_new(void);
+//
+//go:linkname X509_STORE_CTXNew C.X509_STORE_CTX_new
+func X509_STORE_CTXNew() *X509_STORE_CTX
+
+// void X509_STORE_CTX_free(X509_STORE_CTX *ctx);
+//
+// llgo:link (*X509_STORE_CTX).Free C.X509_STORE_CTX_free
+func (*X509_STORE_CTX) Free() {}
+
+// int X509_STORE_CTX_init(X509_STORE_CTX *ctx, X509_STORE *trust_store,
+// X509 *target, STACK_OF(X509) *untrusted);
+//
+// llgo:link (*X509_STORE_CTX).Init C.X509_STORE_CTX_init
+func (*X509_STORE_CTX) Init(store *X509_STORE, target *X509, untrusted *OPENSSL_STACK) c.Int {
+	return 0
+}
+
+// void X509_STORE_CTX_cleanup(X509_STORE_CTX *ctx);
+//
+// llgo:link (*X509_STORE_CTX).Cleanup C.X509_STORE_CTX_cleanup
+func (*X509_STORE_CTX) Cleanup() {}
+
+// int X509_STORE_CTX_get_error(const X509_STORE_CTX *ctx);
+//
+// llgo:link (*X509_STORE_CTX).GetError C.X509_STORE_CTX_get_error
+func (*X509_STORE_CTX) GetError() c.Int { return 0 }
+
+// int X509_STORE_CTX_get_error_depth(const X509_STORE_CTX *ctx);
+//
+// llgo:link (*X509_STORE_CTX).GetErrorDepth C.X509_STORE_CTX_get_error_depth
+func (*X509_STORE_CTX) GetErrorDepth() c.Int { return 0 }
+
+// X509 *X509_STORE_CTX_get_current_cert(const X509_STORE_CTX *ctx);
+//
+// llgo:link (*X509_STORE_CTX).GetCurrentCert C.X509_STORE_CTX_get_current_cert
+func (*X509_STORE_CTX) GetCurrentCert() *X509 { return nil }
+
+// STACK_OF(X509) *X509_STORE_CTX_get1_chain(const X509_STORE_CTX *ctx);
+//
+// llgo:link (*X509_STORE_CTX).Get1Chain C.X509_STORE_CTX_get1_chain
+func (*X509_STORE_CTX) Get1Chain() *OPENSSL_STACK { return nil }
+
+// X509_VERIFY_PARAM *X509_STORE_CTX_get0_param(const X509_STORE_CTX *ctx);
+//
+// llgo:link (*X509_STORE_CTX).Get0Param C.X509_STORE_CTX_get0_param
+func (*X509_STORE_CTX) Get0Param() *X509_VERIFY_PARAM { return nil }
+
+// int X509_verify_cert(X509_STORE_CTX *ctx);
+//
+// llgo:link (*X509_STORE_CTX).VerifyCert C.X509_verify_cert
+func (*X509_STORE_CTX) VerifyCert() c.Int { return 0 }
+
+// -----------------------------------------------------------------------------
+
+type X509_VERIFY_PARAM struct {
+	Unused [0]byte
+}
+
+// int X509_VERIFY_PARAM_set1_host(X509_VERIFY_PARAM *param, const char *name, size_t namelen);
+//
+// llgo:link (*X509_VERIFY_PARAM).Set1Host C.X509_VERIFY_PARAM_set1_host
+func (*X509_VERIFY_PARAM) Set1Host(name *c.Char, namelen uintptr) c.Int { return 0 }
+
+// int X509_VERIFY_PARAM_set1_email(X509_VERIFY_PARAM *param, const char *email, size_t emaillen);
+//
+// llgo:link (*X509_VERIFY_PARAM).Set1Email C.X509_VERIFY_PARAM_set1_email
+func (*X509_VERIFY_PARAM) Set1Email(email *c.Char, emaillen uintptr) c.Int { return 0 }
+
+// int X509_VERIFY_PARAM_set1_ip_asc(X509_VERIFY_PARAM *param, const char *ipasc);
+//
+// llgo:link (*X509_VERIFY_PARAM).Set1IPAsc C.X509_VERIFY_PARAM_set1_ip_asc
+func (*X509_VERIFY_PARAM) Set1IPAsc(ipasc *c.Char) c.Int { return 0 }
+
+// void X509_VERIFY_PARAM_set_time(X509_VERIFY_PARAM *param, time_t t);
+//
+// llgo:link (*X509_VERIFY_PARAM).SetTime C.X509_VERIFY_PARAM_set_time
+func (*X509_VERIFY_PARAM) SetTime(t ctime.TimeT) {}
+
+// void X509_VERIFY_PARAM_set_hostflags(X509_VERIFY_PARAM *param, unsigned int flags);
+//
+// llgo:link (*X509_VERIFY_PARAM).SetHostflags C.X509_VERIFY_PARAM_set_hostflags
+func (*X509_VERIFY_PARAM) SetHostflags(flags c.Uint) {}
+
+// -----------------------------------------------------------------------------
+
+// x509DER returns the DER encoding of x.
+func x509DER(x *X509) []byte {
+	n := I2dX509(x, nil)
+	if n <= 0 {
+		return nil
+	}
+	der := make([]byte, n)
+	p := unsafe.SliceData(der)
+	if I2dX509(x, &p) != n {
+		return nil
+	}
+	return der
+}
+
+// -----------------------------------------------------------------------------
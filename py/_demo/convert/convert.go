@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/py"
+)
+
+type Point struct {
+	X, Y  float64
+	Label string `py:"label"`
+}
+
+func main() {
+	py.Initialize()
+	py.SetProgramName(*c.Argv)
+
+	json := py.ImportModule(c.Str("json"))
+	dumps := json.GetAttrString(c.Str("dumps"))
+
+	data := py.From(map[string]any{
+		"points": []Point{{1, 2, "a"}, {3, 4, "b"}},
+		"tags":   []string{"x", "y"},
+		"ok":     true,
+		"none":   nil,
+	})
+	text := dumps.CallOneArg(data)
+	var s string
+	py.To(text, &s)
+	fmt.Println(s)
+
+	var back struct {
+		Points []Point
+		Tags   []string
+	}
+	var generic map[string]any
+	fmt.Println(py.To(data, &back), back.Points, back.Tags)
+	fmt.Println(py.To(data, &generic), generic["ok"], generic["tags"])
+
+	when := time.Date(2024, 5, 6, 7, 8, 9, 123456000, time.FixedZone("CEST", 2*3600))
+	dt := py.From(when)
+	var t time.Time
+	fmt.Println(py.To(dt, &t), t.Equal(when), t.Format(time.RFC3339Nano))
+
+	var n int8
+	big := py.From(1000)
+	fmt.Println(py.To(big, &n))
+	fmt.Println(py.To(text, &n))
+
+	big.DecRef()
+	dt.DecRef()
+	text.DecRef()
+	data.DecRef()
+	dumps.DecRef()
+	json.DecRef()
+	py.Finalize()
+}
+
+/* Expected output:
+{"none": null, "ok": true, "points": [{"X": 1.0, "Y": 2.0, "label": "a"}, {"X": 3.0, "Y": 4.0, "label": "b"}], "tags": ["x", "y"]}
+<nil> [{1 2 a} {3 4 b}] [x y]
+<nil> true [x y]
+<nil> true 2024-05-06T07:08:09.123456+02:00
+py: 1000 overflows int8
+TypeError: 'str' object cannot be interpreted as an integer
+*/
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/py"
+	"github.com/goplus/lib/py/json"
+)
+
+func main() {
+	py.Initialize()
+	py.SetProgramName(*c.Argv)
+
+	text, err := json.DumpsKw(map[string]any{"b": 1, "a": []int{2, 3}}, py.Kwargs{
+		"indent":    2,
+		"sort_keys": true,
+	})
+	var s string
+	if err == nil {
+		err = py.To(text, &s)
+		text.DecRef()
+	}
+	fmt.Println(s, err)
+
+	total, err := py.CallModule("builtins", "sum", []float64{0.5, 0.25}, py.Kwargs{"start": 10})
+	var f float64
+	if err == nil {
+		err = py.To(total, &f)
+		total.DecRef()
+	}
+	fmt.Println(f, err)
+
+	_, err = py.CallModule("builtins", "sum", []int{1}, py.Kwargs{"begin": 0})
+	fmt.Println(err)
+
+	py.Finalize()
+}
+
+/* Expected output:
+{
+  "a": [
+    2,
+    3
+  ],
+  "b": 1
+} <nil>
+10.75 <nil>
+TypeError: sum() got an unexpected keyword argument 'begin'
+*/
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/goplus/lib/c"
+	"github.com/goplus/lib/py"
+)
+
+const calc = `def div(a, b):
+    return a / b
+`
+
+func main() {
+	py.Initialize()
+	py.SetProgramName(*c.Argv)
+
+	_, err := py.Check(py.ImportModule(c.Str("nosuchmod")))
+	fmt.Println(err)
+
+	code := py.CompileString(c.Str(calc), c.Str("calc.py"), py.FileInput)
+	mod := py.ImportModule(c.Str("__main__"))
+	gbl := mod.ModuleGetDict()
+	py.EvalCode(code, gbl, nil).DecRef()
+	div := gbl.DictGetItemString(c.Str("div"))
+
+	a, b := py.From(1), py.From(0)
+	_, err = py.Check(div.CallFunctionObjArgs(a, b, nil))
+	var pyErr *py.Error
+	if errors.As(err, &pyErr) {
+		fmt.Println(pyErr.Type)
+		fmt.Println(pyErr.Message)
+		fmt.Print(pyErr.Traceback)
+	}
+
+	py.ErrSetString(py.ExcKeyError, c.Str("missing"))
+	fmt.Println(py.ErrExceptionMatches(py.ExcLookupError), py.LastError(), py.LastError() == nil)
+
+	b.DecRef()
+	a.DecRef()
+	mod.DecRef()
+	code.DecRef()
+	py.Finalize()
+}
+
+/* Expected output:
+ModuleNotFoundError: No module named 'nosuchmod'
+ZeroDivisionError
+division by zero
+Traceback (most recent call last):
+  File "calc.py", line 2, in div
+ZeroDivisionError: division by zero
+1 KeyError: 'missing' true
+*/
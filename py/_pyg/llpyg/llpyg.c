@@ -1,49 +1,176 @@
 #include <stdlib.h>
 #include <stdio.h>
+#include <string.h>
+#include <ctype.h>
+
+// llpyg emits Go bindings that call the functions of a Python module with
+// keyword arguments. The bindings generated for a module (gen.go) only take
+// the required positional parameters; for each function that has optional
+// or keyword-only parameters, llpyg emits a variant named with a Kw suffix
+// that takes positional arguments followed by an optional py.Kwargs:
+//
+//	llpyg numpy > numpy/gen_kw.go
+//	llpyg matplotlib.pyplot pyplot > matplotlib/pyplot/gen_kw.go
+//
+// The package name defaults to the last element of the module name. Builtins
+// without a signature, such as numpy.zeros, are left out; their Kw variants
+// are written by hand.
 
 typedef struct PyObject PyObject;
 
 void Py_Initialize();
+void PyErr_Clear();
+void PyErr_Print();
 
 PyObject* PyImport_ImportModule(const char* modName);
-PyObject* PyModule_GetDict(PyObject* mod);
 PyObject* PyObject_Str(PyObject* obj);
-PyObject* PyDict_Keys(PyObject* dict);
-PyObject* PyList_GetItem(PyObject* list, size_t index);
-PyObject* PyTuple_GetItem(PyObject* tuple, size_t index);
-PyObject* PyObject_GetAttr(PyObject* mod, PyObject* attrName);
+PyObject* PyObject_Dir(PyObject* obj);
 PyObject* PyObject_GetAttrString(PyObject* mod, const char* attrName);
 PyObject* PyObject_CallOneArg(PyObject* fn, PyObject* arg);
+PyObject* PyObject_CallMethod(PyObject* obj, const char* name, const char* format, ...);
+PyObject* PyList_GetItem(PyObject* list, size_t index);
+PyObject* PySequence_List(PyObject* seq);
 
 const char* PyUnicode_AsUTF8(PyObject* str);
 
 size_t PyList_Size(PyObject* list);
 
 int PyCallable_Check(PyObject*);
+int PyObject_IsTrue(PyObject*);
+
+void Py_DecRef(PyObject*);
+
+static PyObject* paramEmpty;
+static PyObject* isclass;
+
+// isClass reports whether val is a class. Classes are left out, as they are
+// from the bindings of gen.go.
+static int isClass(PyObject* val) {
+    PyObject* ret = PyObject_CallOneArg(isclass, val);
+    int yes = ret != NULL && PyObject_IsTrue(ret) == 1;
+    PyErr_Clear();
+    Py_DecRef(ret);
+    return yes;
+}
+
+// goName converts a Python name in snake_case to an exported Go name.
+static void goName(const char* name, char* out) {
+    int upper = 1;
+    for (; *name; name++) {
+        if (*name == '_') {
+            upper = 1;
+            continue;
+        }
+        *out++ = upper ? toupper((unsigned char)*name) : *name;
+        upper = 0;
+    }
+    *out = '\0';
+}
+
+// hasKeywords reports whether the signature sig has parameters with a
+// default value, keyword-only parameters or **kwargs.
+static int hasKeywords(PyObject* sig) {
+    PyObject* params = PyObject_GetAttrString(sig, "parameters");
+    PyObject* values = PyObject_CallMethod(params, "values", NULL);
+    PyObject* list = PySequence_List(values);
+    size_t i, n = PyList_Size(list);
+    int found = 0;
+    for (i = 0; i < n && !found; i++) {
+        PyObject* param = PyList_GetItem(list, i);
+        PyObject* def = PyObject_GetAttrString(param, "default");
+        PyObject* kind = PyObject_GetAttrString(param, "kind");
+        PyObject* kindName = PyObject_GetAttrString(kind, "name");
+        const char* k = PyUnicode_AsUTF8(kindName);
+        found = def != paramEmpty || strcmp(k, "KEYWORD_ONLY") == 0 || strcmp(k, "VAR_KEYWORD") == 0;
+        Py_DecRef(kindName);
+        Py_DecRef(kind);
+        Py_DecRef(def);
+    }
+    Py_DecRef(list);
+    Py_DecRef(values);
+    Py_DecRef(params);
+    return found;
+}
+
+// printSig prints the signature in a Go comment on a single line.
+static void printSig(const char* modName, const char* name, const char* sig) {
+    printf("//\t%s.%s", modName, name);
+    for (; *sig; sig++) {
+        putchar(*sig == '\n' ? ' ' : *sig);
+    }
+    printf("\n");
+}
+
+int main(int argc, char* argv[]) {
+    if (argc < 2) {
+        fprintf(stderr, "Usage: llpyg <module> [<package>]\n");
+        return 2;
+    }
+    const char* modName = argv[1];
+    const char* pkgName = strrchr(modName, '.');
+    pkgName = pkgName ? pkgName + 1 : modName;
+    if (argc > 2) {
+        pkgName = argv[2];
+    }
 
-int main() {
     Py_Initialize();
     PyObject* inspect = PyImport_ImportModule("inspect");
     PyObject* signature = PyObject_GetAttrString(inspect, "signature");
-	PyObject* mod = PyImport_ImportModule("numpy");
-	PyObject* dict = PyModule_GetDict(mod);
-	PyObject* keys = PyDict_Keys(dict);
-    size_t i, n;
-    n = PyList_Size(keys);
-	for (i = 0; i < n; i++) {
-		PyObject* key = PyList_GetItem(keys, i);
-        PyObject* val = PyObject_GetAttr(mod, key);
-		if (PyCallable_Check(val) != 0) {
-			PyObject* doc = PyObject_GetAttrString(val, "__doc__");
-			PyObject* sig = PyObject_CallOneArg(signature, val);
-			printf("-----------------------------------\n");
-			printf("sig: %p\n", sig);
-			printf("%s: %s\n", PyUnicode_AsUTF8(key), PyUnicode_AsUTF8(PyObject_Str(sig)));
-			printf("%s\n", PyUnicode_AsUTF8(key));
-			if (doc != NULL) {
-				printf("%s\n", PyUnicode_AsUTF8(doc));
-			}
-		}
-	}
+    isclass = PyObject_GetAttrString(inspect, "isclass");
+    PyObject* parameter = PyObject_GetAttrString(inspect, "Parameter");
+    paramEmpty = PyObject_GetAttrString(parameter, "empty");
+    PyObject* mod = PyImport_ImportModule(modName);
+    if (mod == NULL) {
+        PyErr_Print();
+        return 1;
+    }
+
+    printf("package %s\n\n", pkgName);
+    printf("import \"github.com/goplus/lib/py\"\n");
+
+    // The public names of the module: __all__, or else those of dir() that
+    // do not start with an underscore.
+    PyObject* all = PyObject_GetAttrString(mod, "__all__");
+    PyObject* names;
+    if (all != NULL) {
+        names = PySequence_List(all);
+        Py_DecRef(all);
+    } else {
+        PyErr_Clear();
+        names = PyObject_Dir(mod);
+    }
+    size_t i, n = PyList_Size(names);
+    char buf[256];
+    for (i = 0; i < n; i++) {
+        const char* name = PyUnicode_AsUTF8(PyList_GetItem(names, i));
+        if (name[0] == '_' || strlen(name) >= sizeof(buf)) {
+            continue;
+        }
+        PyObject* val = PyObject_GetAttrString(mod, name);
+        if (val == NULL || PyCallable_Check(val) == 0 || isClass(val)) {
+            PyErr_Clear();
+            Py_DecRef(val);
+            continue;
+        }
+        PyObject* sig = PyObject_CallOneArg(signature, val);
+        if (sig == NULL) {
+            // Builtins without a text signature.
+            PyErr_Clear();
+            Py_DecRef(val);
+            continue;
+        }
+        if (hasKeywords(sig)) {
+            PyObject* sigStr = PyObject_Str(sig);
+            goName(name, buf);
+            printf("\n// %sKw calls %s.%s with positional arguments, followed by an\n", buf, modName, name);
+            printf("// optional py.Kwargs of keyword arguments:\n//\n");
+            printSig(modName, name, PyUnicode_AsUTF8(sigStr));
+            printf("func %sKw(args ...any) (*py.Object, error) {\n", buf);
+            printf("\treturn py.CallModule(\"%s\", \"%s\", args...)\n}\n", modName, name);
+            Py_DecRef(sigStr);
+        }
+        Py_DecRef(sig);
+        Py_DecRef(val);
+    }
     return 0;
 }
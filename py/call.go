@@ -17,6 +17,7 @@
 package py
 
 import (
+	"fmt"
 	_ "unsafe"
 
 	"github.com/goplus/lib/c"
@@ -123,3 +124,69 @@ func (o *Object) VectorcallMethod(name *Object, args **Object, nargs uintptr, kw
 }
 
 // -----------------------------------------------------------------------------
+
+// Kwargs holds the keyword arguments of a call made by Call, CallAttr or
+// CallModule. The values are converted by From.
+type Kwargs map[string]any
+
+// Call calls the callable fn with args converted by From, and returns the
+// result or an *Error. If the last argument is a Kwargs, it gives keyword
+// arguments:
+//
+//	arr, err := py.Call(zeros, []int{2, 3}, py.Kwargs{"dtype": "int32"})
+//
+// This is the equivalent of the Python expression: fn(*args, **kwargs).
+func Call(fn *Object, args ...any) (*Object, error) {
+	var kwargs Kwargs
+	if n := len(args); n > 0 {
+		if kw, ok := args[n-1].(Kwargs); ok {
+			kwargs, args = kw, args[:n-1]
+		}
+	}
+	// One more item so that &objs[0] is valid without arguments.
+	objs := make([]*Object, len(args)+1)
+	defer func() {
+		for _, o := range objs {
+			o.DecRef()
+		}
+	}()
+	for i, arg := range args {
+		if _, ok := arg.(Kwargs); ok {
+			return nil, fmt.Errorf("py: Kwargs must be the last argument")
+		}
+		if objs[i] = From(arg); objs[i] == nil {
+			return nil, LastError()
+		}
+	}
+	var kwdict *Object
+	if len(kwargs) > 0 {
+		if kwdict = From(kwargs); kwdict == nil {
+			return nil, LastError()
+		}
+		defer kwdict.DecRef()
+	}
+	return Check(fn.VectorcallDict(&objs[0], uintptr(len(args)), kwdict))
+}
+
+// CallAttr calls the attribute name of o, such as a method, as Call does.
+func CallAttr(o *Object, name string, args ...any) (*Object, error) {
+	fn, err := Check(o.GetAttrString(c.AllocaCStr(name)))
+	if err != nil {
+		return nil, err
+	}
+	defer fn.DecRef()
+	return Call(fn, args...)
+}
+
+// CallModule imports module and calls its attribute name as Call does. It
+// reaches the keyword arguments that generated bindings leave out:
+//
+//	py.CallModule("matplotlib.pyplot", "plot", x, y, py.Kwargs{"color": "red"})
+func CallModule(module, name string, args ...any) (*Object, error) {
+	mod, err := Check(ImportModule(c.AllocaCStr(module)))
+	if err != nil {
+		return nil, err
+	}
+	defer mod.DecRef()
+	return CallAttr(mod, name, args...)
+}
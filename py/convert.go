@@ -0,0 +1,754 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package py
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/goplus/lib/c"
+)
+
+// maxConvertDepth bounds how deeply nested containers are converted, so that
+// cyclic data fails instead of overflowing the stack.
+const maxConvertDepth = 100
+
+//go:linkname noneObject _Py_NoneStruct
+var noneObject Object
+
+//go:linkname boolType PyBool_Type
+var boolType Object
+
+//go:linkname longType PyLong_Type
+var longType Object
+
+//go:linkname floatType PyFloat_Type
+var floatType Object
+
+//go:linkname unicodeType PyUnicode_Type
+var unicodeType Object
+
+//go:linkname bytesType PyBytes_Type
+var bytesType Object
+
+//go:linkname byteArrayType PyByteArray_Type
+var byteArrayType Object
+
+//go:linkname listType PyList_Type
+var listType Object
+
+//go:linkname tupleType PyTuple_Type
+var tupleType Object
+
+//go:linkname dictType PyDict_Type
+var dictType Object
+
+//go:linkname bytesFromStringAndSize C.PyBytes_FromStringAndSize
+func bytesFromStringAndSize(s *c.Char, size uintptr) *Object
+
+//go:linkname bytesAsStringAndSize C.PyBytes_AsStringAndSize
+func bytesAsStringAndSize(o *Object, s **c.Char, size *uintptr) c.Int
+
+//go:linkname byteArrayAsString C.PyByteArray_AsString
+func byteArrayAsString(o *Object) *c.Char
+
+//go:linkname byteArraySize C.PyByteArray_Size
+func byteArraySize(o *Object) uintptr
+
+//go:linkname unicodeAsUTF8AndSize C.PyUnicode_AsUTF8AndSize
+func unicodeAsUTF8AndSize(o *Object, size *uintptr) *c.Char
+
+//go:linkname sequenceSize C.PySequence_Size
+func sequenceSize(o *Object) int
+
+//go:linkname sequenceGetItem C.PySequence_GetItem
+func sequenceGetItem(o *Object, i int) *Object
+
+//go:linkname mappingItems C.PyMapping_Items
+func mappingItems(o *Object) *Object
+
+func isInstance(o, typ *Object) bool {
+	return o.IsInstance(typ) == 1
+}
+
+// objectString returns the content of the str object o and releases o. It
+// returns "" if o is nil, which it accepts so that it can wrap calls that may
+// fail, such as o.Str(). Errors are cleared, so that it can be used while
+// reporting errors.
+func objectString(o *Object) string {
+	if o == nil {
+		ErrClear()
+		return ""
+	}
+	defer o.DecRef()
+	s, ok := utf8String(o)
+	if !ok {
+		ErrClear()
+	}
+	return s
+}
+
+// typeName returns the name of the type of o.
+func typeName(o *Object) string {
+	typ := o.Type()
+	defer typ.DecRef()
+	return objectString(typ.TypeName())
+}
+
+func goString(o *Object) (string, error) {
+	s, ok := utf8String(o)
+	if !ok {
+		return "", LastError()
+	}
+	return s, nil
+}
+
+// utf8String returns the content of the str object o, or false with an
+// exception set.
+func utf8String(o *Object) (string, bool) {
+	var n uintptr
+	p := unicodeAsUTF8AndSize(o, &n)
+	if p == nil {
+		return "", false
+	}
+	return string(unsafe.Slice((*byte)(unsafe.Pointer(p)), n)), true
+}
+
+func goBytes(o *Object) ([]byte, error) {
+	var p *c.Char
+	var n uintptr
+	if isInstance(o, &byteArrayType) {
+		p, n = byteArrayAsString(o), byteArraySize(o)
+	} else if bytesAsStringAndSize(o, &p, &n) != 0 {
+		return nil, LastError()
+	}
+	return append([]byte{}, unsafe.Slice((*byte)(unsafe.Pointer(p)), n)...), nil
+}
+
+// -----------------------------------------------------------------------------
+
+var (
+	objectPtrType = reflect.TypeOf((*Object)(nil))
+	timeType      = reflect.TypeOf(time.Time{})
+)
+
+// From converts the Go value v to a new Python object, and returns nil with
+// an exception set if it cannot; use Check or LastError to get it as an
+// error. The caller owns the returned reference.
+//
+// nil converts to None, bools, integers, floats and strings to the Python
+// types of the same name, []byte to bytes, slices and arrays to lists, maps
+// to dicts, structs to dicts of their exported fields, time.Time to a
+// timezone-aware datetime.datetime with microsecond precision, and pointers
+// and interfaces to the value they refer to. A *Object is returned as is,
+// with a new reference. Struct fields are named as in Go or by a
+// `py:"name"` tag; the tag "-" leaves a field out.
+func From(v any) *Object {
+	return fromValue(reflect.ValueOf(v), 0)
+}
+
+func fromValue(v reflect.Value, depth int) *Object {
+	if depth > maxConvertDepth {
+		ErrSetString(ExcValueError, c.Str("value too deeply nested"))
+		return nil
+	}
+	if !v.IsValid() {
+		return none()
+	}
+	switch v.Type() {
+	case objectPtrType:
+		o := v.Interface().(*Object)
+		if o == nil {
+			return none()
+		}
+		o.IncRef()
+		return o
+	case timeType:
+		return fromTime(v.Interface().(time.Time))
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return BoolFromLong(1)
+		}
+		return BoolFromLong(0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return LongLong(c.LongLong(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return UlongLong(c.UlongLong(v.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return Float(v.Float())
+	case reflect.String:
+		return FromGoString(v.String())
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return none()
+		}
+		return fromValue(v.Elem(), depth+1)
+	case reflect.Slice:
+		if v.IsNil() {
+			return none()
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := v.Bytes()
+			return bytesFromStringAndSize((*c.Char)(unsafe.Pointer(unsafe.SliceData(b))), uintptr(len(b)))
+		}
+		return fromList(v, depth)
+	case reflect.Array:
+		return fromList(v, depth)
+	case reflect.Map:
+		if v.IsNil() {
+			return none()
+		}
+		return fromMap(v, depth)
+	case reflect.Struct:
+		return fromStruct(v, depth)
+	}
+	ErrSetString(ExcTypeError, c.AllocaCStr(fmt.Sprintf("cannot convert Go value of type %v", v.Type())))
+	return nil
+}
+
+func none() *Object {
+	noneObject.IncRef()
+	return &noneObject
+}
+
+func fromList(v reflect.Value, depth int) *Object {
+	n := v.Len()
+	list := NewList(n)
+	if list == nil {
+		return nil
+	}
+	for i := 0; i < n; i++ {
+		item := fromValue(v.Index(i), depth+1)
+		if item == nil {
+			list.DecRef()
+			return nil
+		}
+		// ListSetItem steals the reference to item.
+		list.ListSetItem(i, item)
+	}
+	return list
+}
+
+func fromMap(v reflect.Value, depth int) *Object {
+	dict := NewDict()
+	if dict == nil {
+		return nil
+	}
+	keys := v.MapKeys()
+	if v.Type().Key().Kind() == reflect.String {
+		// Give the dict a stable order.
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	}
+	for _, k := range keys {
+		if !setItem(dict, fromValue(k, depth+1), v.MapIndex(k), depth) {
+			dict.DecRef()
+			return nil
+		}
+	}
+	return dict
+}
+
+func fromStruct(v reflect.Value, depth int) *Object {
+	dict := NewDict()
+	if dict == nil {
+		return nil
+	}
+	for _, f := range structFields(v.Type()) {
+		if !setItem(dict, FromGoString(f.name), v.FieldByIndex(f.index), depth) {
+			dict.DecRef()
+			return nil
+		}
+	}
+	return dict
+}
+
+// setItem sets dict[key] to v converted, and releases key. It fails if key is
+// nil, or v or the item cannot be set.
+func setItem(dict, key *Object, v reflect.Value, depth int) bool {
+	if key == nil {
+		return false
+	}
+	defer key.DecRef()
+	val := fromValue(v, depth+1)
+	if val == nil {
+		return false
+	}
+	defer val.DecRef()
+	return dict.DictSetItem(key, val) == 0
+}
+
+// fromTime converts t to a datetime.datetime with a fixed offset timezone
+// named as the zone of t.
+func fromTime(t time.Time) *Object {
+	mod := ImportModule(c.Str("datetime"))
+	if mod == nil {
+		return nil
+	}
+	defer mod.DecRef()
+	name, offset := t.Zone()
+	timedelta := mod.GetAttrString(c.Str("timedelta"))
+	defer timedelta.DecRef()
+	timezone := mod.GetAttrString(c.Str("timezone"))
+	defer timezone.DecRef()
+	datetime := mod.GetAttrString(c.Str("datetime"))
+	defer datetime.DecRef()
+	if timedelta == nil || timezone == nil || datetime == nil {
+		return nil
+	}
+	delta := timedelta.CallFunction(c.Str("ii"), c.Int(0), c.Int(offset))
+	if delta == nil {
+		return nil
+	}
+	defer delta.DecRef()
+	tz := timezone.CallFunction(c.Str("Os"), delta, c.AllocaCStr(name))
+	if tz == nil {
+		return nil
+	}
+	defer tz.DecRef()
+	return datetime.CallFunction(c.Str("iiiiiiiO"),
+		c.Int(t.Year()), c.Int(t.Month()), c.Int(t.Day()),
+		c.Int(t.Hour()), c.Int(t.Minute()), c.Int(t.Second()),
+		c.Int(t.Nanosecond()/1000), tz)
+}
+
+// -----------------------------------------------------------------------------
+
+type structField struct {
+	name  string
+	index []int
+}
+
+var structFieldCache sync.Map // reflect.Type -> []structField
+
+// structFields returns the exported fields of the struct type t, named by
+// their py tag if they have one.
+func structFields(t reflect.Type) []structField {
+	if fields, ok := structFieldCache.Load(t); ok {
+		return fields.([]structField)
+	}
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("py"); ok {
+			tag, _, _ = strings.Cut(tag, ",")
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, structField{name: name, index: f.Index})
+	}
+	structFieldCache.Store(t, fields)
+	return fields
+}
+
+// -----------------------------------------------------------------------------
+
+// To converts the Python object obj and stores it in the value ptr points
+// to. It is the inverse of From: sequences convert to slices and arrays,
+// mappings to maps, and dicts or objects with attributes, such as
+// dataclass instances, to structs. Objects with year, month and day
+// attributes, such as datetime.datetime, convert to time.Time; naive
+// datetimes are taken as local time. Into a *Object, obj is stored with a
+// new reference.
+//
+// Into an interface type, objects convert to nil, bool, int64, float64,
+// string, []byte, []any (for lists and tuples), map[string]any or
+// map[any]any (for dicts), time.Time (for datetime.date and subclasses),
+// or else a *Object holding a new reference.
+//
+// If a Python operation fails, the error is an *Error carrying the Python
+// exception, which is cleared.
+func To(obj *Object, ptr any) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("py: To needs a non-nil pointer, got %T", ptr)
+	}
+	if obj == nil {
+		if err := LastError(); err != nil {
+			return err
+		}
+		return fmt.Errorf("py: To called with a nil object")
+	}
+	return toValue(obj, v.Elem(), 0)
+}
+
+func typeError(o *Object, t reflect.Type) error {
+	return fmt.Errorf("py: cannot convert %s to %v", typeName(o), t)
+}
+
+func toValue(o *Object, v reflect.Value, depth int) error {
+	if depth > maxConvertDepth {
+		return fmt.Errorf("py: value too deeply nested")
+	}
+	switch v.Type() {
+	case objectPtrType:
+		o.IncRef()
+		v.Set(reflect.ValueOf(o))
+		return nil
+	case timeType:
+		t, err := toTime(o)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return typeError(o, v.Type())
+		}
+		x, err := toAny(o, depth)
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			v.Set(reflect.Zero(v.Type()))
+		} else {
+			v.Set(reflect.ValueOf(x))
+		}
+	case reflect.Pointer:
+		if o == &noneObject {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return toValue(o, v.Elem(), depth+1)
+	case reflect.Bool:
+		t := o.IsTrue()
+		if t < 0 {
+			return LastError()
+		}
+		v.SetBool(t != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := int64(o.LongLong())
+		if n == -1 && ErrOccurred() != nil {
+			return LastError()
+		}
+		if v.OverflowInt(n) {
+			return fmt.Errorf("py: %d overflows %v", n, v.Type())
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := uint64(o.UlongLong())
+		if n == ^uint64(0) && ErrOccurred() != nil {
+			return LastError()
+		}
+		if v.OverflowUint(n) {
+			return fmt.Errorf("py: %d overflows %v", n, v.Type())
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f := o.Float64()
+		if f == -1 && ErrOccurred() != nil {
+			return LastError()
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		if !isInstance(o, &unicodeType) {
+			return typeError(o, v.Type())
+		}
+		s, err := goString(o)
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+	case reflect.Slice:
+		if o == &noneObject {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 && (isInstance(o, &bytesType) || isInstance(o, &byteArrayType)) {
+			b, err := goBytes(o)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(b)
+			return nil
+		}
+		if isInstance(o, &unicodeType) {
+			return typeError(o, v.Type())
+		}
+		n := sequenceSize(o)
+		if n < 0 {
+			ErrClear()
+			return typeError(o, v.Type())
+		}
+		s := reflect.MakeSlice(v.Type(), n, n)
+		if err := toList(o, s, depth); err != nil {
+			return err
+		}
+		v.Set(s)
+	case reflect.Array:
+		n := sequenceSize(o)
+		if n < 0 {
+			ErrClear()
+			return typeError(o, v.Type())
+		}
+		if n != v.Len() {
+			return fmt.Errorf("py: cannot convert sequence of length %d to %v", n, v.Type())
+		}
+		return toList(o, v, depth)
+	case reflect.Map:
+		if o == &noneObject {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		return toMap(o, v, depth)
+	case reflect.Struct:
+		return toStruct(o, v, depth)
+	default:
+		return typeError(o, v.Type())
+	}
+	return nil
+}
+
+func toList(o *Object, v reflect.Value, depth int) error {
+	for i := 0; i < v.Len(); i++ {
+		item := sequenceGetItem(o, i)
+		if item == nil {
+			return LastError()
+		}
+		err := toValue(item, v.Index(i), depth+1)
+		item.DecRef()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toMap(o *Object, v reflect.Value, depth int) error {
+	items := mappingItems(o)
+	if items == nil {
+		ErrClear()
+		return typeError(o, v.Type())
+	}
+	defer items.DecRef()
+	t := v.Type()
+	m := reflect.MakeMapWithSize(t, items.ListLen())
+	for i, n := 0, items.ListLen(); i < n; i++ {
+		item := items.ListItem(i)
+		key, val := reflect.New(t.Key()).Elem(), reflect.New(t.Elem()).Elem()
+		if err := toValue(item.TupleItem(0), key, depth+1); err != nil {
+			return err
+		}
+		if err := toValue(item.TupleItem(1), val, depth+1); err != nil {
+			return err
+		}
+		if key.Kind() == reflect.Interface && !key.IsNil() && !key.Elem().Type().Comparable() {
+			// Such as the []any of a tuple key.
+			return fmt.Errorf("py: cannot use %s key in %v", typeName(item.TupleItem(0)), t)
+		}
+		m.SetMapIndex(key, val)
+	}
+	v.Set(m)
+	return nil
+}
+
+// toStruct sets the fields of v from the items of the dict o, or else from
+// the attributes of o. Fields without a matching item or attribute are left
+// unchanged.
+func toStruct(o *Object, v reflect.Value, depth int) error {
+	dict := isInstance(o, &dictType)
+	for _, f := range structFields(v.Type()) {
+		name := c.AllocaCStr(f.name)
+		var item *Object
+		if dict {
+			item = o.DictGetItemString(name)
+			if item == nil {
+				continue
+			}
+			item.IncRef()
+		} else {
+			if o.HasAttrString(name) == 0 {
+				continue
+			}
+			item = o.GetAttrString(name)
+			if item == nil {
+				return LastError()
+			}
+		}
+		err := toValue(item, v.FieldByIndex(f.index), depth+1)
+		item.DecRef()
+		if err != nil {
+			return fmt.Errorf("py: field %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+func intAttr(o *Object, name string) (int, error) {
+	attr := o.GetAttrString(c.AllocaCStr(name))
+	if attr == nil {
+		return 0, LastError()
+	}
+	defer attr.DecRef()
+	n := attr.Long()
+	if n == -1 && ErrOccurred() != nil {
+		return 0, LastError()
+	}
+	return int(n), nil
+}
+
+// toTime converts a datetime.date, datetime.datetime or similar object.
+func toTime(o *Object) (time.Time, error) {
+	names := [...]string{"year", "month", "day", "hour", "minute", "second", "microsecond"}
+	var f [len(names)]int
+	for i, name := range names {
+		if i >= 3 && o.HasAttrString(c.AllocaCStr(name)) == 0 {
+			// A date has no time of day.
+			break
+		}
+		n, err := intAttr(o, name)
+		if err != nil {
+			if i == 0 {
+				return time.Time{}, typeError(o, timeType)
+			}
+			return time.Time{}, err
+		}
+		f[i] = n
+	}
+	loc, err := timeLocation(o)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(f[0], time.Month(f[1]), f[2], f[3], f[4], f[5], f[6]*1000, loc), nil
+}
+
+// timeLocation returns the location of a datetime, from its utcoffset and
+// tzname methods, or time.Local for naive datetimes and dates.
+func timeLocation(o *Object) (*time.Location, error) {
+	if o.HasAttrString(c.Str("utcoffset")) == 0 {
+		return time.Local, nil
+	}
+	delta := o.CallMethod(c.Str("utcoffset"), nil)
+	if delta == nil {
+		return nil, LastError()
+	}
+	defer delta.DecRef()
+	if delta == &noneObject {
+		return time.Local, nil
+	}
+	days, err := intAttr(delta, "days")
+	if err != nil {
+		return nil, err
+	}
+	seconds, err := intAttr(delta, "seconds")
+	if err != nil {
+		return nil, err
+	}
+	offset := days*86400 + seconds
+	name := ""
+	if tzname := o.CallMethod(c.Str("tzname"), nil); tzname == nil {
+		ErrClear()
+	} else if tzname != &noneObject {
+		name = objectString(tzname)
+	} else {
+		tzname.DecRef()
+	}
+	if offset == 0 && (name == "" || name == "UTC") {
+		return time.UTC, nil
+	}
+	return time.FixedZone(name, offset), nil
+}
+
+// -----------------------------------------------------------------------------
+
+func toAny(o *Object, depth int) (any, error) {
+	switch {
+	case o == &noneObject:
+		return nil, nil
+	case isInstance(o, &boolType):
+		return o.IsTrue() == 1, nil
+	case isInstance(o, &longType):
+		var n int64
+		err := toValue(o, reflect.ValueOf(&n).Elem(), depth)
+		return n, err
+	case isInstance(o, &floatType):
+		return o.Float64(), nil
+	case isInstance(o, &unicodeType):
+		return goString(o)
+	case isInstance(o, &bytesType), isInstance(o, &byteArrayType):
+		return goBytes(o)
+	case isInstance(o, &listType), isInstance(o, &tupleType):
+		var s []any
+		err := toValue(o, reflect.ValueOf(&s).Elem(), depth)
+		return s, err
+	case isInstance(o, &dictType):
+		return toAnyDict(o, depth)
+	case isDate(o):
+		return toTime(o)
+	}
+	o.IncRef()
+	return o, nil
+}
+
+// toAnyDict converts a dict to a map[string]any if all its keys are strings,
+// or else to a map[any]any.
+func toAnyDict(o *Object, depth int) (any, error) {
+	keys := o.DictKeys()
+	if keys == nil {
+		return nil, LastError()
+	}
+	strKeys := true
+	for i, n := 0, keys.ListLen(); i < n && strKeys; i++ {
+		strKeys = isInstance(keys.ListItem(i), &unicodeType)
+	}
+	keys.DecRef()
+	if strKeys {
+		var m map[string]any
+		err := toValue(o, reflect.ValueOf(&m).Elem(), depth)
+		return m, err
+	}
+	var m map[any]any
+	err := toValue(o, reflect.ValueOf(&m).Elem(), depth)
+	return m, err
+}
+
+// isDate reports whether o is a datetime.date, which includes datetimes.
+func isDate(o *Object) bool {
+	mod := ImportModule(c.Str("datetime"))
+	if mod == nil {
+		ErrClear()
+		return false
+	}
+	defer mod.DecRef()
+	date := mod.GetAttrString(c.Str("date"))
+	if date == nil {
+		ErrClear()
+		return false
+	}
+	defer date.DecRef()
+	return isInstance(o, date)
+}
@@ -18,6 +18,8 @@ package py
 
 import (
 	_ "unsafe"
+
+	"github.com/goplus/lib/c"
 )
 
 // https://docs.python.org/3/c-api/dict.html
@@ -47,7 +49,7 @@ func (d *Object) DictItems() *Object { return nil }
 // -1 on failure.
 //
 // llgo:link (*Object).DictSetItem C.PyDict_SetItem
-func (d *Object) DictSetItem(key *Object, val *Object) *Object { return nil }
+func (d *Object) DictSetItem(key *Object, val *Object) c.Int { return -1 }
 
 // Return the object from dictionary d which has a key key. Return nil if the
 // key key is not present, but without setting an exception.
@@ -55,6 +57,12 @@ func (d *Object) DictSetItem(key *Object, val *Object) *Object { return nil }
 // llgo:link (*Object).DictGetItem C.PyDict_GetItem
 func (d *Object) DictGetItem(key *Object) *Object { return nil }
 
+// This is the same as DictGetItem, but key is specified as a C string rather
+// than an Object. Return nil if the key is not present.
+//
+// llgo:link (*Object).DictGetItemString C.PyDict_GetItemString
+func (d *Object) DictGetItemString(key *c.Char) *Object { return nil }
+
 // Return the number of items in the dictionary.
 //
 // llgo:link (*Object).DictSize C.PyDict_Size
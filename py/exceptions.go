@@ -17,7 +17,10 @@
 package py
 
 import (
+	"strings"
 	_ "unsafe"
+
+	"github.com/goplus/lib/c"
 )
 
 // https://docs.python.org/3/c-api/exceptions.html
@@ -28,5 +31,279 @@ import (
 //go:linkname ErrClear C.PyErr_Clear
 func ErrClear()
 
+// Print a standard traceback to sys.stderr and clear the error indicator.
+//
 //go:linkname ErrPrint C.PyErr_Print
 func ErrPrint()
+
+// Test whether the error indicator is set. If set, return the exception type
+// (the first argument to the last call to one of the ErrSet* functions or to
+// ErrRestore). If not set, return nil. You do not own a reference to the
+// return value, so you do not need to DecRef it.
+//
+//go:linkname ErrOccurred C.PyErr_Occurred
+func ErrOccurred() *Object
+
+// Retrieve the error indicator into three variables whose addresses are
+// passed. If the error indicator is not set, set all three variables to nil.
+// If it is set, it will be cleared and you own a reference to each object
+// retrieved. The value and traceback object may be nil even when the type
+// object is not.
+//
+//go:linkname ErrFetch C.PyErr_Fetch
+func ErrFetch(ptype, pvalue, ptraceback **Object)
+
+// Under certain circumstances, the values returned by ErrFetch can be
+// "unnormalized", meaning that *pvalue is not an instance of *ptype. This
+// function normalizes them in place.
+//
+//go:linkname ErrNormalizeException C.PyErr_NormalizeException
+func ErrNormalizeException(ptype, pvalue, ptraceback **Object)
+
+// Set the error indicator from the three objects, which are usually the
+// results of ErrFetch. If the error indicator is already set, it is cleared
+// first. This call takes away a reference to each object.
+//
+//go:linkname ErrRestore C.PyErr_Restore
+func ErrRestore(typ, value, traceback *Object)
+
+// Return the exception currently being raised, clearing the error indicator
+// at the same time, or nil if no exception is set. You own the returned
+// reference. New in Python 3.12, where it replaces ErrFetch.
+//
+//go:linkname ErrGetRaisedException C.PyErr_GetRaisedException
+func ErrGetRaisedException() *Object
+
+// Set exc as the exception currently being raised, clearing the existing
+// exception if one is set. This call steals a reference to exc, which must be
+// a valid exception. New in Python 3.12.
+//
+//go:linkname ErrSetRaisedException C.PyErr_SetRaisedException
+func ErrSetRaisedException(exc *Object)
+
+// Equivalent to ErrGivenExceptionMatches(ErrOccurred(), exc). This should
+// only be called when an exception is actually set. Return 1 if the pending
+// exception matches exc, which may be a class or a tuple of classes, and 0
+// otherwise.
+//
+//go:linkname ErrExceptionMatches C.PyErr_ExceptionMatches
+func ErrExceptionMatches(exc *Object) c.Int
+
+// Return 1 if the given exception matches the exception type in exc. If exc
+// is a class object, this also returns 1 when given is an instance of a
+// subclass. If exc is a tuple, all exception types in the tuple (and
+// recursively in subtuples) are searched for a match.
+//
+//go:linkname ErrGivenExceptionMatches C.PyErr_GivenExceptionMatches
+func ErrGivenExceptionMatches(given, exc *Object) c.Int
+
+// This is the most common way to set the error indicator. The first argument
+// specifies the exception type; it is normally one of the standard exceptions,
+// e.g. ExcTypeError. The second argument is an error message; it is decoded
+// from 'utf-8'.
+//
+//go:linkname ErrSetString C.PyErr_SetString
+func ErrSetString(typ *Object, message *c.Char)
+
+// -----------------------------------------------------------------------------
+
+// https://docs.python.org/3/c-api/exceptions.html#standard-exceptions
+
+//go:linkname ExcBaseException PyExc_BaseException
+var ExcBaseException *Object
+
+//go:linkname ExcException PyExc_Exception
+var ExcException *Object
+
+//go:linkname ExcArithmeticError PyExc_ArithmeticError
+var ExcArithmeticError *Object
+
+//go:linkname ExcAssertionError PyExc_AssertionError
+var ExcAssertionError *Object
+
+//go:linkname ExcAttributeError PyExc_AttributeError
+var ExcAttributeError *Object
+
+//go:linkname ExcEOFError PyExc_EOFError
+var ExcEOFError *Object
+
+//go:linkname ExcFileNotFoundError PyExc_FileNotFoundError
+var ExcFileNotFoundError *Object
+
+//go:linkname ExcImportError PyExc_ImportError
+var ExcImportError *Object
+
+//go:linkname ExcModuleNotFoundError PyExc_ModuleNotFoundError
+var ExcModuleNotFoundError *Object
+
+//go:linkname ExcIndexError PyExc_IndexError
+var ExcIndexError *Object
+
+//go:linkname ExcKeyError PyExc_KeyError
+var ExcKeyError *Object
+
+//go:linkname ExcKeyboardInterrupt PyExc_KeyboardInterrupt
+var ExcKeyboardInterrupt *Object
+
+//go:linkname ExcLookupError PyExc_LookupError
+var ExcLookupError *Object
+
+//go:linkname ExcMemoryError PyExc_MemoryError
+var ExcMemoryError *Object
+
+//go:linkname ExcNameError PyExc_NameError
+var ExcNameError *Object
+
+//go:linkname ExcNotImplementedError PyExc_NotImplementedError
+var ExcNotImplementedError *Object
+
+//go:linkname ExcOSError PyExc_OSError
+var ExcOSError *Object
+
+//go:linkname ExcOverflowError PyExc_OverflowError
+var ExcOverflowError *Object
+
+//go:linkname ExcRecursionError PyExc_RecursionError
+var ExcRecursionError *Object
+
+//go:linkname ExcRuntimeError PyExc_RuntimeError
+var ExcRuntimeError *Object
+
+//go:linkname ExcStopIteration PyExc_StopIteration
+var ExcStopIteration *Object
+
+//go:linkname ExcSyntaxError PyExc_SyntaxError
+var ExcSyntaxError *Object
+
+//go:linkname ExcSystemError PyExc_SystemError
+var ExcSystemError *Object
+
+//go:linkname ExcTimeoutError PyExc_TimeoutError
+var ExcTimeoutError *Object
+
+//go:linkname ExcTypeError PyExc_TypeError
+var ExcTypeError *Object
+
+//go:linkname ExcUnicodeError PyExc_UnicodeError
+var ExcUnicodeError *Object
+
+//go:linkname ExcValueError PyExc_ValueError
+var ExcValueError *Object
+
+//go:linkname ExcZeroDivisionError PyExc_ZeroDivisionError
+var ExcZeroDivisionError *Object
+
+// -----------------------------------------------------------------------------
+
+// Error is a Python exception as a Go error.
+type Error struct {
+	// Type is the name of the exception class, qualified by its module
+	// unless it is a built-in, e.g. "ValueError" or
+	// "numpy.linalg.LinAlgError".
+	Type string
+	// Message is str() of the exception.
+	Message string
+	// Traceback is the exception formatted by traceback.format_exception,
+	// as Python prints it, or "" if it could not be formatted.
+	Traceback string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return e.Type
+	}
+	return e.Type + ": " + e.Message
+}
+
+// LastError returns the pending exception as an *Error and clears it, or
+// returns nil if no exception is set.
+//
+// The C API reports failures by returning nil or -1 with an exception set,
+// which LastError turns into a Go error:
+//
+//	arr := numpy.Zeros(shape, nil, nil)
+//	if arr == nil {
+//		return py.LastError()
+//	}
+func LastError() error {
+	var typ, val, tb *Object
+	ErrFetch(&typ, &val, &tb)
+	if typ == nil {
+		return nil
+	}
+	ErrNormalizeException(&typ, &val, &tb)
+	defer typ.DecRef()
+	defer val.DecRef()
+	defer tb.DecRef()
+	e := &Error{Type: qualifiedName(typ)}
+	if val != nil {
+		e.Message = objectString(val.Str())
+	}
+	e.Traceback = formatException(typ, val, tb)
+	return e
+}
+
+// Check returns o if it is not nil, and LastError otherwise. It turns the
+// result of a call that returns nil on failure into a Go result:
+//
+//	arr, err := py.Check(numpy.Zeros(shape, nil, nil))
+func Check(o *Object) (*Object, error) {
+	if o == nil {
+		if err := LastError(); err != nil {
+			return nil, err
+		}
+		return nil, &Error{Type: "SystemError", Message: "nil result without an exception set"}
+	}
+	return o, nil
+}
+
+// qualifiedName returns the module and qualified name of the class typ.
+func qualifiedName(typ *Object) string {
+	name := objectString(typ.GetAttrString(c.Str("__qualname__")))
+	if name == "" {
+		name = objectString(typ.TypeName())
+	}
+	if mod := objectString(typ.GetAttrString(c.Str("__module__"))); mod != "" && mod != "builtins" {
+		name = mod + "." + name
+	}
+	return name
+}
+
+// formatException formats an exception with traceback.format_exception. Any
+// error in doing so is cleared.
+func formatException(typ, val, tb *Object) string {
+	mod := ImportModule(c.Str("traceback"))
+	if mod == nil {
+		ErrClear()
+		return ""
+	}
+	defer mod.DecRef()
+	format := mod.GetAttrString(c.Str("format_exception"))
+	if format == nil {
+		ErrClear()
+		return ""
+	}
+	defer format.DecRef()
+	if val == nil {
+		val = &noneObject
+	}
+	if tb == nil {
+		tb = &noneObject
+	}
+	lines := format.CallFunctionObjArgs(typ, val, tb, nil)
+	if lines == nil {
+		ErrClear()
+		return ""
+	}
+	defer lines.DecRef()
+	var b strings.Builder
+	for i, n := 0, lines.ListLen(); i < n; i++ {
+		s, ok := utf8String(lines.ListItem(i))
+		if !ok {
+			ErrClear()
+			return ""
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
@@ -0,0 +1,35 @@
+package json
+
+import "github.com/goplus/lib/py"
+
+// DumpKw calls json.dump with positional arguments, followed by an
+// optional py.Kwargs of keyword arguments:
+//
+//	json.dump(obj, fp, *, skipkeys=False, ensure_ascii=True, check_circular=True, allow_nan=True, cls=None, indent=None, separators=None, default=None, sort_keys=False, **kw)
+func DumpKw(args ...any) (*py.Object, error) {
+	return py.CallModule("json", "dump", args...)
+}
+
+// DumpsKw calls json.dumps with positional arguments, followed by an
+// optional py.Kwargs of keyword arguments:
+//
+//	json.dumps(obj, *, skipkeys=False, ensure_ascii=True, check_circular=True, allow_nan=True, cls=None, indent=None, separators=None, default=None, sort_keys=False, **kw)
+func DumpsKw(args ...any) (*py.Object, error) {
+	return py.CallModule("json", "dumps", args...)
+}
+
+// LoadKw calls json.load with positional arguments, followed by an
+// optional py.Kwargs of keyword arguments:
+//
+//	json.load(fp, *, cls=None, object_hook=None, parse_float=None, parse_int=None, parse_constant=None, object_pairs_hook=None, **kw)
+func LoadKw(args ...any) (*py.Object, error) {
+	return py.CallModule("json", "load", args...)
+}
+
+// LoadsKw calls json.loads with positional arguments, followed by an
+// optional py.Kwargs of keyword arguments:
+//
+//	json.loads(s, *, cls=None, object_hook=None, parse_float=None, parse_int=None, parse_constant=None, object_pairs_hook=None, **kw)
+func LoadsKw(args ...any) (*py.Object, error) {
+	return py.CallModule("json", "loads", args...)
+}
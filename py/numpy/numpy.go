@@ -68,3 +68,46 @@ func Array(object, dtype *py.Object) *py.Object
 //
 //go:linkname AsArray py.asarray
 func AsArray(a, dtype, order *py.Object) *py.Object
+
+// -----------------------------------------------------------------------------
+
+// The functions above are builtins without a signature that llpyg can
+// inspect, so their keyword-argument variants are written by hand. They take
+// positional arguments, followed by an optional py.Kwargs:
+//
+//	arr, err := numpy.ZerosKw([]int{2, 3}, py.Kwargs{"dtype": "int32"})
+
+// ArangeKw calls numpy.arange:
+//
+//	numpy.arange([start, ]stop, [step, ]dtype=None, *, like=None)
+func ArangeKw(args ...any) (*py.Object, error) {
+	return py.CallModule("numpy", "arange", args...)
+}
+
+// EmptyKw calls numpy.empty:
+//
+//	numpy.empty(shape, dtype=float, order='C', *, like=None)
+func EmptyKw(args ...any) (*py.Object, error) {
+	return py.CallModule("numpy", "empty", args...)
+}
+
+// ZerosKw calls numpy.zeros:
+//
+//	numpy.zeros(shape, dtype=float, order='C', *, like=None)
+func ZerosKw(args ...any) (*py.Object, error) {
+	return py.CallModule("numpy", "zeros", args...)
+}
+
+// ArrayKw calls numpy.array:
+//
+//	numpy.array(object, dtype=None, *, copy=True, order='K', subok=False, ndmin=0, like=None)
+func ArrayKw(args ...any) (*py.Object, error) {
+	return py.CallModule("numpy", "array", args...)
+}
+
+// AsArrayKw calls numpy.asarray:
+//
+//	numpy.asarray(a, dtype=None, order=None, *, like=None)
+func AsArrayKw(args ...any) (*py.Object, error) {
+	return py.CallModule("numpy", "asarray", args...)
+}
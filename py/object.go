@@ -29,6 +29,9 @@ type Object struct {
 	Unused [0]byte
 }
 
+// llgo:link (*Object).IncRef C.Py_IncRef
+func (o *Object) IncRef() {}
+
 // llgo:link (*Object).DecRef C.Py_DecRef
 func (o *Object) DecRef() {}
 
@@ -65,4 +68,17 @@ func (o *Object) GetAttr(attrName *Object) *Object { return nil }
 // llgo:link (*Object).GetAttrString C.PyObject_GetAttrString
 func (o *Object) GetAttrString(attrName *c.Char) *Object { return nil }
 
+// Returns 1 if o has the attribute attrName, and 0 otherwise. This is equivalent
+// to the Python expression hasattr(o, attrName). This function always succeeds.
+//
+// llgo:link (*Object).HasAttrString C.PyObject_HasAttrString
+func (o *Object) HasAttrString(attrName *c.Char) c.Int { return 0 }
+
+// Return 1 if o is an instance of the class cls or a subclass of cls, or 0 if
+// not. On error, returns -1 and sets an exception. This is the equivalent of
+// the Python expression isinstance(o, cls).
+//
+// llgo:link (*Object).IsInstance C.PyObject_IsInstance
+func (o *Object) IsInstance(cls *Object) c.Int { return -1 }
+
 // -----------------------------------------------------------------------------
@@ -0,0 +1,75 @@
+package statistics
+
+import "github.com/goplus/lib/py"
+
+// FmeanKw calls statistics.fmean with positional arguments, followed by an
+// optional py.Kwargs of keyword arguments:
+//
+//	statistics.fmean(data, weights=None)
+func FmeanKw(args ...any) (*py.Object, error) {
+	return py.CallModule("statistics", "fmean", args...)
+}
+
+// HarmonicMeanKw calls statistics.harmonic_mean with positional arguments, followed by an
+// optional py.Kwargs of keyword arguments:
+//
+//	statistics.harmonic_mean(data, weights=None)
+func HarmonicMeanKw(args ...any) (*py.Object, error) {
+	return py.CallModule("statistics", "harmonic_mean", args...)
+}
+
+// LinearRegressionKw calls statistics.linear_regression with positional arguments, followed by an
+// optional py.Kwargs of keyword arguments:
+//
+//	statistics.linear_regression(x, y, /, *, proportional=False)
+func LinearRegressionKw(args ...any) (*py.Object, error) {
+	return py.CallModule("statistics", "linear_regression", args...)
+}
+
+// MedianGroupedKw calls statistics.median_grouped with positional arguments, followed by an
+// optional py.Kwargs of keyword arguments:
+//
+//	statistics.median_grouped(data, interval=1.0)
+func MedianGroupedKw(args ...any) (*py.Object, error) {
+	return py.CallModule("statistics", "median_grouped", args...)
+}
+
+// PstdevKw calls statistics.pstdev with positional arguments, followed by an
+// optional py.Kwargs of keyword arguments:
+//
+//	statistics.pstdev(data, mu=None)
+func PstdevKw(args ...any) (*py.Object, error) {
+	return py.CallModule("statistics", "pstdev", args...)
+}
+
+// PvarianceKw calls statistics.pvariance with positional arguments, followed by an
+// optional py.Kwargs of keyword arguments:
+//
+//	statistics.pvariance(data, mu=None)
+func PvarianceKw(args ...any) (*py.Object, error) {
+	return py.CallModule("statistics", "pvariance", args...)
+}
+
+// QuantilesKw calls statistics.quantiles with positional arguments, followed by an
+// optional py.Kwargs of keyword arguments:
+//
+//	statistics.quantiles(data, *, n=4, method='exclusive')
+func QuantilesKw(args ...any) (*py.Object, error) {
+	return py.CallModule("statistics", "quantiles", args...)
+}
+
+// StdevKw calls statistics.stdev with positional arguments, followed by an
+// optional py.Kwargs of keyword arguments:
+//
+//	statistics.stdev(data, xbar=None)
+func StdevKw(args ...any) (*py.Object, error) {
+	return py.CallModule("statistics", "stdev", args...)
+}
+
+// VarianceKw calls statistics.variance with positional arguments, followed by an
+// optional py.Kwargs of keyword arguments:
+//
+//	statistics.variance(data, xbar=None)
+func VarianceKw(args ...any) (*py.Object, error) {
+	return py.CallModule("statistics", "variance", args...)
+}